@@ -14,6 +14,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -49,7 +50,17 @@ type XSDConverterConfig struct {
 	ValidationOutputPath string
 	// 多语言支持和类型映射
 	TargetLanguage    string
+	MixedContent      string
+	AnonymousNaming   string
+	AnonymousNameMap  string
+	NameSuffixes      string
+	NamespacePackages bool
+	ImportBase        string
+	Layout            string
+	IncludeTypes      string
+	ExcludeTypes      string
 	EnableCustomTypes bool
+	BigDecimal        bool
 	ShowTypeMappings  bool
 	ValidateXML       string
 	CreateSampleXML   bool
@@ -81,7 +92,17 @@ func parseFlags() *XSDConverterConfig {
 	flag.StringVar(&config.ValidationOutputPath, "validation-output", defaultOutputDir, "验证代码输出路径")
 	// 新增多语言和实用功能
 	flag.StringVar(&config.TargetLanguage, "lang", "go", "目标语言 (go, java, csharp, python)")
+	flag.StringVar(&config.MixedContent, "mixed", "ordered", "混合内容的Go表示方式 (ordered, innerxml)")
+	flag.StringVar(&config.AnonymousNaming, "anon-names", "parent", "匿名类型命名策略 (parent, short)")
+	flag.StringVar(&config.AnonymousNameMap, "anon-map", "", "匿名类型名称映射文件 (JSON)")
+	flag.StringVar(&config.NameSuffixes, "name-suffixes", "", "名称冲突时追加的后缀 (如 element=Elem,attribute=Attr)")
+	flag.BoolVar(&config.NamespacePackages, "ns-packages", false, "每个命名空间生成独立的Go包")
+	flag.StringVar(&config.ImportBase, "import-base", "", "输出目录的Go导入路径 (默认根据 go.mod 推断)")
+	flag.StringVar(&config.IncludeTypes, "include", "", "只生成这些类型或元素及其依赖 (逗号分隔，支持通配符，可写作 {命名空间}名称)")
+	flag.StringVar(&config.ExcludeTypes, "exclude", "", "不生成这些类型或元素 (逗号分隔，支持通配符，可写作 {命名空间}名称)")
+	flag.StringVar(&config.Layout, "layout", "", "输出文件布局 (single, per-type, grouped; 默认取配置文件的 output_structure)")
 	flag.BoolVar(&config.EnableCustomTypes, "plc", false, "启用PLC/自定义类型映射")
+	flag.BoolVar(&config.BigDecimal, "big-decimal", false, "xs:decimal 和 xs:integer 使用任意精度类型")
 	flag.BoolVar(&config.ShowTypeMappings, "show-mappings", false, "显示XSD到目标语言的类型映射")
 	flag.StringVar(&config.ValidateXML, "validate", "", "验证XML文件是否符合XSD规范")
 	flag.BoolVar(&config.CreateSampleXML, "sample", false, "根据XSD生成示例XML")
@@ -134,6 +155,28 @@ func validateConfig(config *XSDConverterConfig) error {
 		return fmt.Errorf("不支持的目标语言: %s (支持: %s)", config.TargetLanguage, strings.Join(validLanguages, ", "))
 	}
 
+	// 验证混合内容模式
+	if config.MixedContent != string(generator.MixedContentOrdered) && config.MixedContent != string(generator.MixedContentInnerXML) {
+		return fmt.Errorf("不支持的混合内容模式: %s (支持: ordered, innerxml)", config.MixedContent)
+	}
+
+	// 验证匿名类型命名策略
+	if config.AnonymousNaming != string(xsdparser.AnonymousNamingParent) && config.AnonymousNaming != string(xsdparser.AnonymousNamingShort) {
+		return fmt.Errorf("不支持的匿名类型命名策略: %s (支持: parent, short)", config.AnonymousNaming)
+	}
+
+	// 验证输出布局
+	switch generator.OutputLayout(config.Layout) {
+	case "", generator.LayoutSingleFile, generator.LayoutFilePerType, generator.LayoutGroupByFeature:
+	default:
+		return fmt.Errorf("不支持的输出布局: %s (支持: single, per-type, grouped)", config.Layout)
+	}
+
+	// 验证名称冲突后缀
+	if _, err := types.ParseNameSuffixes(config.NameSuffixes); err != nil {
+		return fmt.Errorf("无效的名称后缀: %v", err)
+	}
+
 	// 如果未提供输出路径或使用默认值，生成基于gen目录的路径
 	if config.OutputPath == "" || config.OutputPath == defaultOutputDir {
 		ext := getLanguageExtension(config.TargetLanguage)
@@ -360,6 +403,17 @@ func runConverter(config *XSDConverterConfig) error { // 获取核心管理器
 	parser.SetDebugMode(config.DebugMode)
 	parser.SetStrictMode(config.StrictMode)
 	parser.SetIncludeComments(config.IncludeComments)
+	anonymousNames, err := loadAnonymousNames(config.AnonymousNameMap)
+	if err != nil {
+		return err
+	}
+	parser.SetAnonymousNaming(xsdparser.AnonymousNaming(config.AnonymousNaming), anonymousNames)
+	nameSuffixes, err := types.ParseNameSuffixes(config.NameSuffixes)
+	if err != nil {
+		return fmt.Errorf("无效的名称后缀: %v", err)
+	}
+	parser.SetNameSuffixes(nameSuffixes)
+	parser.SetArbitraryPrecision(config.BigDecimal)
 
 	// 检查文件大小，对于大型XSD文件使用并发处理
 	fileInfo, err := os.Stat(config.XSDPath)
@@ -425,6 +479,11 @@ func runConverter(config *XSDConverterConfig) error { // 获取核心管理器
 	}
 
 	fmt.Println("解析完成！")
+	printNameChanges(parser.GetNameChanges())
+	goTypes, err := selectTypes(config, parser.GetGoTypes(), parser.GetElementTypes())
+	if err != nil {
+		return err
+	}
 	// 创建代码生成器配置
 	genConfig := generator.NewGeneratorConfig().
 		SetLanguage(generator.TargetLanguage(config.TargetLanguage)).
@@ -437,10 +496,18 @@ func runConverter(config *XSDConverterConfig) error { // 获取核心管理器
 	if config.EnableCustomTypes {
 		genConfig.EnableCustomTypes = true
 	}
+	if config.BigDecimal {
+		genConfig.EnableArbitraryPrecision()
+	}
 	genConfig.IncludeComments = config.IncludeComments
 	genConfig.DebugMode = config.DebugMode
 	genConfig.EnableValidation = config.GenerateValidation
 	genConfig.EnableTestCode = config.GenerateTests
+	genConfig.SetMixedContentMode(generator.MixedContentMode(config.MixedContent))
+	genConfig.SetOutputLayout(outputLayout(config))
+	if err := configureNamespacePackages(config, parser, genConfig); err != nil {
+		return err
+	}
 
 	// 确保输出目录存在
 	outputDir := filepath.Dir(config.OutputPath)
@@ -450,14 +517,27 @@ func runConverter(config *XSDConverterConfig) error { // 获取核心管理器
 
 	// 创建代码生成器工厂
 	factory := generator.NewCodeGeneratorFactory(genConfig)
+	packages, err := factory.Packages(goTypes)
+	if err != nil {
+		return fmt.Errorf("按命名空间拆分包失败: %v", err)
+	}
 
 	// 生成代码
 	fmt.Printf("生成%s代码...\n", strings.ToUpper(config.TargetLanguage))
-	if err := factory.GenerateCode(parser.GetGoTypes()); err != nil {
+	if err := factory.GenerateCode(goTypes); err != nil {
 		return fmt.Errorf("生成代码失败: %v", err)
 	}
 
-	fmt.Printf("✓ 成功！%s结构已生成在: %s\n", strings.ToUpper(config.TargetLanguage), config.OutputPath)
+	if packages != nil {
+		fmt.Printf("✓ 成功！%s结构已按命名空间生成 %d 个包:\n", strings.ToUpper(config.TargetLanguage), len(packages))
+		for _, pkg := range packages {
+			fmt.Printf("   - %s (%s): %s\n", pkg.Name, strings.Join(pkg.Namespaces, ", "), pkg.OutputPath)
+		}
+	} else if layout := genConfig.Layout; layout != generator.LayoutSingleFile {
+		fmt.Printf("✓ 成功！%s结构已按 %s 布局生成在: %s\n", strings.ToUpper(config.TargetLanguage), layout, filepath.Dir(config.OutputPath))
+	} else {
+		fmt.Printf("✓ 成功！%s结构已生成在: %s\n", strings.ToUpper(config.TargetLanguage), config.OutputPath)
+	}
 	// 如果启用了额外的代码生成功能，使用CodeGenerator
 	if config.GenerateValidation || config.GenerateTests || config.GenerateBenchmarks {
 		fmt.Println("------------------------------------------------")
@@ -469,78 +549,18 @@ func runConverter(config *XSDConverterConfig) error { // 获取核心管理器
 			return fmt.Errorf("创建输出目录失败: %v", err)
 		}
 
-		// 创建代码生成器
-		codeGen := generator.NewCodeGenerator(config.PackageName, config.OutputPath)
-		codeGen.SetGoTypes(parser.GetGoTypes())
-		codeGen.SetJSONCompatible(config.EnableJSON)
-		codeGen.SetIncludeComments(config.IncludeComments)
-		codeGen.SetDebugMode(config.DebugMode)
-
-		// 生成验证代码
-		if config.GenerateValidation {
-			fmt.Printf("生成验证代码到: %s\n", config.ValidationOutputPath)
-			validationCode := codeGen.GenerateValidationCode()
-
-			// 添加包声明和必要的导入
-			fullValidationCode := fmt.Sprintf("package %s\n\n%s", config.PackageName, validationCode)
-
-			if err := os.WriteFile(config.ValidationOutputPath, []byte(fullValidationCode), 0644); err != nil {
-				return fmt.Errorf("写入验证代码失败: %v", err)
-			}
-			fmt.Printf("✓ 验证代码已生成在: %s\n", config.ValidationOutputPath)
+		// 每个包单独生成额外代码
+		units := packages
+		if units == nil {
+			units = []generator.GoPackage{{Name: config.PackageName, OutputPath: config.OutputPath, Types: goTypes}}
 		}
-
-		// 生成测试代码
-		if config.GenerateTests {
-			fmt.Printf("生成测试代码到: %s\n", config.TestOutputPath)
-			testCode := codeGen.GenerateTestCode()
-
-			// 添加包声明和必要的导入
-			fullTestCode := fmt.Sprintf("package %s\n\n%s", config.PackageName, testCode)
-
-			if err := os.WriteFile(config.TestOutputPath, []byte(fullTestCode), 0644); err != nil {
-				return fmt.Errorf("写入测试代码失败: %v", err)
-			}
-			fmt.Printf("✓ 测试代码已生成在: %s\n", config.TestOutputPath)
-		}
-
-		// 生成基准测试代码（如果测试代码已启用，基准测试会包含在测试文件中）
-		if config.GenerateBenchmarks && !config.GenerateTests {
-			ext := filepath.Ext(config.OutputPath)
-			baseName := strings.TrimSuffix(filepath.Base(config.OutputPath), ext)
-			outputDir := filepath.Dir(config.OutputPath)
-			benchmarkPath := filepath.Join(outputDir, baseName+"_bench_test.go")
-			fmt.Printf("生成独立基准测试代码到: %s\n", benchmarkPath)
-
-			// 仅生成基准测试部分
-			benchmarkCode := codeGen.GenerateTestCode()
-			// 过滤出只包含基准测试的代码
-			lines := strings.Split(benchmarkCode, "\n")
-			var benchmarkLines []string
-			inBenchmark := false
-			for _, line := range lines {
-				if strings.Contains(line, "func Benchmark") {
-					inBenchmark = true
-				}
-				if inBenchmark {
-					benchmarkLines = append(benchmarkLines, line)
-					if line == "}" && !strings.Contains(line, "func Benchmark") {
-						inBenchmark = false
-					}
-				}
-			}
-
-			fullBenchmarkCode := fmt.Sprintf("package %s\n\nimport (\n\t\"encoding/xml\"\n\t\"testing\"\n\t\"time\"\n)\n\n%s",
-				config.PackageName, strings.Join(benchmarkLines, "\n"))
-
-			if err := os.WriteFile(benchmarkPath, []byte(fullBenchmarkCode), 0644); err != nil {
-				return fmt.Errorf("写入基准测试代码失败: %v", err)
+		for _, unit := range units {
+			if err := generateExtraCode(config, unit, packages != nil); err != nil {
+				return err
 			}
-			fmt.Printf("✓ 基准测试代码已生成在: %s\n", benchmarkPath)
 		}
 
 		// 显示生成的类型统计
-		goTypes := parser.GetGoTypes()
 		if len(goTypes) > 0 {
 			fmt.Printf("📊 代码生成统计:\n")
 			fmt.Printf("   - 生成的类型数量: %d\n", len(goTypes))
@@ -827,9 +847,7 @@ func generateSampleComplexType(builder *strings.Builder, complexType *types.XSDC
 // generateSampleValueForType 根据类型生成示例值
 func generateSampleValueForType(typeName string) string {
 	// 移除命名空间前缀
-	if colonIndex := strings.LastIndex(typeName, ":"); colonIndex != -1 {
-		typeName = typeName[colonIndex+1:]
-	}
+	typeName = types.LocalName(typeName)
 
 	switch typeName {
 	case "string", "normalizedString", "token":
@@ -851,6 +869,199 @@ func generateSampleValueForType(typeName string) string {
 	}
 }
 
+// generateExtraCode 为一个包生成验证、测试和基准测试代码
+func generateExtraCode(config *XSDConverterConfig, unit generator.GoPackage, split bool) error {
+	// 创建代码生成器
+	codeGen := generator.NewCodeGenerator(unit.Name, unit.OutputPath)
+	validationPath, testPath := config.ValidationOutputPath, config.TestOutputPath
+	if split {
+		codeGen.SetPackage(unit)
+		validationPath = filepath.Join(filepath.Dir(unit.OutputPath), filepath.Base(validationPath))
+		testPath = filepath.Join(filepath.Dir(unit.OutputPath), filepath.Base(testPath))
+	} else {
+		codeGen.SetGoTypes(unit.Types)
+	}
+	codeGen.SetJSONCompatible(config.EnableJSON)
+	codeGen.SetIncludeComments(config.IncludeComments)
+	codeGen.SetDebugMode(config.DebugMode)
+	codeGen.SetMixedContentMode(generator.MixedContentMode(config.MixedContent))
+	codeGen.SetArbitraryPrecision(config.BigDecimal)
+
+	// 生成验证代码
+	if config.GenerateValidation {
+		fmt.Printf("生成验证代码到: %s\n", validationPath)
+		validationCode := codeGen.GenerateValidationCode()
+
+		// 添加包声明和必要的导入
+		fullValidationCode := fmt.Sprintf("package %s\n\n%s", unit.Name, validationCode)
+
+		if err := os.WriteFile(validationPath, []byte(fullValidationCode), 0644); err != nil {
+			return fmt.Errorf("写入验证代码失败: %v", err)
+		}
+		fmt.Printf("✓ 验证代码已生成在: %s\n", validationPath)
+	}
+
+	// 生成测试代码
+	if config.GenerateTests {
+		fmt.Printf("生成测试代码到: %s\n", testPath)
+		testCode := codeGen.GenerateTestCode()
+
+		// 添加包声明和必要的导入
+		fullTestCode := fmt.Sprintf("package %s\n\n%s", unit.Name, testCode)
+
+		if err := os.WriteFile(testPath, []byte(fullTestCode), 0644); err != nil {
+			return fmt.Errorf("写入测试代码失败: %v", err)
+		}
+		fmt.Printf("✓ 测试代码已生成在: %s\n", testPath)
+	}
+
+	// 生成基准测试代码（如果测试代码已启用，基准测试会包含在测试文件中）
+	if config.GenerateBenchmarks && !config.GenerateTests {
+		ext := filepath.Ext(unit.OutputPath)
+		baseName := strings.TrimSuffix(filepath.Base(unit.OutputPath), ext)
+		outputDir := filepath.Dir(unit.OutputPath)
+		benchmarkPath := filepath.Join(outputDir, baseName+"_bench_test.go")
+		fmt.Printf("生成独立基准测试代码到: %s\n", benchmarkPath)
+
+		// 仅生成基准测试部分
+		benchmarkCode := codeGen.GenerateTestCode()
+		// 过滤出只包含基准测试的代码
+		lines := strings.Split(benchmarkCode, "\n")
+		var benchmarkLines []string
+		inBenchmark := false
+		for _, line := range lines {
+			if strings.Contains(line, "func Benchmark") {
+				inBenchmark = true
+			}
+			if inBenchmark {
+				benchmarkLines = append(benchmarkLines, line)
+				if line == "}" && !strings.Contains(line, "func Benchmark") {
+					inBenchmark = false
+				}
+			}
+		}
+
+		fullBenchmarkCode := fmt.Sprintf("package %s\n\nimport (\n\t\"encoding/xml\"\n\t\"testing\"\n\t\"time\"\n)\n\n%s",
+			unit.Name, strings.Join(benchmarkLines, "\n"))
+
+		if err := os.WriteFile(benchmarkPath, []byte(fullBenchmarkCode), 0644); err != nil {
+			return fmt.Errorf("写入基准测试代码失败: %v", err)
+		}
+		fmt.Printf("✓ 基准测试代码已生成在: %s\n", benchmarkPath)
+	}
+	return nil
+}
+
+// configureNamespacePackages 在启用时为每个命名空间设置独立的Go包
+func configureNamespacePackages(config *XSDConverterConfig, parser *xsdparser.UnifiedXSDParser, genConfig *generator.GeneratorConfig) error {
+	var custom map[string]string
+	if configManager := core.GetConfigManager(); configManager != nil {
+		custom = configManager.GetConfig().Input.CustomNamespaces
+	}
+	if config.TargetLanguage != "go" || (!config.NamespacePackages && len(custom) == 0) {
+		return nil
+	}
+	for namespace, name := range custom {
+		if !isValidIdentifier(name) {
+			return fmt.Errorf("命名空间 %s 的包名无效: %s", namespace, name)
+		}
+	}
+
+	importBase := config.ImportBase
+	if importBase == "" {
+		detected, err := generator.DetectImportPath(filepath.Dir(config.OutputPath))
+		if err != nil {
+			return fmt.Errorf("无法推断输出目录的导入路径，请使用 -import-base 指定: %v", err)
+		}
+		importBase = detected
+	}
+	genConfig.SetNamespacePackages(parser.NamespacePackages(config.PackageName, custom), importBase)
+	return nil
+}
+
+// selectTypes 按命令行参数和配置文件的 include_types/exclude_types 过滤类型，
+// 全局元素按其内容类型匹配，保留包含类型的全部依赖，并提示仍被引用的已排除类型
+func selectTypes(config *XSDConverterConfig, goTypes []types.GoType, elements map[types.QName]string) ([]types.GoType, error) {
+	include := splitList(config.IncludeTypes)
+	exclude := splitList(config.ExcludeTypes)
+	if configManager := core.GetConfigManager(); configManager != nil {
+		structure := configManager.GetConfig().Output.OutputStructure
+		include = append(include, structure.IncludeTypes...)
+		exclude = append(exclude, structure.ExcludeTypes...)
+	}
+	if len(include) == 0 && len(exclude) == 0 {
+		return goTypes, nil
+	}
+
+	selected, references, err := generator.SelectTypes(goTypes, elements, include, exclude)
+	if err != nil {
+		return nil, fmt.Errorf("类型过滤失败: %v", err)
+	}
+	fmt.Printf("类型过滤: 生成 %d / %d 个类型\n", len(selected), len(goTypes))
+	if len(references) > 0 {
+		fmt.Printf("⚠️  类型过滤: %d 个已排除的类型仍被引用\n", len(references))
+		for _, reference := range references {
+			fmt.Printf("   - %s (被 %s 引用)\n", reference.Type, strings.Join(reference.ReferencedBy, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// outputLayout 返回输出布局：命令行参数优先，否则取配置文件的 output_structure
+func outputLayout(config *XSDConverterConfig) generator.OutputLayout {
+	if config.Layout != "" {
+		return generator.OutputLayout(config.Layout)
+	}
+	if configManager := core.GetConfigManager(); configManager != nil {
+		structure := configManager.GetConfig().Output.OutputStructure
+		switch {
+		case structure.GroupByFeature:
+			return generator.LayoutGroupByFeature
+		case structure.FilePerType:
+			return generator.LayoutFilePerType
+		}
+	}
+	return generator.LayoutSingleFile
+}
+
+// printNameChanges 打印为解决命名冲突而重命名的标识符
+func printNameChanges(changes []types.NameChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Printf("⚠️  命名冲突: %d 个名称已重命名\n", len(changes))
+	for _, change := range changes {
+		fmt.Printf("   - %s\n", change)
+	}
+}
+
+// loadAnonymousNames 读取匿名类型名称映射文件
+func loadAnonymousNames(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取匿名类型名称映射文件: %v", err)
+	}
+	names := make(map[string]string)
+	if err := json.Unmarshal(content, &names); err != nil {
+		return nil, fmt.Errorf("匿名类型名称映射文件格式错误: %v", err)
+	}
+	return names, nil
+}
+
 // showHelp 显示帮助信息
 func showHelp() {
 	fmt.Println("XSD到Go转换工具 - v3.1 (增强版统一解析器)")
@@ -894,6 +1105,30 @@ func showHelp() {
 	fmt.Println("多语言与实用功能:")
 	fmt.Println("  -lang string")
 	fmt.Println("        目标语言 (go, java, csharp, python) (默认: \"go\")")
+	fmt.Println("  -mixed string")
+	fmt.Println("        混合内容的Go表示方式: ordered 按文档顺序保存文本和子元素, innerxml 保存原始XML (默认: \"ordered\")")
+	fmt.Println("  -anon-names string")
+	fmt.Println("        匿名类型命名策略: parent 连接所有外层名称, short 使用元素名并仅在冲突时加外层名称 (默认: \"parent\")")
+	fmt.Println("  -anon-map string")
+	fmt.Println("        匿名类型名称映射文件 (JSON)，键为路径 (如 \"Order/Items/Item\") 或默认名称 (如 \"OrderItemsItem\")")
+	fmt.Println("  -name-suffixes string")
+	fmt.Println("        名称冲突时追加的后缀，格式 kind=suffix，kind 为 type, element, constant, field, attribute")
+	fmt.Println("        (默认: type=Type,element=Element,constant=Value,field=Field,attribute=Attr)")
+	fmt.Println("  -ns-packages")
+	fmt.Println("        每个命名空间生成独立的Go包目录，包名可在配置文件 input.custom_namespaces 中指定")
+	fmt.Println("  -import-base string")
+	fmt.Println("        输出目录的Go导入路径，用于包之间的导入 (默认根据 go.mod 推断)")
+	fmt.Println("  -include string")
+	fmt.Println("        只生成这些类型或元素及其全部依赖，逗号分隔，支持 * 和 ? 通配符")
+	fmt.Println("        (与配置文件的 output.output_structure.include_types 合并)")
+	fmt.Println("  -exclude string")
+	fmt.Println("        不生成这些类型或元素，仍被引用时给出警告 (与 exclude_types 合并)")
+	fmt.Println("  -layout string")
+	fmt.Println("        输出文件布局: single (单个文件), per-type (每个类型一个文件), grouped (按枚举、简单类型、复杂类型分组)")
+	fmt.Println("        (默认取配置文件的 output.output_structure，未配置时为 single)")
+	fmt.Println("  -big-decimal")
+	fmt.Println("        xs:decimal 和无固定长度的整数类型 (integer, positiveInteger 等) 使用任意精度类型:")
+	fmt.Println("        Go 生成基于 math/big 的 Decimal/Integer, Java 为 BigDecimal/BigInteger, C# 为 decimal, Python 为 Decimal/int")
 	fmt.Println("  -show-mappings")
 	fmt.Println("        显示XSD到目标语言的类型映射")
 	fmt.Println("  -validate string")
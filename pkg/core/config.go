@@ -46,7 +46,7 @@ type OutputConfig struct {
 	OutputStructure OutputStructure   `json:"output_structure" yaml:"output_structure"`
 }
 
-// OutputStructure 输出结构配置
+// OutputStructure 输出结构配置，GroupByFeature 优先于 FilePerType，都未设置时生成单个文件
 type OutputStructure struct {
 	SingleFile     bool     `json:"single_file" yaml:"single_file"`
 	FilePerType    bool     `json:"file_per_type" yaml:"file_per_type"`
@@ -230,8 +230,8 @@ func NewDefaultConfig() *Config {
 			IncludeComments: true,
 			CustomTemplates: make(map[string]string),
 			OutputStructure: OutputStructure{
-				SingleFile:     false,
-				FilePerType:    true,
+				SingleFile:     true,
+				FilePerType:    false,
 				GroupByFeature: false,
 				ExcludeTypes:   []string{},
 				IncludeTypes:   []string{},
@@ -426,6 +426,10 @@ func (cm *ConfigManager) applyEnvironmentVariables(envVars *EnvironmentVariables
 	if envVars.SingleFile != "" {
 		if val, err := strconv.ParseBool(envVars.SingleFile); err == nil {
 			cm.config.Output.OutputStructure.SingleFile = val
+			if val {
+				cm.config.Output.OutputStructure.FilePerType = false
+				cm.config.Output.OutputStructure.GroupByFeature = false
+			}
 		}
 	}
 
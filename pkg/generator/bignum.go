@@ -0,0 +1,320 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// Go types of the arbitrary-precision numbers, written next to the schema
+// types when enabled
+const (
+	DecimalTypeName = "Decimal"
+	IntegerTypeName = "Integer"
+)
+
+// bigNumberXSDTypes maps the XSD types without a fixed size to the
+// arbitrary-precision Go type that holds their values
+var bigNumberXSDTypes = map[string]string{
+	"decimal":            DecimalTypeName,
+	"integer":            IntegerTypeName,
+	"nonNegativeInteger": IntegerTypeName,
+	"positiveInteger":    IntegerTypeName,
+	"nonPositiveInteger": IntegerTypeName,
+	"negativeInteger":    IntegerTypeName,
+}
+
+// BigNumberType returns the arbitrary-precision Go type for a built-in XSD
+// type, if it has one
+func BigNumberType(xsdType string) (string, bool) {
+	goType, exists := bigNumberXSDTypes[xsdType]
+	return goType, exists
+}
+
+// bigNumberConversions maps the arbitrary-precision Go types to the types
+// of the other target languages. C# has no serializable big integer, so
+// integers use decimal, which holds 28 digits.
+var bigNumberConversions = map[TargetLanguage]map[string]string{
+	LanguageJava:   {DecimalTypeName: "BigDecimal", IntegerTypeName: "BigInteger"},
+	LanguageCSharp: {DecimalTypeName: "decimal", IntegerTypeName: "decimal"},
+	LanguagePython: {DecimalTypeName: "Decimal", IntegerTypeName: "int"},
+}
+
+// SetArbitraryPrecision enables the arbitrary-precision Decimal and Integer
+// types for xs:decimal and the integer types without a fixed size
+func (g *CodeGenerator) SetArbitraryPrecision(enable bool) {
+	g.arbitraryPrecision = enable
+}
+
+// bigNumberConversion returns the target language type for an
+// arbitrary-precision Go type
+func (g *CodeGenerator) bigNumberConversion(goType string) (string, bool) {
+	if !g.arbitraryPrecision {
+		return "", false
+	}
+	converted, exists := bigNumberConversions[g.languageMapper.GetLanguage()][goType]
+	return converted, exists
+}
+
+// bigNumberCategory returns the value category of an arbitrary-precision Go
+// type, or of an XSD type held by one when xsdType is set
+func (g *CodeGenerator) bigNumberCategory(goType, xsdType string) (string, bool) {
+	if !g.arbitraryPrecision {
+		return "", false
+	}
+	if xsdType != "" {
+		goType = bigNumberXSDTypes[xsdType]
+	}
+	switch goType {
+	case DecimalTypeName:
+		return valueBigDecimal, true
+	case IntegerTypeName:
+		return valueBigInteger, true
+	}
+	return "", false
+}
+
+// bigNumberBase returns the arbitrary-precision Go type a restricted type
+// is ultimately defined by, if any
+func (g *CodeGenerator) bigNumberBase(goType types.GoType) (string, bool) {
+	if !g.arbitraryPrecision {
+		return "", false
+	}
+	switch underlying := g.goUnderlyingType(goType.BaseType); underlying {
+	case DecimalTypeName, IntegerTypeName:
+		return underlying, true
+	}
+	return "", false
+}
+
+// needsBigNumbers reports which arbitrary-precision types the generated code uses
+func (g *CodeGenerator) needsBigNumbers() (decimal, integer bool) {
+	if !g.arbitraryPrecision {
+		return false, false
+	}
+	uses := func(goType string) {
+		goType = strings.TrimPrefix(strings.TrimPrefix(goType, "[]"), "*")
+		goType, _ = unwrapNillable(goType)
+		switch goType {
+		case DecimalTypeName:
+			decimal = true
+		case IntegerTypeName:
+			integer = true
+		}
+	}
+	for _, goType := range g.goTypes {
+		uses(goType.BaseType)
+		uses(goType.ListItemType)
+		for _, member := range goType.UnionMembers {
+			uses(bigNumberXSDTypes[member.XSDType])
+		}
+		fields := append(g.classFields(goType), g.mixedChildFields(goType)...)
+		for _, field := range fields {
+			uses(field.Type)
+		}
+	}
+	return decimal, integer
+}
+
+// writeGoBigNumberRangeValidation writes the bound checks of a restricted
+// type whose values are arbitrary-precision numbers
+func (g *CodeGenerator) writeGoBigNumberRangeValidation(builder *strings.Builder, goType types.GoType, bigType string) {
+	builder.WriteString(fmt.Sprintf("\tval := %s(v)\n", bigType))
+	bounds := []struct {
+		has        bool
+		value, cmp string
+	}{
+		{goType.HasMinInclusive, goType.MinInclusive, "< 0"},
+		{goType.HasMinExclusive, goType.MinExclusive, "<= 0"},
+		{goType.HasMaxInclusive, goType.MaxInclusive, "> 0"},
+		{goType.HasMaxExclusive, goType.MaxExclusive, ">= 0"},
+	}
+	for _, bound := range bounds {
+		if bound.has {
+			builder.WriteString(fmt.Sprintf("\tif bound, _ := Parse%s(%q); val.Cmp(bound) %s {\n\t\treturn false\n\t}\n", bigType, bound.value, bound.cmp))
+		}
+	}
+}
+
+// writeGoBigNumberDigitsValidation writes the totalDigits and fractionDigits
+// checks of a restricted type whose values are arbitrary-precision numbers
+func (g *CodeGenerator) writeGoBigNumberDigitsValidation(builder *strings.Builder, goType types.GoType, bigType string) {
+	if bigType == IntegerTypeName {
+		if goType.HasTotalDigits {
+			builder.WriteString(fmt.Sprintf("\tif %s(v).Digits() > %s {\n\t\treturn false\n\t}\n", bigType, goType.TotalDigits))
+		}
+		return
+	}
+	total, fraction := "_", "_"
+	if goType.HasTotalDigits {
+		total = "total"
+	}
+	if goType.HasFractionDigits {
+		fraction = "fraction"
+	}
+	builder.WriteString(fmt.Sprintf("\t%s, %s := %s(v).Digits()\n", total, fraction, bigType))
+	if goType.HasTotalDigits {
+		builder.WriteString(fmt.Sprintf("\tif total > %s {\n\t\treturn false\n\t}\n", goType.TotalDigits))
+	}
+	if goType.HasFractionDigits {
+		builder.WriteString(fmt.Sprintf("\tif fraction > %s {\n\t\treturn false\n\t}\n", goType.FractionDigits))
+	}
+}
+
+// writeGoDecimalType writes Decimal, an xs:decimal of arbitrary precision
+// that keeps the digits it was read with
+func (g *CodeGenerator) writeGoDecimalType(builder *strings.Builder) {
+	builder.WriteString("// Decimal is an xs:decimal of arbitrary precision: the unscaled value\n")
+	builder.WriteString("// times ten to the power of minus the scale\n")
+	builder.WriteString("type Decimal struct {\n")
+	builder.WriteString("\tunscaled *big.Int\n")
+	builder.WriteString("\tscale    int\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// NewDecimal returns the decimal unscaled × 10^-scale\n")
+	builder.WriteString("func NewDecimal(unscaled *big.Int, scale int) Decimal {\n")
+	builder.WriteString("\treturn Decimal{unscaled: new(big.Int).Set(unscaled), scale: scale}\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// ParseDecimal parses the lexical form of an xs:decimal\n")
+	builder.WriteString("func ParseDecimal(s string) (Decimal, error) {\n")
+	builder.WriteString("\ttext := strings.TrimSpace(s)\n")
+	builder.WriteString("\tsign := \"\"\n")
+	builder.WriteString("\tif strings.HasPrefix(text, \"+\") || strings.HasPrefix(text, \"-\") {\n")
+	builder.WriteString("\t\tsign, text = text[:1], text[1:]\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tintegral, fraction, _ := strings.Cut(text, \".\")\n")
+	builder.WriteString("\tdigits := integral + fraction\n")
+	builder.WriteString("\tif digits == \"\" || strings.Trim(digits, \"0123456789\") != \"\" {\n")
+	builder.WriteString("\t\treturn Decimal{}, fmt.Errorf(\"invalid decimal %q\", s)\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tunscaled, _ := new(big.Int).SetString(sign+digits, 10)\n")
+	builder.WriteString("\treturn Decimal{unscaled: unscaled, scale: len(fraction)}, nil\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// String returns the decimal with the fraction digits it was made with\n")
+	builder.WriteString("func (d Decimal) String() string {\n")
+	builder.WriteString("\tif d.unscaled == nil {\n")
+	builder.WriteString("\t\treturn \"0\"\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tdigits := new(big.Int).Abs(d.unscaled).String()\n")
+	builder.WriteString("\tif d.scale > 0 {\n")
+	builder.WriteString("\t\tif len(digits) <= d.scale {\n")
+	builder.WriteString("\t\t\tdigits = strings.Repeat(\"0\", d.scale-len(digits)+1) + digits\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t\tdigits = digits[:len(digits)-d.scale] + \".\" + digits[len(digits)-d.scale:]\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tif d.unscaled.Sign() < 0 {\n")
+	builder.WriteString("\t\treturn \"-\" + digits\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn digits\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// Rat returns the value of the decimal as a rational number\n")
+	builder.WriteString("func (d Decimal) Rat() *big.Rat {\n")
+	builder.WriteString("\tvalue := new(big.Rat)\n")
+	builder.WriteString("\tif d.unscaled != nil {\n")
+	builder.WriteString("\t\tvalue.SetInt(d.unscaled)\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tscale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)\n")
+	builder.WriteString("\treturn value.Quo(value, new(big.Rat).SetInt(scale))\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// Cmp compares the values of two decimals, returning -1, 0 or +1\n")
+	builder.WriteString("func (d Decimal) Cmp(other Decimal) int {\n")
+	builder.WriteString("\treturn d.Rat().Cmp(other.Rat())\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// Digits returns the total and fraction digits of the value as the\n")
+	builder.WriteString("// totalDigits and fractionDigits facets count them\n")
+	builder.WriteString("func (d Decimal) Digits() (total, fraction int) {\n")
+	builder.WriteString("\tif d.unscaled == nil || d.unscaled.Sign() == 0 {\n")
+	builder.WriteString("\t\treturn 1, 0\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tdigits := new(big.Int).Abs(d.unscaled).String()\n")
+	builder.WriteString("\tfraction = d.scale\n")
+	builder.WriteString("\tfor fraction > 0 && strings.HasSuffix(digits, \"0\") {\n")
+	builder.WriteString("\t\tdigits, fraction = digits[:len(digits)-1], fraction-1\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tif fraction > len(digits) {\n")
+	builder.WriteString("\t\treturn fraction, fraction\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn len(digits), fraction\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// MarshalText implements encoding.TextMarshaler\n")
+	builder.WriteString("func (d Decimal) MarshalText() ([]byte, error) {\n")
+	builder.WriteString("\treturn []byte(d.String()), nil\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// UnmarshalText implements encoding.TextUnmarshaler\n")
+	builder.WriteString("func (d *Decimal) UnmarshalText(text []byte) error {\n")
+	builder.WriteString("\tvalue, err := ParseDecimal(string(text))\n")
+	builder.WriteString("\tif err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\t*d = value\n")
+	builder.WriteString("\treturn nil\n")
+	builder.WriteString("}\n\n")
+}
+
+// writeGoIntegerType writes Integer, an xs:integer of arbitrary size
+func (g *CodeGenerator) writeGoIntegerType(builder *strings.Builder) {
+	builder.WriteString("// Integer is an xs:integer of arbitrary size\n")
+	builder.WriteString("type Integer struct {\n")
+	builder.WriteString("\tvalue *big.Int\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// NewInteger returns an integer of the given value\n")
+	builder.WriteString("func NewInteger(value *big.Int) Integer {\n")
+	builder.WriteString("\treturn Integer{value: new(big.Int).Set(value)}\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// ParseInteger parses the lexical form of an xs:integer\n")
+	builder.WriteString("func ParseInteger(s string) (Integer, error) {\n")
+	builder.WriteString("\tvalue, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)\n")
+	builder.WriteString("\tif !ok {\n")
+	builder.WriteString("\t\treturn Integer{}, fmt.Errorf(\"invalid integer %q\", s)\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn Integer{value: value}, nil\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// Int returns the value of the integer\n")
+	builder.WriteString("func (i Integer) Int() *big.Int {\n")
+	builder.WriteString("\tif i.value == nil {\n")
+	builder.WriteString("\t\treturn new(big.Int)\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn new(big.Int).Set(i.value)\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// String returns the decimal digits of the integer\n")
+	builder.WriteString("func (i Integer) String() string {\n")
+	builder.WriteString("\treturn i.Int().String()\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// Cmp compares the values of two integers, returning -1, 0 or +1\n")
+	builder.WriteString("func (i Integer) Cmp(other Integer) int {\n")
+	builder.WriteString("\treturn i.Int().Cmp(other.Int())\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// Digits returns the number of decimal digits of the integer\n")
+	builder.WriteString("func (i Integer) Digits() int {\n")
+	builder.WriteString("\treturn len(new(big.Int).Abs(i.Int()).String())\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// MarshalText implements encoding.TextMarshaler\n")
+	builder.WriteString("func (i Integer) MarshalText() ([]byte, error) {\n")
+	builder.WriteString("\treturn []byte(i.String()), nil\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// UnmarshalText implements encoding.TextUnmarshaler\n")
+	builder.WriteString("func (i *Integer) UnmarshalText(text []byte) error {\n")
+	builder.WriteString("\tvalue, err := ParseInteger(string(text))\n")
+	builder.WriteString("\tif err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\t*i = value\n")
+	builder.WriteString("\treturn nil\n")
+	builder.WriteString("}\n\n")
+}
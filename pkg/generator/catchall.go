@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// catchAllFields returns the element fields of a type tagged ",any": the
+// substitution group fields and the wildcard field
+func (g *CodeGenerator) catchAllFields(goType types.GoType) []types.GoField {
+	fields := make([]types.GoField, 0)
+	for _, field := range g.classFields(goType) {
+		if field.XMLTag == ",any" && !field.IsAttribute {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// splitsCatchAll reports whether a type needs its own UnmarshalXML to share
+// out unmatched elements. encoding/xml only fills the first ",any" field of
+// a struct, so a type with several of them decodes all unmatched elements
+// into one and hands each to the field it belongs to.
+func (g *CodeGenerator) splitsCatchAll(goType types.GoType) bool {
+	if goType.IsMixed && g.mixedContentMode != MixedContentInnerXML {
+		return false // the ordered mixed UnmarshalXML decodes every child itself
+	}
+	return len(g.catchAllFields(goType)) > 1
+}
+
+// needsCatchAllSplit reports whether any type splits its unmatched elements
+func (g *CodeGenerator) needsCatchAllSplit() bool {
+	for _, goType := range g.goTypes {
+		if !goType.IsSubstitutionGroup && !goType.IsEnum && g.splitsCatchAll(goType) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeGoCatchAllMethods writes the UnmarshalXML of a type with several
+// ",any" fields. The elements no other field matches are collected by a
+// leading catch-all and assigned by qualified element name to the
+// substitution group they are a member of, or else to the wildcard field.
+// Encoding needs no help, as encoding/xml writes every ",any" field.
+func (g *CodeGenerator) writeGoCatchAllMethods(builder *strings.Builder, goType types.GoType) {
+	if !g.splitsCatchAll(goType) {
+		return
+	}
+
+	builder.WriteString("\n// UnmarshalXML decodes the element and hands each element no other field\n")
+	builder.WriteString("// matches to the substitution group or wildcard field it belongs to\n")
+	builder.WriteString(fmt.Sprintf("func (v *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n", goType.Name))
+	builder.WriteString(fmt.Sprintf("\ttype plain %s\n", goType.Name))
+	builder.WriteString("\tvar content struct {\n")
+	builder.WriteString("\t\tUnmatched []AnyElement `xml:\",any\"`\n")
+	builder.WriteString("\t\t*plain\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tcontent.plain = (*plain)(v)\n")
+	builder.WriteString("\tif err := d.DecodeElement(&content, &start); err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tfor _, element := range content.Unmatched {\n")
+	builder.WriteString("\t\tswitch element.XMLName {\n")
+
+	var wildcard *types.GoField
+	for _, field := range g.catchAllFields(goType) {
+		if field.IsWildcard {
+			if wildcard == nil {
+				field := field
+				wildcard = &field
+			}
+			continue
+		}
+		groupName := strings.TrimPrefix(strings.TrimPrefix(field.Type, "[]"), "*")
+		group := g.findGoType(groupName)
+		if group == nil || len(group.Members) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(group.Members))
+		for _, member := range group.Members {
+			names = append(names, fmt.Sprintf("xml.Name{Space: %q, Local: %q}", member.Namespace, member.ElementName))
+		}
+		builder.WriteString(fmt.Sprintf("\t\tcase %s:\n", strings.Join(names, ", ")))
+		builder.WriteString(fmt.Sprintf("\t\t\tvar member %s\n", groupName))
+		builder.WriteString("\t\t\tif err := element.Decode(&member); err != nil {\n")
+		builder.WriteString("\t\t\t\treturn err\n")
+		builder.WriteString("\t\t\t}\n")
+		switch {
+		case field.IsArray:
+			builder.WriteString(fmt.Sprintf("\t\t\tv.%s = append(v.%s, member)\n", field.Name, field.Name))
+		case strings.HasPrefix(field.Type, "*"):
+			builder.WriteString(fmt.Sprintf("\t\t\tv.%s = &member\n", field.Name))
+		default:
+			builder.WriteString(fmt.Sprintf("\t\t\tv.%s = member\n", field.Name))
+		}
+	}
+	if wildcard != nil {
+		builder.WriteString("\t\tdefault:\n")
+		builder.WriteString(fmt.Sprintf("\t\t\tv.%s = append(v.%s, element)\n", wildcard.Name, wildcard.Name))
+	}
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t}\n")
+	if g.hasGoDefaults(goType) {
+		builder.WriteString("\tv.SetDefaults()\n")
+	}
+	builder.WriteString("\treturn nil\n")
+	builder.WriteString("}\n")
+}
+
+// writeGoAnyElementDecode writes the method that decodes a recorded element
+// into a value, replaying its tokens
+func (g *CodeGenerator) writeGoAnyElementDecode(builder *strings.Builder) {
+	builder.WriteString("// Decode decodes the recorded element into v\n")
+	builder.WriteString("func (a AnyElement) Decode(v interface{}) error {\n")
+	builder.WriteString("\tstart := xml.StartElement{Name: a.XMLName, Attr: a.Attrs}\n")
+	builder.WriteString("\ttokens := append(append([]xml.Token{start}, a.Tokens...), start.End())\n")
+	builder.WriteString("\treturn xml.NewTokenDecoder(&anyElementTokens{tokens: tokens}).Decode(v)\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// anyElementTokens replays the tokens of a recorded element\n")
+	builder.WriteString("type anyElementTokens struct {\n")
+	builder.WriteString("\ttokens []xml.Token\n")
+	builder.WriteString("}\n\n")
+	builder.WriteString("// Token returns the next recorded token\n")
+	builder.WriteString("func (r *anyElementTokens) Token() (xml.Token, error) {\n")
+	builder.WriteString("\tif len(r.tokens) == 0 {\n")
+	builder.WriteString("\t\treturn nil, io.EOF\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\ttoken := r.tokens[0]\n")
+	builder.WriteString("\tr.tokens = r.tokens[1:]\n")
+	builder.WriteString("\treturn token, nil\n")
+	builder.WriteString("}\n\n")
+}
@@ -3,6 +3,7 @@ package generator
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -50,9 +51,7 @@ type BaseLanguageMapper struct{}
 // FormatTypeName provides common type name formatting logic
 func (b *BaseLanguageMapper) FormatTypeName(typeName string) string {
 	// Remove namespace prefix
-	if colonIndex := strings.LastIndex(typeName, ":"); colonIndex != -1 {
-		typeName = typeName[colonIndex+1:]
-	}
+	typeName = types.LocalName(typeName)
 
 	// Convert to PascalCase
 	parts := strings.FieldsFunc(typeName, func(c rune) bool {
@@ -325,6 +324,7 @@ func (p *PythonLanguageMapper) GetImportStatements() []string {
 		"from dataclasses import dataclass, field",
 		"from typing import List, Optional, Any",
 		"from datetime import datetime, date, time, timedelta",
+		"from decimal import Decimal",
 		"from enum import Enum",
 		"import xml.etree.ElementTree as ET",
 	}
@@ -356,6 +356,11 @@ type CodeGenerator struct {
 	enableCustomTypes bool // 控制是否启用自定义类型映射
 	languageMapper    LanguageMapper
 	typeMappings      map[string]string // Cache for type mappings
+	mixedContentMode  MixedContentMode
+	goPackage         *GoPackage // set when writing one package of a per-namespace output
+	layout            OutputLayout
+	// arbitraryPrecision maps xs:decimal and xs:integer to Decimal and Integer
+	arbitraryPrecision bool
 }
 
 // NewCodeGenerator creates a new code generator
@@ -370,6 +375,8 @@ func NewCodeGenerator(packageName, outputPath string) *CodeGenerator {
 		enableCustomTypes: false,               // 默认关闭自定义类型映射
 		languageMapper:    &GoLanguageMapper{}, // Default to Go
 		typeMappings:      make(map[string]string),
+		mixedContentMode:  MixedContentOrdered,
+		layout:            LayoutSingleFile,
 	}
 	generator.initializeTypeMappings()
 	return generator
@@ -437,43 +444,59 @@ func (g *CodeGenerator) GetBuiltinTypeMappings() []TypeMapping {
 	return g.languageMapper.GetBuiltinTypeMappings()
 }
 
-// Generate generates the code for the target language and writes it to the output file
+// Generate generates the code for the target language and writes it to the
+// files of the output layout
 func (g *CodeGenerator) Generate() error {
 	if g.debugMode {
 		fmt.Printf("Generating %s code for %d types\n", g.languageMapper.GetLanguage(), len(g.goTypes))
 	}
 
-	code := g.generateCode()
-
-	if err := os.WriteFile(g.outputPath, []byte(code), 0644); err != nil {
-		return fmt.Errorf("failed to write output file: %v", err)
+	files := g.outputFiles()
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %v", err)
+		}
+		if err := os.WriteFile(file.path, []byte(g.generateCode(file, files)), 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %v", err)
+		}
+		if g.debugMode {
+			fmt.Printf("Generated code written to: %s\n", file.path)
+		}
 	}
 
-	if g.debugMode {
-		fmt.Printf("Generated code written to: %s\n", g.outputPath)
+	// A split Python output is a package that re-exports its classes
+	if g.languageMapper.GetLanguage() == LanguagePython && g.layout != LayoutSingleFile {
+		initPath := filepath.Join(filepath.Dir(g.outputPath), "__init__.py")
+		if err := os.WriteFile(initPath, []byte(g.pythonPackageInit(files)), 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %v", err)
+		}
 	}
 
 	return nil
 }
 
-// generateCode generates the complete code for the target language
-func (g *CodeGenerator) generateCode() string {
-	var builder strings.Builder
-
-	// Package declaration and imports
-	g.writeHeader(&builder)
+// generateCode generates the complete code of one output file. The body is
+// generated first so that the header imports only what it uses.
+func (g *CodeGenerator) generateCode(file outputFile, files []outputFile) string {
+	var body strings.Builder
 
 	// Generate types
-	for _, goType := range g.goTypes {
-		g.writeType(&builder, goType)
-		builder.WriteString("\n")
+	for _, goType := range file.types {
+		g.writeType(&body, goType)
+		body.WriteString("\n")
 	}
 
 	// Generate helper functions for Go if needed
-	if g.languageMapper.GetLanguage() == LanguageGo {
-		g.writeGoHelperFunctions(&builder)
+	if file.helpers && g.languageMapper.GetLanguage() == LanguageGo {
+		g.writeGoHelperFunctions(&body)
 	}
 
+	var builder strings.Builder
+
+	// Package declaration and imports
+	g.writeHeader(&builder, file, g.fileImports(file, body.String(), files))
+	builder.WriteString(body.String())
+
 	// Close namespace for C#
 	if g.languageMapper.GetLanguage() == LanguageCSharp {
 		builder.WriteString("}\n")
@@ -483,58 +506,67 @@ func (g *CodeGenerator) generateCode() string {
 }
 
 // writeHeader writes the package declaration and imports for the target language
-func (g *CodeGenerator) writeHeader(builder *strings.Builder) {
+func (g *CodeGenerator) writeHeader(builder *strings.Builder, file outputFile, imports []string) {
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 
 	switch g.languageMapper.GetLanguage() {
 	case LanguagePython:
 		builder.WriteString("# Code generated by xsd2code v3.0; DO NOT EDIT.\n")
 		builder.WriteString("# Generated on " + timestamp + "\n\n")
-		g.writePythonHeader(builder)
+		g.writePythonHeader(builder, imports)
 	default:
 		builder.WriteString("// Code generated by xsd2code v3.0; DO NOT EDIT.\n")
 		builder.WriteString("// Generated on " + timestamp + "\n\n")
 		switch g.languageMapper.GetLanguage() {
 		case LanguageGo:
-			g.writeGoHeader(builder)
+			g.writeGoHeader(builder, imports)
 		case LanguageJava:
-			g.writeJavaHeader(builder)
+			g.writeJavaHeader(builder, file, imports)
 		case LanguageCSharp:
-			g.writeCSharpHeader(builder)
+			g.writeCSharpHeader(builder, imports)
 		default:
-			g.writeGoHeader(builder) // Fallback to Go
+			g.writeGoHeader(builder, imports) // Fallback to Go
 		}
 	}
 }
 
 // writeGoHeader writes Go-specific package and imports
-func (g *CodeGenerator) writeGoHeader(builder *strings.Builder) {
+func (g *CodeGenerator) writeGoHeader(builder *strings.Builder, imports []string) {
 	builder.WriteString("package " + g.packageName + "\n\n")
-
-	// Determine needed imports dynamically
-	neededImports := g.determineNeededImports()
+	if len(imports) == 0 {
+		return
+	}
 
 	// Imports
 	builder.WriteString("import (\n")
-	for _, importStmt := range neededImports {
+	for _, importStmt := range imports {
+		if importStmt == "" {
+			builder.WriteString("\n")
+			continue
+		}
 		builder.WriteString("\t" + importStmt + "\n")
 	}
 	builder.WriteString(")\n\n")
 }
 
-// writeJavaHeader writes Java-specific package and imports
-func (g *CodeGenerator) writeJavaHeader(builder *strings.Builder) {
-	builder.WriteString("package " + g.packageName + ";\n\n")
+// writeJavaHeader writes Java-specific package and imports. Files of the
+// grouped layout belong to a subpackage.
+func (g *CodeGenerator) writeJavaHeader(builder *strings.Builder, file outputFile, imports []string) {
+	packageName := g.packageName
+	if file.pkg != "" {
+		packageName += "." + file.pkg
+	}
+	builder.WriteString("package " + packageName + ";\n\n")
 
-	for _, importStmt := range g.languageMapper.GetImportStatements() {
+	for _, importStmt := range imports {
 		builder.WriteString(importStmt + "\n")
 	}
 	builder.WriteString("\n")
 }
 
 // writeCSharpHeader writes C#-specific namespace and using statements
-func (g *CodeGenerator) writeCSharpHeader(builder *strings.Builder) {
-	for _, importStmt := range g.languageMapper.GetImportStatements() {
+func (g *CodeGenerator) writeCSharpHeader(builder *strings.Builder, imports []string) {
+	for _, importStmt := range imports {
 		builder.WriteString(importStmt + "\n")
 	}
 	builder.WriteString("\n")
@@ -542,8 +574,8 @@ func (g *CodeGenerator) writeCSharpHeader(builder *strings.Builder) {
 }
 
 // writePythonHeader writes Python-specific imports
-func (g *CodeGenerator) writePythonHeader(builder *strings.Builder) {
-	for _, importStmt := range g.languageMapper.GetImportStatements() {
+func (g *CodeGenerator) writePythonHeader(builder *strings.Builder, imports []string) {
+	for _, importStmt := range imports {
 		builder.WriteString(importStmt + "\n")
 	}
 	builder.WriteString("\n")
@@ -552,7 +584,8 @@ func (g *CodeGenerator) writePythonHeader(builder *strings.Builder) {
 // needsTimePackage checks if the generated code needs the time package
 func (g *CodeGenerator) needsTimePackage() bool {
 	for _, goType := range g.goTypes {
-		for _, field := range goType.Fields {
+		fields := append(g.classFields(goType), g.mixedChildFields(goType)...)
+		for _, field := range fields {
 			if strings.Contains(field.Type, "time.Time") {
 				return true
 			}
@@ -602,6 +635,44 @@ func (g *CodeGenerator) determineNeededImports() []string {
 		imports["\"strings\""] = true
 	}
 
+	// Union and list types convert text when decoding and parse values
+	for _, goType := range g.goTypes {
+		if goType.IsUnion || goType.IsList {
+			imports["\"strings\""] = true
+			break
+		}
+	}
+	// Arbitrary-precision numbers are parsed and formatted with math/big
+	if decimal, integer := g.needsBigNumbers(); decimal || integer {
+		imports["\"fmt\""] = true
+		imports["\"math/big\""] = true
+		imports["\"strings\""] = true
+	}
+	// Ordered mixed content re-encodes attributes and replays tokens
+	if g.needsMixedItems() {
+		imports["\"bytes\""] = true
+		imports["\"io\""] = true
+	}
+	// Elements shared out by a catch-all are decoded from their tokens
+	if g.needsCatchAllSplit() {
+		imports["\"io\""] = true
+	}
+
+	if g.unionPackages() || g.listPackages() {
+		imports["\"strconv\""] = true
+	}
+	// Dates, times and durations use the types of the xsd package
+	if g.needsXSDPackage() {
+		imports["\""+xsdPackagePath+"\""] = true
+	}
+
+	// Packages of a per-namespace output import the packages they refer to
+	if g.goPackage != nil {
+		for _, importPath := range g.goPackage.Imports {
+			imports["\""+importPath+"\""] = true
+		}
+	}
+
 	// Convert map to sorted slice
 	result := make([]string, 0, len(imports))
 	for imp := range imports {
@@ -613,6 +684,16 @@ func (g *CodeGenerator) determineNeededImports() []string {
 
 // writeType writes a single type for the target language
 func (g *CodeGenerator) writeType(builder *strings.Builder, goType types.GoType) {
+	// Point back at the defining document for multi-file schemas
+	if g.includeComments && goType.SourceFile != "" {
+		if g.languageMapper.GetLanguage() == LanguagePython {
+			g.writePythonComment(builder, "Source: "+goType.SourceFile, "")
+		} else {
+			g.writeComment(builder, "Source: "+goType.SourceFile, "")
+		}
+		builder.WriteString("\n")
+	}
+
 	switch g.languageMapper.GetLanguage() {
 	case LanguageGo:
 		g.writeGoType(builder, goType)
@@ -629,13 +710,17 @@ func (g *CodeGenerator) writeType(builder *strings.Builder, goType types.GoType)
 
 // writeGoType writes a Go type
 func (g *CodeGenerator) writeGoType(builder *strings.Builder, goType types.GoType) {
-	if goType.IsEnum {
+	defer g.writeGoInterfaceMethods(builder, goType)
+
+	if goType.IsSubstitutionGroup {
+		g.writeGoSubstitutionGroupType(builder, goType)
+	} else if goType.IsList {
+		g.writeGoListType(builder, goType)
+	} else if goType.IsUnion {
+		g.writeGoUnionType(builder, goType)
+	} else if goType.IsEnum {
 		g.writeGoEnumType(builder, goType)
-	} else if goType.HasPattern || goType.HasMinLength || goType.HasMaxLength ||
-		goType.HasMinInclusive || goType.HasMaxInclusive ||
-		goType.HasMinExclusive || goType.HasMaxExclusive ||
-		goType.HasTotalDigits || goType.HasFractionDigits ||
-		goType.HasWhiteSpace || goType.HasLength || goType.HasFixedValue {
+	} else if hasGoValidation(goType) {
 		// This is a simple type with restrictions (like pattern, whiteSpace, length, or fixed value)
 		g.writeGoRestrictedType(builder, goType)
 	} else {
@@ -643,9 +728,25 @@ func (g *CodeGenerator) writeGoType(builder *strings.Builder, goType types.GoTyp
 	}
 }
 
+// hasGoValidation reports whether a simple type has restrictions that the Go
+// output checks in a Validate method
+func hasGoValidation(goType types.GoType) bool {
+	return goType.HasPattern || goType.HasMinLength || goType.HasMaxLength ||
+		goType.HasMinInclusive || goType.HasMaxInclusive ||
+		goType.HasMinExclusive || goType.HasMaxExclusive ||
+		goType.HasTotalDigits || goType.HasFractionDigits ||
+		goType.HasWhiteSpace || goType.HasLength || goType.HasFixedValue
+}
+
 // writeJavaType writes a Java type
 func (g *CodeGenerator) writeJavaType(builder *strings.Builder, goType types.GoType) {
-	if goType.IsEnum {
+	if goType.IsSubstitutionGroup {
+		g.writeJavaSubstitutionGroupType(builder, goType)
+	} else if goType.IsList {
+		g.writeJavaListType(builder, goType)
+	} else if goType.IsUnion {
+		g.writeJavaUnionType(builder, goType)
+	} else if goType.IsEnum {
 		g.writeJavaEnumType(builder, goType)
 	} else if goType.HasPattern || goType.HasMinLength || goType.HasMaxLength ||
 		goType.HasMinInclusive || goType.HasMaxInclusive ||
@@ -660,7 +761,13 @@ func (g *CodeGenerator) writeJavaType(builder *strings.Builder, goType types.GoT
 
 // writeCSharpType writes a C# type
 func (g *CodeGenerator) writeCSharpType(builder *strings.Builder, goType types.GoType) {
-	if goType.IsEnum {
+	if goType.IsSubstitutionGroup {
+		g.writeCSharpSubstitutionGroupType(builder, goType)
+	} else if goType.IsList {
+		g.writeCSharpListType(builder, goType)
+	} else if goType.IsUnion {
+		g.writeCSharpUnionType(builder, goType)
+	} else if goType.IsEnum {
 		if csharpMapper, ok := g.languageMapper.(*CSharpLanguageMapper); ok {
 			csharpMapper.writeCSharpEnumType(builder, goType, g)
 		}
@@ -677,7 +784,13 @@ func (g *CodeGenerator) writeCSharpType(builder *strings.Builder, goType types.G
 
 // writePythonType writes a Python type
 func (g *CodeGenerator) writePythonType(builder *strings.Builder, goType types.GoType) {
-	if goType.IsEnum {
+	if goType.IsSubstitutionGroup {
+		g.writePythonSubstitutionGroupType(builder, goType)
+	} else if goType.IsList {
+		g.writePythonListType(builder, goType)
+	} else if goType.IsUnion {
+		g.writePythonUnionType(builder, goType)
+	} else if goType.IsEnum {
 		g.writePythonEnumType(builder, goType)
 	} else if goType.HasPattern || goType.HasMinLength || goType.HasMaxLength ||
 		goType.HasMinInclusive || goType.HasMaxInclusive ||
@@ -698,6 +811,9 @@ func (g *CodeGenerator) writeGoStructType(builder *strings.Builder, goType types
 	} else if g.includeComments {
 		g.writeComment(builder, fmt.Sprintf("%s represents %s", goType.Name, goType.XMLName), "")
 	}
+	if g.includeComments && goType.IsAbstract {
+		builder.WriteString("//\n// The schema declares this type abstract; instances use a derived type.\n")
+	}
 
 	// Write type declaration
 	builder.WriteString(fmt.Sprintf("type %s struct {\n", goType.Name))
@@ -713,11 +829,113 @@ func (g *CodeGenerator) writeGoStructType(builder *strings.Builder, goType types
 	}
 
 	// Write fields
-	for _, field := range goType.Fields {
+	for _, field := range g.classFields(goType) {
 		g.writeGoField(builder, field)
 	}
 
 	builder.WriteString("}\n")
+
+	if goType.IsMixed {
+		g.writeGoMixedMethods(builder, goType)
+	}
+	g.writeGoCatchAllMethods(builder, goType)
+	g.writeGoDefaultMethods(builder, goType)
+}
+
+// writeGoSubstitutionGroupType writes the interface implemented by the
+// members of a substitution group and a holder type that decodes whichever
+// member element it is given
+func (g *CodeGenerator) writeGoSubstitutionGroupType(builder *strings.Builder, goType types.GoType) {
+	if g.includeComments {
+		g.writeComment(builder, fmt.Sprintf("%s is implemented by the types of all elements in the %s substitution group", goType.MemberInterface, goType.XMLName), "")
+	}
+	builder.WriteString(fmt.Sprintf("type %s interface {\n", goType.MemberInterface))
+	builder.WriteString(fmt.Sprintf("\t%s()\n", g.memberMethod(goType.MemberInterface)))
+	builder.WriteString("}\n\n")
+
+	if g.includeComments {
+		g.writeComment(builder, fmt.Sprintf("%s %s", goType.Name, goType.Comment), "")
+	}
+	builder.WriteString(fmt.Sprintf("type %s struct {\n", goType.Name))
+	builder.WriteString("\tXMLName xml.Name\n")
+	builder.WriteString(fmt.Sprintf("\tValue   %s\n", goType.MemberInterface))
+	builder.WriteString("}\n\n")
+
+	// UnmarshalXML dispatches on the qualified element name. Member structs
+	// carry an XMLName bound to their type name, so they are decoded under
+	// that name.
+	if g.includeComments {
+		g.writeComment(builder, "UnmarshalXML decodes the member element named by start", "")
+	}
+	builder.WriteString(fmt.Sprintf("func (g *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n", goType.Name))
+	builder.WriteString(fmt.Sprintf("\tvar value %s\n", goType.MemberInterface))
+	builder.WriteString("\tmemberStart := start\n")
+	builder.WriteString("\tswitch start.Name {\n")
+	for _, member := range goType.Members {
+		builder.WriteString(fmt.Sprintf("\tcase xml.Name{Space: %q, Local: %q}:\n", member.Namespace, member.ElementName))
+		builder.WriteString(fmt.Sprintf("\t\tvalue = &%s{}\n", member.TypeName))
+		if memberType := g.findGoType(member.TypeName); memberType != nil && memberType.XMLName != "" && !memberType.IsEnum {
+			builder.WriteString(fmt.Sprintf("\t\tmemberStart.Name = xml.Name{Space: %q, Local: %q}\n", memberType.Namespace, memberType.XMLName))
+		}
+	}
+	builder.WriteString("\tdefault:\n")
+	builder.WriteString("\t\treturn d.Skip()\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tif err := d.DecodeElement(value, &memberStart); err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tg.XMLName = start.Name\n")
+	builder.WriteString("\tg.Value = value\n")
+	builder.WriteString("\treturn nil\n")
+	builder.WriteString("}\n\n")
+
+	if g.includeComments {
+		g.writeComment(builder, "MarshalXML encodes the held member under its element name", "")
+	}
+	builder.WriteString(fmt.Sprintf("func (g %s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n", goType.Name))
+	builder.WriteString("\tif g.Value == nil {\n")
+	builder.WriteString("\t\treturn nil\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tstart.Name = g.XMLName\n")
+	builder.WriteString("\tif start.Name.Local == \"\" {\n")
+	builder.WriteString("\t\tswitch g.Value.(type) {\n")
+	seen := make(map[string]bool)
+	for _, member := range goType.Members {
+		if seen[member.TypeName] {
+			continue
+		}
+		seen[member.TypeName] = true
+		builder.WriteString(fmt.Sprintf("\t\tcase *%s, %s:\n", member.TypeName, member.TypeName))
+		builder.WriteString(fmt.Sprintf("\t\t\tstart.Name = xml.Name{Space: %q, Local: %q}\n", member.Namespace, member.ElementName))
+	}
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn e.EncodeElement(g.Value, start)\n")
+	builder.WriteString("}\n")
+}
+
+// writeGoInterfaceMethods writes the marker methods that make a type a
+// member of substitution group interfaces
+func (g *CodeGenerator) writeGoInterfaceMethods(builder *strings.Builder, goType types.GoType) {
+	for _, iface := range goType.Implements {
+		builder.WriteString(fmt.Sprintf("\nfunc (%s) %s() {}\n", goType.Name, g.memberMethod(iface)))
+	}
+}
+
+// findGoType returns the generated type with the given name, or nil. Types
+// of other packages are found by their qualified name.
+func (g *CodeGenerator) findGoType(name string) *types.GoType {
+	for i := range g.goTypes {
+		if g.goTypes[i].Name == name {
+			return &g.goTypes[i]
+		}
+	}
+	if g.goPackage != nil {
+		if external, exists := g.goPackage.external[name]; exists {
+			return &external
+		}
+	}
+	return nil
 }
 
 // writeGoEnumType writes a Go enum type with constants
@@ -833,6 +1051,9 @@ func (g *CodeGenerator) writeComment(builder *strings.Builder, comment, indent s
 
 // GenerateValidationCode generates validation functions for XSD types
 func (g *CodeGenerator) GenerateValidationCode() string {
+	var references strings.Builder
+	g.writeGoReferenceValidators(&references)
+
 	var builder strings.Builder
 	builder.WriteString("// Generated validation functions\n\n")
 	builder.WriteString("import (\n")
@@ -840,6 +1061,10 @@ func (g *CodeGenerator) GenerateValidationCode() string {
 	builder.WriteString("\t\"regexp\"\n")
 	builder.WriteString("\t\"strings\"\n")
 	builder.WriteString("\t\"time\"\n")
+	// Identity constraints key dates, times and durations in their value space
+	if strings.Contains(references.String(), "xsd.Key(") {
+		builder.WriteString("\n\t\"" + xsdPackagePath + "\"\n")
+	}
 	builder.WriteString(")\n\n")
 
 	// Generate validation interface
@@ -849,8 +1074,13 @@ func (g *CodeGenerator) GenerateValidationCode() string {
 	builder.WriteString("}\n\n")
 	// Generate validation functions for each type
 	for _, goType := range g.goTypes {
+		// Restricted, list and union types have their own Validate method
+		if goType.IsList || goType.IsUnion || hasGoValidation(goType) {
+			continue
+		}
 		g.generateTypeValidator(&builder, &goType)
 	}
+	builder.WriteString(references.String())
 
 	// Generate helper validation functions
 	g.generateValidationHelpers(&builder)
@@ -866,7 +1096,7 @@ func (g *CodeGenerator) generateTypeValidator(builder *strings.Builder, goType *
 	builder.WriteString(fmt.Sprintf("func (v *%s) Validate() error {\n", typeName))
 
 	// Generate field validations
-	for _, field := range goType.Fields {
+	for _, field := range g.classFields(*goType) {
 		g.generateFieldValidation(builder, &field, typeName)
 	}
 
@@ -1015,6 +1245,9 @@ func (g *CodeGenerator) GenerateTestCode() string {
 	builder.WriteString("\t\"encoding/xml\"\n")
 	builder.WriteString("\t\"testing\"\n")
 	builder.WriteString("\t\"time\"\n")
+	if g.needsXSDPackage() {
+		builder.WriteString(fmt.Sprintf("\n\t%q\n", xsdPackagePath))
+	}
 	builder.WriteString(")\n\n")
 	// Generate test functions for each type
 	for _, goType := range g.goTypes {
@@ -1047,7 +1280,7 @@ func (g *CodeGenerator) generateTypeTest(builder *strings.Builder, goType *types
 		builder.WriteString(fmt.Sprintf("\toriginal := &%s{\n", typeName))
 
 		// Generate test data for fields
-		for _, field := range goType.Fields {
+		for _, field := range g.classFields(*goType) {
 			g.generateTestFieldData(builder, &field)
 		}
 
@@ -1083,6 +1316,11 @@ func (g *CodeGenerator) generateTypeTest(builder *strings.Builder, goType *types
 
 // generateTestFieldData generates test data for a field
 func (g *CodeGenerator) generateTestFieldData(builder *strings.Builder, field *types.GoField) {
+	// Wildcard and mixed content have no meaningful sample value
+	if field.IsWildcard || field.IsMixed {
+		return
+	}
+
 	fieldName := field.Name
 	baseType := strings.TrimPrefix(field.Type, "*")
 	baseType = strings.TrimPrefix(baseType, "[]")
@@ -1205,7 +1443,7 @@ func (g *CodeGenerator) generateValidationTest(builder *strings.Builder, goType
 	} else {
 		// For struct types, use the existing logic
 		builder.WriteString(fmt.Sprintf("\tvalid := &%s{\n", typeName))
-		for _, field := range goType.Fields {
+		for _, field := range g.classFields(*goType) {
 			if !field.IsOptional {
 				g.generateTestFieldData(builder, &field)
 			}
@@ -1216,7 +1454,7 @@ func (g *CodeGenerator) generateValidationTest(builder *strings.Builder, goType
 		builder.WriteString("\t}\n\n")
 
 		// Test invalid cases for required fields
-		for _, field := range goType.Fields {
+		for _, field := range g.classFields(*goType) {
 			if !field.IsOptional {
 				g.generateInvalidFieldTest(builder, &field, typeName)
 			}
@@ -1254,7 +1492,7 @@ func (g *CodeGenerator) generateBenchmarkTests(builder *strings.Builder) {
 			builder.WriteString(fmt.Sprintf("\tobj := &%s{\n", typeName))
 
 			// Generate sample data
-			for _, field := range goType.Fields {
+			for _, field := range g.classFields(goType) {
 				if !field.IsOptional {
 					g.generateTestFieldData(builder, &field)
 				}
@@ -1302,17 +1540,22 @@ func (g *CodeGenerator) writeJavaClassType(builder *strings.Builder, goType type
 			builder.WriteString(fmt.Sprintf("@XmlType(namespace = \"%s\")\n", goType.Namespace))
 		}
 	}
-	builder.WriteString(fmt.Sprintf("public class %s {\n", goType.Name))
+	if goType.IsAbstract {
+		builder.WriteString(fmt.Sprintf("public abstract class %s {\n", goType.Name))
+	} else {
+		builder.WriteString(fmt.Sprintf("public class %s {\n", goType.Name))
+	}
 
 	// Write fields
-	for _, field := range goType.Fields {
+	fields := g.classFields(goType)
+	for _, field := range fields {
 		g.writeJavaField(builder, field)
 	}
 
 	builder.WriteString("\n")
 
 	// Write getters and setters
-	for _, field := range goType.Fields {
+	for _, field := range fields {
 		g.writeJavaGetterSetter(builder, field)
 	}
 
@@ -1322,21 +1565,40 @@ func (g *CodeGenerator) writeJavaClassType(builder *strings.Builder, goType type
 // writeJavaField writes a Java field
 func (g *CodeGenerator) writeJavaField(builder *strings.Builder, field types.GoField) {
 	// Convert Go type to Java type
-	javaType := g.convertToJavaType(field.Type) // Write field with annotations
-	if field.XMLTag != "" {
+	javaType := g.javaFieldType(field) // Write field with annotations
+	if field.IsWildcard {
+		if field.IsAttribute {
+			builder.WriteString("    @XmlAnyAttribute\n")
+		} else {
+			builder.WriteString("    @XmlAnyElement(lax = true)\n")
+		}
+	} else if field.IsMixed {
+		builder.WriteString("    @XmlMixed\n")
+		builder.WriteString("    @XmlAnyElement(lax = true)\n")
+	} else if field.IsText {
+		builder.WriteString("    @XmlValue\n")
+	} else if field.XMLTag != "" {
 		if strings.Contains(field.XMLTag, ",attr") {
 			builder.WriteString("    @XmlAttribute\n")
+		} else if field.IsNillable {
+			builder.WriteString("    @XmlElement(nillable = true)\n")
 		} else {
 			builder.WriteString("    @XmlElement\n")
 		}
 	}
 
-	builder.WriteString(fmt.Sprintf("    private %s %s;\n", javaType, strings.ToLower(field.Name[:1])+field.Name[1:]))
+	initializer := ""
+	if field.HasDefault {
+		if value, ok := g.javaDefaultValue(field); ok {
+			initializer = " = " + value
+		}
+	}
+	builder.WriteString(fmt.Sprintf("    private %s %s%s;\n", javaType, strings.ToLower(field.Name[:1])+field.Name[1:], initializer))
 }
 
 // writeJavaGetterSetter writes getter and setter methods for a Java field
 func (g *CodeGenerator) writeJavaGetterSetter(builder *strings.Builder, field types.GoField) {
-	javaType := g.convertToJavaType(field.Type)
+	javaType := g.javaFieldType(field)
 	fieldName := strings.ToLower(field.Name[:1]) + field.Name[1:]
 	capitalizedName := field.Name
 
@@ -1351,6 +1613,47 @@ func (g *CodeGenerator) writeJavaGetterSetter(builder *strings.Builder, field ty
 	builder.WriteString("    }\n\n")
 }
 
+// javaFieldType returns the Java type of a field; wildcard fields use the
+// types JAXB expects for @XmlAnyElement and @XmlAnyAttribute
+func (g *CodeGenerator) javaFieldType(field types.GoField) string {
+	if field.IsWildcard {
+		if field.IsAttribute {
+			return "Map<javax.xml.namespace.QName, String>"
+		}
+		return "List<Object>"
+	}
+	if field.IsMixed {
+		return "List<Object>"
+	}
+	return g.convertToJavaType(field.Type)
+}
+
+// writeJavaSubstitutionGroupType writes a holder class for the members of a
+// substitution group
+func (g *CodeGenerator) writeJavaSubstitutionGroupType(builder *strings.Builder, goType types.GoType) {
+	if g.includeComments {
+		g.writeComment(builder, fmt.Sprintf("%s %s", goType.Name, goType.Comment), "")
+	}
+	builder.WriteString(fmt.Sprintf("public class %s {\n", goType.Name))
+	builder.WriteString("    @XmlElements({\n")
+	for i, member := range goType.Members {
+		separator := ","
+		if i == len(goType.Members)-1 {
+			separator = ""
+		}
+		builder.WriteString(fmt.Sprintf("        @XmlElement(name = \"%s\", type = %s.class)%s\n", member.ElementName, member.TypeName, separator))
+	}
+	builder.WriteString("    })\n")
+	builder.WriteString("    private Object value;\n\n")
+	builder.WriteString("    public Object getValue() {\n")
+	builder.WriteString("        return value;\n")
+	builder.WriteString("    }\n\n")
+	builder.WriteString("    public void setValue(Object value) {\n")
+	builder.WriteString("        this.value = value;\n")
+	builder.WriteString("    }\n")
+	builder.WriteString("}\n")
+}
+
 // writeJavaEnumType writes a Java enum type
 func (g *CodeGenerator) writeJavaEnumType(builder *strings.Builder, goType types.GoType) {
 	// Write comment
@@ -1399,10 +1702,14 @@ func (g *CodeGenerator) writeCSharpClassType(builder *strings.Builder, goType ty
 		}
 		builder.WriteString(")]\n")
 	}
-	builder.WriteString(fmt.Sprintf("public class %s\n{\n", goType.Name))
+	if goType.IsAbstract {
+		builder.WriteString(fmt.Sprintf("public abstract class %s\n{\n", goType.Name))
+	} else {
+		builder.WriteString(fmt.Sprintf("public class %s\n{\n", goType.Name))
+	}
 
 	// Write properties
-	for _, field := range goType.Fields {
+	for _, field := range g.classFields(goType) {
 		g.writeCSharpProperty(builder, field)
 	}
 
@@ -1415,9 +1722,25 @@ func (g *CodeGenerator) writeCSharpProperty(builder *strings.Builder, field type
 	csharpType := g.convertToCSharpType(field.Type)
 
 	// Write property with XML attributes
-	if field.XMLTag != "" {
+	if field.IsWildcard {
+		if field.IsAttribute {
+			builder.WriteString("    [XmlAnyAttribute]\n")
+			csharpType = "System.Xml.XmlAttribute[]"
+		} else {
+			builder.WriteString("    [XmlAnyElement]\n")
+			csharpType = "System.Xml.XmlElement[]"
+		}
+	} else if field.IsMixed {
+		builder.WriteString("    [XmlText]\n")
+		builder.WriteString("    [XmlAnyElement]\n")
+		csharpType = "System.Xml.XmlNode[]"
+	} else if field.IsText {
+		builder.WriteString("    [XmlText]\n")
+	} else if field.XMLTag != "" {
 		if strings.Contains(field.XMLTag, ",attr") {
 			builder.WriteString("    [XmlAttribute]\n")
+		} else if field.IsNillable {
+			builder.WriteString("    [XmlElement(IsNullable = true)]\n")
 		} else {
 			builder.WriteString("    [XmlElement]\n")
 		}
@@ -1427,7 +1750,27 @@ func (g *CodeGenerator) writeCSharpProperty(builder *strings.Builder, field type
 		builder.WriteString(fmt.Sprintf("    [JsonPropertyName(\"%s\")]\n", field.JSONTag))
 	}
 
-	builder.WriteString(fmt.Sprintf("    public %s %s { get; set; }\n\n", csharpType, field.Name))
+	initializer := ""
+	if field.HasDefault {
+		if value, ok := g.csharpDefaultValue(field); ok {
+			initializer = fmt.Sprintf(" = %s;", value)
+		}
+	}
+	builder.WriteString(fmt.Sprintf("    public %s %s { get; set; }%s\n\n", csharpType, field.Name, initializer))
+}
+
+// writeCSharpSubstitutionGroupType writes a holder class for the members of
+// a substitution group
+func (g *CodeGenerator) writeCSharpSubstitutionGroupType(builder *strings.Builder, goType types.GoType) {
+	if g.includeComments {
+		g.writeComment(builder, fmt.Sprintf("%s %s", goType.Name, goType.Comment), "")
+	}
+	builder.WriteString(fmt.Sprintf("public class %s\n{\n", goType.Name))
+	for _, member := range goType.Members {
+		builder.WriteString(fmt.Sprintf("    [XmlElement(\"%s\", typeof(%s))]\n", member.ElementName, member.TypeName))
+	}
+	builder.WriteString("    public object Value { get; set; }\n")
+	builder.WriteString("}\n")
 }
 
 // writeCSharpEnumType writes a C# enum type
@@ -1466,10 +1809,11 @@ func (g *CodeGenerator) writePythonClassType(builder *strings.Builder, goType ty
 	builder.WriteString(fmt.Sprintf("class %s:\n", goType.Name))
 
 	// Write fields
-	if len(goType.Fields) == 0 {
+	fields := g.classFields(goType)
+	if len(fields) == 0 {
 		builder.WriteString("    pass\n")
 	} else {
-		for _, field := range goType.Fields {
+		for _, field := range fields {
 			g.writePythonField(builder, field)
 		}
 	}
@@ -1482,6 +1826,22 @@ func (g *CodeGenerator) writePythonField(builder *strings.Builder, field types.G
 		g.writePythonComment(builder, field.Comment, "    ")
 	}
 
+	// Mixed content is kept as a list of strings and parsed elements
+	if field.IsMixed {
+		builder.WriteString(fmt.Sprintf("    %s: list = field(default_factory=list)\n", field.Name))
+		return
+	}
+
+	// Wildcard content is kept as parsed elements and an attribute map
+	if field.IsWildcard {
+		if field.IsAttribute {
+			builder.WriteString(fmt.Sprintf("    %s: dict = field(default_factory=dict)\n", field.Name))
+		} else {
+			builder.WriteString(fmt.Sprintf("    %s: List[ET.Element] = field(default_factory=list)\n", field.Name))
+		}
+		return
+	}
+
 	// Convert Go type to Python type
 	pythonType := g.convertToPythonType(field.Type)
 
@@ -1499,6 +1859,23 @@ func (g *CodeGenerator) writePythonField(builder *strings.Builder, field types.G
 	}
 }
 
+// writePythonSubstitutionGroupType writes a holder class for the members of
+// a substitution group
+func (g *CodeGenerator) writePythonSubstitutionGroupType(builder *strings.Builder, goType types.GoType) {
+	if g.includeComments {
+		g.writePythonComment(builder, fmt.Sprintf("%s %s", goType.Name, goType.Comment), "")
+	}
+	builder.WriteString("@dataclass\n")
+	builder.WriteString(fmt.Sprintf("class %s:\n", goType.Name))
+	members := make([]string, 0, len(goType.Members))
+	for _, member := range goType.Members {
+		members = append(members, fmt.Sprintf("\"%s\": %s", member.ElementName, member.TypeName))
+	}
+	builder.WriteString(fmt.Sprintf("    MEMBERS = {%s}\n", strings.Join(members, ", ")))
+	builder.WriteString("    element_name: str = \"\"\n")
+	builder.WriteString("    value: Any = None\n")
+}
+
 // writePythonEnumType writes a Python enum type
 func (g *CodeGenerator) writePythonEnumType(builder *strings.Builder, goType types.GoType) {
 	// Write comment
@@ -1547,6 +1924,21 @@ func (g *CodeGenerator) convertToPythonType(goType string) string {
 		return fmt.Sprintf("List[%s]", pythonElementType)
 	}
 
+	// Nillable values are None when the element is xsi:nil
+	if valueType, ok := unwrapNillable(goType); ok {
+		return fmt.Sprintf("Optional[%s]", g.convertToPythonType(valueType))
+	}
+
+	// Arbitrary-precision numbers use the big number types of the language
+	if converted, ok := g.bigNumberConversion(goType); ok {
+		return converted
+	}
+
+	// Dates, times and durations of the xsd package
+	if converted, ok := g.temporalConversion(goType); ok {
+		return converted
+	}
+
 	// Check for mapped types first
 	if mapped, exists := g.GetTypeMapping(goType); exists {
 		return mapped
@@ -1594,6 +1986,21 @@ func (g *CodeGenerator) convertToJavaType(goType string) string {
 		return fmt.Sprintf("List<%s>", javaElementType)
 	}
 
+	// Nillable values are null when the element is xsi:nil
+	if valueType, ok := unwrapNillable(goType); ok {
+		return g.convertToJavaType(valueType)
+	}
+
+	// Arbitrary-precision numbers use the big number types of the language
+	if converted, ok := g.bigNumberConversion(goType); ok {
+		return converted
+	}
+
+	// Dates, times and durations of the xsd package
+	if converted, ok := g.temporalConversion(goType); ok {
+		return converted
+	}
+
 	// Check for mapped types first
 	if mapped, exists := g.GetTypeMapping(goType); exists {
 		return mapped
@@ -1641,6 +2048,21 @@ func (g *CodeGenerator) convertToCSharpType(goType string) string {
 		return fmt.Sprintf("List<%s>", csharpElementType)
 	}
 
+	// Nillable values are null when the element is xsi:nil
+	if valueType, ok := unwrapNillable(goType); ok {
+		return nullableCSharpType(g.convertToCSharpType(valueType))
+	}
+
+	// Arbitrary-precision numbers use the big number types of the language
+	if converted, ok := g.bigNumberConversion(goType); ok {
+		return converted
+	}
+
+	// Dates, times and durations of the xsd package
+	if converted, ok := g.temporalConversion(goType); ok {
+		return converted
+	}
+
 	// Check for mapped types first
 	if mapped, exists := g.GetTypeMapping(goType); exists {
 		return mapped
@@ -1708,6 +2130,44 @@ func (g *CodeGenerator) writeGoRestrictedType(builder *strings.Builder, goType t
 
 	// Add validation function
 	g.writeGoTypeValidation(builder, goType)
+
+	// Types defined by an arbitrary-precision number or a date, time or
+	// duration keep its text form
+	if bigType, ok := g.bigNumberBase(goType); ok {
+		g.writeGoTextMethods(builder, goType, bigType)
+	} else if temporalType, ok := g.temporalBase(goType); ok {
+		g.writeGoTextMethods(builder, goType, temporalType)
+	}
+}
+
+// writeGoTextMethods writes the text methods of a restricted type defined
+// by a type with text methods, which do not carry over to the new type
+func (g *CodeGenerator) writeGoTextMethods(builder *strings.Builder, goType types.GoType, valueType string) {
+	builder.WriteString("\n")
+	if g.includeComments {
+		g.writeComment(builder, "MarshalText implements encoding.TextMarshaler", "")
+	}
+	builder.WriteString(fmt.Sprintf("func (v %s) MarshalText() ([]byte, error) {\n", goType.Name))
+	builder.WriteString(fmt.Sprintf("\treturn %s(v).MarshalText()\n", valueType))
+	builder.WriteString("}\n\n")
+	if g.includeComments {
+		g.writeComment(builder, "UnmarshalText implements encoding.TextUnmarshaler", "")
+	}
+	builder.WriteString(fmt.Sprintf("func (v *%s) UnmarshalText(text []byte) error {\n", goType.Name))
+	builder.WriteString(fmt.Sprintf("\treturn (*%s)(v).UnmarshalText(text)\n", valueType))
+	builder.WriteString("}\n")
+}
+
+// goLexicalValue returns the Go expression for the text of a restricted
+// value, which patterns and fixed values are checked against
+func (g *CodeGenerator) goLexicalValue(goType types.GoType) string {
+	if bigType, ok := g.bigNumberBase(goType); ok {
+		return bigType + "(v).String()"
+	}
+	if temporalType, ok := g.temporalBase(goType); ok {
+		return temporalType + "(v).String()"
+	}
+	return "string(v)"
 }
 
 // writeGoTypeValidation writes Go validation function for a type
@@ -1724,21 +2184,25 @@ func (g *CodeGenerator) writeGoTypeValidation(builder *strings.Builder, goType t
 		builder.WriteString(fmt.Sprintf("func (v %s) Validate() bool {\n", goType.Name))
 	}
 
-	// Add validation logic based on restriction type
+	// Add validation logic for each restriction; a derived type carries the
+	// facets of its base types, so every kind present is checked in turn
 	if goType.HasPattern {
-		// For pattern validation, use regexp
-		builder.WriteString(fmt.Sprintf("\t// Validate against pattern: %s\n", goType.PatternValue))
-		builder.WriteString("\tpattern := regexp.MustCompile(`" + goType.PatternValue + "`)\n")
-		builder.WriteString("\treturn pattern.MatchString(string(v))\n")
-	} else if goType.HasLength {
-		// For exact length validation
-		builder.WriteString("\tstrVal := string(v)\n")
-		// Apply whiteSpace processing if needed
-		if goType.HasWhiteSpace {
-			builder.WriteString(fmt.Sprintf("\tstrVal = applyWhiteSpaceProcessing(strVal, \"%s\")\n", goType.WhiteSpace))
+		// For pattern validation, use regexp; the value must match the
+		// patterns of every derivation step
+		for _, pattern := range goType.Patterns {
+			builder.WriteString(fmt.Sprintf("\t// Validate against pattern: %s\n", pattern))
 		}
-		builder.WriteString(fmt.Sprintf("\treturn len(strVal) == %s\n", goType.Length))
-	} else if goType.HasMinLength || goType.HasMaxLength {
+		translated, errs := g.translatePatterns(goType.Patterns)
+		for _, err := range errs {
+			builder.WriteString(fmt.Sprintf("\t// Not checked: %v\n", err))
+		}
+		for _, pattern := range translated {
+			builder.WriteString("\tif !regexp.MustCompile(`" + pattern + "`).MatchString(" + g.goLexicalValue(goType) + ") {\n")
+			builder.WriteString("\t\treturn false\n")
+			builder.WriteString("\t}\n")
+		}
+	}
+	if goType.HasLength || goType.HasMinLength || goType.HasMaxLength {
 		// For length validation
 		builder.WriteString("\tstrVal := string(v)\n")
 		// Apply whiteSpace processing if needed
@@ -1746,36 +2210,46 @@ func (g *CodeGenerator) writeGoTypeValidation(builder *strings.Builder, goType t
 			builder.WriteString(fmt.Sprintf("\tstrVal = applyWhiteSpaceProcessing(strVal, \"%s\")\n", goType.WhiteSpace))
 		}
 		builder.WriteString("\tlength := len(strVal)\n")
+		if goType.HasLength {
+			builder.WriteString(fmt.Sprintf("\tif length != %s {\n\t\treturn false\n\t}\n", goType.Length))
+		}
 		if goType.HasMinLength {
 			builder.WriteString(fmt.Sprintf("\tif length < %s {\n\t\treturn false\n\t}\n", goType.MinLength))
 		}
 		if goType.HasMaxLength {
 			builder.WriteString(fmt.Sprintf("\tif length > %s {\n\t\treturn false\n\t}\n", goType.MaxLength))
 		}
-		builder.WriteString("\treturn true\n")
-	} else if goType.HasFixedValue {
+	}
+	if goType.HasFixedValue {
 		// For fixed value validation
-		builder.WriteString(fmt.Sprintf("\treturn string(v) == \"%s\"\n", goType.FixedValue))
-	} else if goType.HasMinInclusive || goType.HasMaxInclusive || goType.HasMinExclusive || goType.HasMaxExclusive {
-		// For numeric range validation
-		switch goType.BaseType {
+		builder.WriteString(fmt.Sprintf("\tif %s != \"%s\" {\n\t\treturn false\n\t}\n", g.goLexicalValue(goType), goType.FixedValue))
+	}
+	if goType.HasMinInclusive || goType.HasMaxInclusive || goType.HasMinExclusive || goType.HasMaxExclusive {
+		// For numeric range validation; the base may be a restricted type
+		switch underlying := g.goUnderlyingType(goType.BaseType); underlying {
 		case "int", "int8", "int16", "int32", "int64":
 			g.writeIntRangeValidation(builder, goType)
+		case DecimalTypeName, IntegerTypeName:
+			g.writeGoBigNumberRangeValidation(builder, goType, underlying)
 		case "float32", "float64":
 			g.writeFloatRangeValidation(builder, goType)
 		default:
-			builder.WriteString("\t// Validation not supported for this type\n")
-			builder.WriteString("\treturn true\n")
+			if isTemporalType(underlying) {
+				g.writeGoTemporalRangeValidation(builder, goType, underlying)
+			} else {
+				builder.WriteString("\t// Range validation not supported for this type\n")
+			}
 		}
+	}
+	if bigType, ok := g.bigNumberBase(goType); ok && (goType.HasTotalDigits || goType.HasFractionDigits) {
+		// Arbitrary-precision numbers count their digits exactly
+		g.writeGoBigNumberDigitsValidation(builder, goType, bigType)
 	} else if goType.HasTotalDigits || goType.HasFractionDigits {
 		// For digit validation
 		builder.WriteString("\t// Validate number of digits\n")
 		builder.WriteString("\t// Note: This is a simplified validation\n")
-		builder.WriteString("\t return true\n")
-	} else {
-		// Default case: no validation
-		builder.WriteString("\treturn true\n")
 	}
+	builder.WriteString("\treturn true\n")
 
 	// Close function
 	builder.WriteString("}\n")
@@ -1795,8 +2269,6 @@ func (g *CodeGenerator) writeIntRangeValidation(builder *strings.Builder, goType
 	} else if goType.HasMaxExclusive {
 		builder.WriteString(fmt.Sprintf("\tif val >= %s {\n\t\treturn false\n\t}\n", goType.MaxExclusive))
 	}
-
-	builder.WriteString("\treturn true\n")
 }
 
 // writeFloatRangeValidation writes validation for floating point range constraints
@@ -1813,8 +2285,6 @@ func (g *CodeGenerator) writeFloatRangeValidation(builder *strings.Builder, goTy
 	} else if goType.HasMaxExclusive {
 		builder.WriteString(fmt.Sprintf("\tif val >= %s {\n\t\treturn false\n\t}\n", goType.MaxExclusive))
 	}
-
-	builder.WriteString("\treturn true\n")
 }
 
 // writeJavaRestrictedType writes a Java class with restriction validation
@@ -1833,7 +2303,11 @@ func (g *CodeGenerator) writeJavaRestrictedType(builder *strings.Builder, goType
 			builder.WriteString(fmt.Sprintf("@XmlType(namespace = \"%s\")\n", goType.Namespace))
 		}
 	}
-	builder.WriteString(fmt.Sprintf("public class %s {\n", goType.Name))
+	if goType.IsAbstract {
+		builder.WriteString(fmt.Sprintf("public abstract class %s {\n", goType.Name))
+	} else {
+		builder.WriteString(fmt.Sprintf("public class %s {\n", goType.Name))
+	}
 
 	// Write fields
 	for _, field := range goType.Fields {
@@ -1878,8 +2352,14 @@ func (g *CodeGenerator) writeCSharpRestrictedType(builder *strings.Builder, goTy
 	builder.WriteString("    {\n")
 
 	if goType.HasPattern {
-		builder.WriteString(fmt.Sprintf("        if (!System.Text.RegularExpressions.Regex.IsMatch(value, @\"%s\"))\n", goType.PatternValue))
-		builder.WriteString("            throw new ArgumentException(\"Invalid format\");\n")
+		translated, errs := g.translatePatterns(goType.Patterns)
+		for _, err := range errs {
+			builder.WriteString(fmt.Sprintf("        // Pattern not checked: %v\n", err))
+		}
+		if len(translated) > 0 {
+			builder.WriteString(fmt.Sprintf("        if (!System.Text.RegularExpressions.Regex.IsMatch(value, @\"%s\"))\n", matchAll(translated)))
+			builder.WriteString("            throw new ArgumentException(\"Invalid format\");\n")
+		}
 	}
 
 	if goType.HasMinLength || goType.HasMaxLength {
@@ -1924,7 +2404,10 @@ func (g *CodeGenerator) writePythonRestrictedType(builder *strings.Builder, goTy
 		builder.WriteString("class " + goType.Name + "(str):\n")
 		builder.WriteString("    \"\"\"\n    Represents a string with ")
 		if goType.HasPattern {
-			builder.WriteString("pattern validation.\n    Pattern: " + goType.PatternValue)
+			builder.WriteString("pattern validation.")
+			for _, pattern := range goType.Patterns {
+				builder.WriteString("\n    Pattern: " + pattern)
+			}
 		} else if goType.HasMinLength || goType.HasMaxLength {
 			builder.WriteString("length validation.")
 		} else if goType.HasMinInclusive || goType.HasMaxInclusive || goType.HasMinExclusive || goType.HasMaxExclusive {
@@ -1936,11 +2419,17 @@ func (g *CodeGenerator) writePythonRestrictedType(builder *strings.Builder, goTy
 	}
 	builder.WriteString("    \"\"\"\n")
 
-	// Add static pattern for regex
+	// Add static pattern for regex; untranslatable patterns accept everything
 	if goType.HasPattern {
-		// Escape backslashes for Python strings
-		pyPattern := strings.Replace(goType.PatternValue, "\\", "\\\\", -1)
-		builder.WriteString("    _pattern = re.compile(r'" + pyPattern + "')\n")
+		translated, errs := g.translatePatterns(goType.Patterns)
+		for _, err := range errs {
+			builder.WriteString(fmt.Sprintf("    # Pattern not checked: %v\n", err))
+		}
+		if len(translated) == 0 {
+			builder.WriteString("    _pattern = re.compile(r'(?s:.*)')\n")
+		} else {
+			builder.WriteString("    _pattern = re.compile(r'" + matchAll(translated) + "')\n")
+		}
 	}
 
 	// Add __new__ method with validation
@@ -1988,7 +2477,15 @@ func (g *CodeGenerator) writeJavaTypeValidation(builder *strings.Builder, goType
 	builder.WriteString("    public boolean validate() {\n")
 
 	if goType.HasPattern {
-		builder.WriteString(fmt.Sprintf("        return value.matches(\"%s\");\n", goType.PatternValue))
+		translated, errs := g.translatePatterns(goType.Patterns)
+		for _, err := range errs {
+			builder.WriteString(fmt.Sprintf("        // Pattern not checked: %v\n", err))
+		}
+		if len(translated) == 0 {
+			builder.WriteString("        return true;\n")
+		} else {
+			builder.WriteString(fmt.Sprintf("        return value.matches(%q);\n", matchAll(translated)))
+		}
 	} else if goType.HasMinLength || goType.HasMaxLength {
 		if goType.HasMinLength && goType.HasMaxLength {
 			builder.WriteString("        int length = value.length();\n")
@@ -2009,6 +2506,100 @@ func (g *CodeGenerator) writeJavaTypeValidation(builder *strings.Builder, goType
 	builder.WriteString("    }\n")
 }
 
+// needsWildcardTypes checks if any struct has element or attribute wildcard fields
+func (g *CodeGenerator) needsWildcardTypes() (elements, attributes bool) {
+	for _, goType := range g.goTypes {
+		fields := append(g.classFields(goType), g.mixedChildFields(goType)...)
+		for _, field := range fields {
+			if field.IsWildcard && field.IsAttribute {
+				attributes = true
+			} else if field.IsWildcard {
+				elements = true
+			}
+		}
+	}
+	return elements, attributes
+}
+
+// writeGoAnyElementType writes the holder for elements matched by xs:any. The
+// content is kept as tokens with resolved namespaces so it can be written back
+// without the prefix declarations of the original document.
+func (g *CodeGenerator) writeGoAnyElementType(builder *strings.Builder) {
+	builder.WriteString("// AnyElement holds an element matched by a schema wildcard\n")
+	builder.WriteString("type AnyElement struct {\n")
+	builder.WriteString("\tXMLName xml.Name\n")
+	builder.WriteString("\tAttrs   []xml.Attr\n")
+	builder.WriteString("\tTokens  []xml.Token\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// UnmarshalXML records the element and everything inside it\n")
+	builder.WriteString("func (a *AnyElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n")
+	builder.WriteString("\ta.XMLName = start.Name\n")
+	builder.WriteString("\ta.Attrs = a.Attrs[:0]\n")
+	builder.WriteString("\tfor _, attr := range start.Attr {\n")
+	builder.WriteString("\t\tif !isNamespaceDecl(attr) {\n")
+	builder.WriteString("\t\t\ta.Attrs = append(a.Attrs, attr)\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\ta.Tokens = a.Tokens[:0]\n")
+	builder.WriteString("\tfor depth := 0; ; {\n")
+	builder.WriteString("\t\ttoken, err := d.Token()\n")
+	builder.WriteString("\t\tif err != nil {\n")
+	builder.WriteString("\t\t\treturn err\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t\tswitch t := token.(type) {\n")
+	builder.WriteString("\t\tcase xml.StartElement:\n")
+	builder.WriteString("\t\t\tdepth++\n")
+	builder.WriteString("\t\t\tattrs := make([]xml.Attr, 0, len(t.Attr))\n")
+	builder.WriteString("\t\t\tfor _, attr := range t.Attr {\n")
+	builder.WriteString("\t\t\t\tif !isNamespaceDecl(attr) {\n")
+	builder.WriteString("\t\t\t\t\tattrs = append(attrs, attr)\n")
+	builder.WriteString("\t\t\t\t}\n")
+	builder.WriteString("\t\t\t}\n")
+	builder.WriteString("\t\t\tt.Attr = attrs\n")
+	builder.WriteString("\t\t\ttoken = t\n")
+	builder.WriteString("\t\tcase xml.EndElement:\n")
+	builder.WriteString("\t\t\tif depth == 0 {\n")
+	builder.WriteString("\t\t\t\treturn nil\n")
+	builder.WriteString("\t\t\t}\n")
+	builder.WriteString("\t\t\tdepth--\n")
+	builder.WriteString("\t\tcase xml.ProcInst:\n")
+	builder.WriteString("\t\t\tcontinue\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t\ta.Tokens = append(a.Tokens, xml.CopyToken(token))\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// MarshalXML writes the recorded element back out\n")
+	builder.WriteString("func (a AnyElement) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {\n")
+	builder.WriteString("\tstart := xml.StartElement{Name: a.XMLName, Attr: a.Attrs}\n")
+	builder.WriteString("\tif err := e.EncodeToken(start); err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tfor _, token := range a.Tokens {\n")
+	builder.WriteString("\t\tif err := e.EncodeToken(token); err != nil {\n")
+	builder.WriteString("\t\t\treturn err\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn e.EncodeToken(start.End())\n")
+	builder.WriteString("}\n\n")
+}
+
+// writeGoAnyAttributesType writes the holder for attributes matched by
+// xs:anyAttribute. Namespace declarations are dropped on the way in because
+// the encoder writes its own.
+func (g *CodeGenerator) writeGoAnyAttributesType(builder *strings.Builder) {
+	builder.WriteString("// AnyAttributes holds attributes matched by a schema wildcard\n")
+	builder.WriteString("type AnyAttributes []xml.Attr\n\n")
+	builder.WriteString("// UnmarshalXMLAttr records an attribute unless it declares a namespace\n")
+	builder.WriteString("func (a *AnyAttributes) UnmarshalXMLAttr(attr xml.Attr) error {\n")
+	builder.WriteString("\tif !isNamespaceDecl(attr) {\n")
+	builder.WriteString("\t\t*a = append(*a, attr)\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn nil\n")
+	builder.WriteString("}\n\n")
+}
+
 // writeGoHelperFunctions writes helper functions needed by the generated Go code
 func (g *CodeGenerator) writeGoHelperFunctions(builder *strings.Builder) {
 	// Check if we need any helper functions
@@ -2024,6 +2615,42 @@ func (g *CodeGenerator) writeGoHelperFunctions(builder *strings.Builder) {
 		}
 	}
 
+	// Write the holders for wildcard content
+	needsAnyElement, needsAnyAttributes := g.needsWildcardTypes()
+	needsCatchAllSplit := g.needsCatchAllSplit()
+	if needsAnyElement || needsCatchAllSplit {
+		g.writeGoAnyElementType(builder)
+	}
+	if needsCatchAllSplit {
+		g.writeGoAnyElementDecode(builder)
+	}
+	if needsAnyAttributes {
+		g.writeGoAnyAttributesType(builder)
+	}
+	needsMixedItems := g.needsMixedItems()
+	if needsMixedItems {
+		g.writeGoMixedHelpers(builder)
+	}
+	if g.needsNillable() {
+		g.writeGoNillableType(builder)
+	}
+	if g.needsNumberLexical() {
+		g.writeGoNumberLexical(builder)
+	}
+	needsDecimal, needsInteger := g.needsBigNumbers()
+	if needsDecimal {
+		g.writeGoDecimalType(builder)
+	}
+	if needsInteger {
+		g.writeGoIntegerType(builder)
+	}
+	if needsAnyElement || needsAnyAttributes || needsMixedItems || needsCatchAllSplit {
+		builder.WriteString("// isNamespaceDecl reports whether an attribute is a namespace declaration\n")
+		builder.WriteString("func isNamespaceDecl(attr xml.Attr) bool {\n")
+		builder.WriteString("\treturn attr.Name.Space == \"xmlns\" || (attr.Name.Space == \"\" && attr.Name.Local == \"xmlns\")\n")
+		builder.WriteString("}\n\n")
+	}
+
 	// Write whiteSpace processing helper if needed
 	if needsWhiteSpaceHelper {
 		builder.WriteString("// applyWhiteSpaceProcessing applies XSD whiteSpace facet processing\n")
@@ -13,13 +13,20 @@ type GeneratorConfig struct {
 	DebugMode       bool
 
 	// Type mapping options
-	EnableCustomTypes bool          // Enable PLC/custom type mappings
-	CustomMappings    []TypeMapping // User-defined custom mappings
+	EnableCustomTypes  bool          // Enable PLC/custom type mappings
+	CustomMappings     []TypeMapping // User-defined custom mappings
+	ArbitraryPrecision bool          // xs:decimal and xs:integer as Decimal and Integer
 
 	// Code generation options
-	EnableValidation bool // Generate validation code
-	EnableTestCode   bool // Generate test code
-	StrictMode       bool // Strict XSD compliance
+	EnableValidation bool             // Generate validation code
+	EnableTestCode   bool             // Generate test code
+	StrictMode       bool             // Strict XSD compliance
+	MixedContent     MixedContentMode // Go representation of mixed content
+	Layout           OutputLayout     // distribution of the types over files
+
+	// Per-namespace output (Go only): one package per target namespace
+	NamespacePackages map[string]string // namespace -> Go package name
+	ImportBase        string            // import path of the output directory
 
 	// Language-specific options
 	LanguageSpecific map[string]interface{} // Language-specific configurations
@@ -37,6 +44,8 @@ func NewGeneratorConfig() *GeneratorConfig {
 		EnableValidation:  false,
 		EnableTestCode:    false,
 		StrictMode:        false,
+		MixedContent:      MixedContentOrdered,
+		Layout:            LayoutSingleFile,
 		CustomMappings:    make([]TypeMapping, 0),
 		LanguageSpecific:  make(map[string]interface{}),
 	}
@@ -78,6 +87,33 @@ func (c *GeneratorConfig) EnablePLCTypes() *GeneratorConfig {
 	return c
 }
 
+// EnableArbitraryPrecision maps xs:decimal and xs:integer to arbitrary-precision
+// types and returns the config for chaining
+func (c *GeneratorConfig) EnableArbitraryPrecision() *GeneratorConfig {
+	c.ArbitraryPrecision = true
+	return c
+}
+
+// SetMixedContentMode sets how mixed content is generated and returns the config for chaining
+func (c *GeneratorConfig) SetMixedContentMode(mode MixedContentMode) *GeneratorConfig {
+	c.MixedContent = mode
+	return c
+}
+
+// SetOutputLayout sets how the types are distributed over files and returns the config for chaining
+func (c *GeneratorConfig) SetOutputLayout(layout OutputLayout) *GeneratorConfig {
+	c.Layout = layout
+	return c
+}
+
+// SetNamespacePackages writes one Go package per namespace, named by
+// packages and imported below importBase, and returns the config for chaining
+func (c *GeneratorConfig) SetNamespacePackages(packages map[string]string, importBase string) *GeneratorConfig {
+	c.NamespacePackages = packages
+	c.ImportBase = importBase
+	return c
+}
+
 // AddCustomMapping adds a custom type mapping and returns the config for chaining
 func (c *GeneratorConfig) AddCustomMapping(xsdType, targetType string) *GeneratorConfig {
 	c.CustomMappings = append(c.CustomMappings, TypeMapping{
@@ -95,6 +131,15 @@ func (c *GeneratorConfig) Validate() error {
 	if c.OutputPath == "" {
 		return fmt.Errorf("output path cannot be empty")
 	}
+	if c.MixedContent != "" && c.MixedContent != MixedContentOrdered && c.MixedContent != MixedContentInnerXML {
+		return fmt.Errorf("unsupported mixed content mode: %s", c.MixedContent)
+	}
+	if c.Layout != "" && c.Layout != LayoutSingleFile && c.Layout != LayoutFilePerType && c.Layout != LayoutGroupByFeature {
+		return fmt.Errorf("unsupported output layout: %s", c.Layout)
+	}
+	if len(c.NamespacePackages) > 0 && c.ImportBase == "" {
+		return fmt.Errorf("import base is required for per-namespace packages")
+	}
 	// Add more validation as needed
 	return nil
 }
@@ -128,6 +173,9 @@ func (c *GeneratorConfig) CreateCodeGenerator() *CodeGenerator {
 	generator.SetIncludeComments(c.IncludeComments)
 	generator.SetDebugMode(c.DebugMode)
 	generator.SetEnableCustomTypes(c.EnableCustomTypes)
+	generator.SetMixedContentMode(c.MixedContent)
+	generator.SetOutputLayout(c.Layout)
+	generator.SetArbitraryPrecision(c.ArbitraryPrecision)
 
 	// Add custom mappings if any
 	if len(c.CustomMappings) > 0 {
@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+	"github.com/suifei/xsd2code/pkg/xsd"
+)
+
+// xsdPackagePath is the import path of the package holding the date, time
+// and duration types generated Go code uses
+const xsdPackagePath = "github.com/suifei/xsd2code/pkg/xsd"
+
+// temporalConversions maps the date, time and duration types of the xsd
+// package to the types of the other target languages. xs:dateTime uses the
+// date-time type of the language; the other types are held as strings.
+var temporalConversions = map[TargetLanguage][2]string{
+	LanguageJava:   {"LocalDateTime", "String"},
+	LanguageCSharp: {"DateTime", "string"},
+	LanguagePython: {"datetime", "str"},
+}
+
+// temporalXSDType returns the built-in XSD type held by a Go type of the
+// xsd package, or "" for other Go types
+func temporalXSDType(goType string) string {
+	for _, mapping := range typeMappingRegistry.BuiltinMappings {
+		if mapping.GoType == goType && xsd.IsType(mapping.XSDType) {
+			return mapping.XSDType
+		}
+	}
+	return ""
+}
+
+// temporalGoType returns the Go type of the xsd package that holds a
+// built-in XSD date, time or duration type, or ""
+func temporalGoType(xsdType string) string {
+	if !xsd.IsType(xsdType) {
+		return ""
+	}
+	for _, mapping := range typeMappingRegistry.BuiltinMappings {
+		if mapping.XSDType == xsdType {
+			return mapping.GoType
+		}
+	}
+	return ""
+}
+
+// isTemporalType reports whether a Go type is one of the date, time and
+// duration types of the xsd package
+func isTemporalType(goType string) bool {
+	return temporalXSDType(goType) != ""
+}
+
+// temporalHeldAsString reports whether the other target languages hold
+// values of an xsd package type as strings, which is all but xs:dateTime
+func temporalHeldAsString(goType string) bool {
+	xsdType := temporalXSDType(goType)
+	return xsdType != "" && xsdType != "dateTime"
+}
+
+// temporalZeroIsAbsent reports whether the zero value of an xsd package
+// type is not a valid value, so that a zero field was absent. Midnight and
+// the empty duration are valid.
+func temporalZeroIsAbsent(goType string) bool {
+	xsdType := temporalXSDType(goType)
+	return xsdType != "" && xsdType != "time" && xsdType != "duration"
+}
+
+// temporalParser returns the function of the xsd package parsing a type
+func temporalParser(goType string) string {
+	return "xsd.Parse" + strings.TrimPrefix(goType, "xsd.")
+}
+
+// temporalConversion returns the target language type for a date, time or
+// duration type of the xsd package
+func (g *CodeGenerator) temporalConversion(goType string) (string, bool) {
+	xsdType := temporalXSDType(goType)
+	if xsdType == "" {
+		return "", false
+	}
+	conversions, exists := temporalConversions[g.languageMapper.GetLanguage()]
+	if !exists {
+		return "", false
+	}
+	if temporalHeldAsString(goType) {
+		return conversions[1], true
+	}
+	return conversions[0], true
+}
+
+// temporalBase returns the xsd package type a restricted type is
+// ultimately defined by, if any
+func (g *CodeGenerator) temporalBase(goType types.GoType) (string, bool) {
+	underlying := g.goUnderlyingType(goType.BaseType)
+	return underlying, isTemporalType(underlying)
+}
+
+// needsXSDPackage reports whether the generated code uses the date, time
+// and duration types of the xsd package
+func (g *CodeGenerator) needsXSDPackage() bool {
+	uses := func(goType string) bool {
+		goType = strings.TrimPrefix(strings.TrimPrefix(goType, "[]"), "*")
+		goType, _ = unwrapNillable(goType)
+		return isTemporalType(goType)
+	}
+	for _, goType := range g.goTypes {
+		if uses(goType.BaseType) || uses(goType.ListItemType) {
+			return true
+		}
+		for _, member := range goType.UnionMembers {
+			if temporalGoType(member.XSDType) != "" {
+				return true
+			}
+		}
+		fields := append(g.classFields(goType), g.mixedChildFields(goType)...)
+		for _, field := range fields {
+			if uses(field.Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// temporalDefault returns the Go expression for a default value of a date,
+// time or duration type, which must be valid for the type
+func temporalDefault(goType, value string) (string, bool) {
+	if _, err := xsd.Parse(temporalXSDType(goType), value); err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("xsd.Must(%s(%q))", temporalParser(goType), strings.TrimSpace(value)), true
+}
+
+// writeGoTemporalRangeValidation writes the bound checks of a restricted
+// type whose values are dates, times or durations. Values the partial
+// order of the type cannot compare with a bound fail the check.
+func (g *CodeGenerator) writeGoTemporalRangeValidation(builder *strings.Builder, goType types.GoType, temporalType string) {
+	builder.WriteString(fmt.Sprintf("\tval := %s(v)\n", temporalType))
+	bounds := []struct {
+		has        bool
+		value, cmp string
+	}{
+		{goType.HasMinInclusive, goType.MinInclusive, "< 0"},
+		{goType.HasMinExclusive, goType.MinExclusive, "<= 0"},
+		{goType.HasMaxInclusive, goType.MaxInclusive, "> 0"},
+		{goType.HasMaxExclusive, goType.MaxExclusive, ">= 0"},
+	}
+	for _, bound := range bounds {
+		if !bound.has {
+			continue
+		}
+		if _, err := xsd.Parse(temporalXSDType(temporalType), bound.value); err != nil {
+			continue // an invalid bound cannot be checked
+		}
+		builder.WriteString(fmt.Sprintf("\tif c := val.Compare(xsd.Must(%s(%q))); c == xsd.Indeterminate || c %s {\n\t\treturn false\n\t}\n",
+			temporalParser(temporalType), strings.TrimSpace(bound.value), bound.cmp))
+	}
+}
@@ -0,0 +1,251 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// defaultFields returns the fields of a type that declare a schema default
+func (g *CodeGenerator) defaultFields(goType types.GoType) []types.GoField {
+	fields := make([]types.GoField, 0)
+	for _, field := range g.classFields(goType) {
+		if field.HasDefault && !field.IsArray && !field.IsWildcard && !field.IsMixed {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// hasGoDefaults reports whether the Go output assigns any default of a type
+func (g *CodeGenerator) hasGoDefaults(goType types.GoType) bool {
+	for _, field := range g.defaultFields(goType) {
+		if _, ok := g.goDefaultAssignment(field); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// goDefaultAssignment returns the Go default value of a field when the field
+// can tell an absent value apart: pointers are nil, strings are empty and
+// dates are zero
+func (g *CodeGenerator) goDefaultAssignment(field types.GoField) (string, bool) {
+	valueType := strings.TrimPrefix(field.Type, "*")
+	if underlying := g.goUnderlyingType(valueType); valueType == field.Type && underlying != "string" && !temporalZeroIsAbsent(underlying) {
+		return "", false
+	}
+	return g.goDefaultValue(valueType, field.DefaultValue)
+}
+
+// writeGoDefaultMethods writes SetDefaults and, unless the type decodes its
+// own content, an UnmarshalXML that applies the defaults after decoding
+func (g *CodeGenerator) writeGoDefaultMethods(builder *strings.Builder, goType types.GoType) {
+	if !g.hasGoDefaults(goType) {
+		return
+	}
+
+	builder.WriteString("\n// SetDefaults assigns the schema default values to absent fields\n")
+	builder.WriteString(fmt.Sprintf("func (v *%s) SetDefaults() {\n", goType.Name))
+	for _, field := range g.defaultFields(goType) {
+		value, ok := g.goDefaultAssignment(field)
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(field.Type, "*") {
+			builder.WriteString(fmt.Sprintf("\tif v.%s == nil {\n", field.Name))
+			builder.WriteString(fmt.Sprintf("\t\tvalue := %s\n", value))
+			builder.WriteString(fmt.Sprintf("\t\tv.%s = &value\n", field.Name))
+			builder.WriteString("\t}\n")
+		} else if underlying := g.goUnderlyingType(field.Type); isTemporalType(underlying) {
+			// Restricted types do not have the methods of the xsd type
+			fieldValue := "v." + field.Name
+			if field.Type != underlying {
+				fieldValue = fmt.Sprintf("%s(v.%s)", underlying, field.Name)
+			}
+			builder.WriteString(fmt.Sprintf("\tif %s.IsZero() {\n", fieldValue))
+			builder.WriteString(fmt.Sprintf("\t\tv.%s = %s\n", field.Name, value))
+			builder.WriteString("\t}\n")
+		} else {
+			// Present but empty elements take the default as well
+			builder.WriteString(fmt.Sprintf("\tif v.%s == \"\" {\n", field.Name))
+			builder.WriteString(fmt.Sprintf("\t\tv.%s = %s\n", field.Name, value))
+			builder.WriteString("\t}\n")
+		}
+	}
+	builder.WriteString("}\n")
+
+	if goType.IsMixed && g.mixedContentMode != MixedContentInnerXML {
+		return // the ordered mixed UnmarshalXML calls SetDefaults itself
+	}
+	if g.splitsCatchAll(goType) {
+		return // so does the UnmarshalXML sharing out unmatched elements
+	}
+	builder.WriteString("\n// UnmarshalXML decodes the element and then applies the schema defaults\n")
+	builder.WriteString(fmt.Sprintf("func (v *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n", goType.Name))
+	builder.WriteString(fmt.Sprintf("\ttype plain %s\n", goType.Name))
+	builder.WriteString("\tif err := d.DecodeElement((*plain)(v), &start); err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tv.SetDefaults()\n")
+	builder.WriteString("\treturn nil\n")
+	builder.WriteString("}\n")
+}
+
+// goUnderlyingType follows generated simple types down to the built-in Go
+// type they are declared with. Struct, list and union types have none.
+func (g *CodeGenerator) goUnderlyingType(goType string) string {
+	for depth := 0; depth < 32; depth++ {
+		named := g.findGoType(goType)
+		if named == nil {
+			return goType
+		}
+		if !named.IsEnum && !hasGoValidation(*named) {
+			return ""
+		}
+		if named.BaseType == "" {
+			return "string"
+		}
+		goType = named.BaseType
+	}
+	return ""
+}
+
+// goDefaultValue returns the Go expression for a default value of the given
+// type, or false when the type has no literal form
+func (g *CodeGenerator) goDefaultValue(goType, value string) (string, bool) {
+	if valueType, ok := unwrapNillable(goType); ok {
+		inner, ok := g.goDefaultValue(valueType, value)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("%s{Value: %s}", goType, inner), true
+	}
+
+	// Enumerations use the matching constant
+	if named := g.findGoType(goType); named != nil && named.IsEnum {
+		for _, constant := range named.Constants {
+			if strings.Trim(constant.Value, `"`) == value {
+				return constant.Name, true
+			}
+		}
+	}
+
+	underlying := g.goUnderlyingType(goType)
+	literal, ok := defaultLiteral(underlying, value)
+	if isTemporalType(underlying) {
+		literal, ok = temporalDefault(underlying, value)
+	}
+	if !ok {
+		return "", false
+	}
+	if goType == "string" || goType == "bool" || goType == "int" || isTemporalType(goType) {
+		return literal, true
+	}
+	return fmt.Sprintf("%s(%s)", goType, literal), true
+}
+
+// defaultLiteral converts a schema default to a literal of a built-in Go
+// type. Values of types other than string are whitespace collapsed first.
+func defaultLiteral(goType, value string) (string, bool) {
+	if goType == "string" {
+		return strconv.Quote(value), true
+	}
+	value = strings.TrimSpace(value)
+	switch goType {
+	case "bool":
+		switch value {
+		case "true", "1":
+			return "true", true
+		case "false", "0":
+			return "false", true
+		}
+	case "int", "int8", "int16", "int32", "int64":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10), true
+		}
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		if n, err := strconv.ParseUint(strings.TrimPrefix(value, "+"), 10, 64); err == nil {
+			return strconv.FormatUint(n, 10), true
+		}
+	case "float32", "float64":
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !strings.ContainsAny(value, "INa") {
+			return strconv.FormatFloat(f, 'g', -1, 64), true
+		}
+	}
+	return "", false
+}
+
+// javaDefaultValue returns the Java initializer for a field default
+func (g *CodeGenerator) javaDefaultValue(field types.GoField) (string, bool) {
+	goType, _ := unwrapNillable(strings.TrimPrefix(field.Type, "*"))
+	if named := g.findGoType(goType); named != nil && named.IsEnum {
+		for _, constant := range named.Constants {
+			if strings.Trim(constant.Value, `"`) == field.DefaultValue {
+				return fmt.Sprintf("%s.%s", named.Name, strings.ToUpper(constant.Name)), true
+			}
+		}
+		return "", false
+	}
+	if temporalHeldAsString(goType) {
+		goType = "string"
+	}
+	literal, ok := defaultLiteral(goType, field.DefaultValue)
+	if !ok {
+		return "", false
+	}
+	switch g.convertToJavaType(goType) {
+	case "Long":
+		return literal + "L", true
+	case "Short":
+		return "(short) " + literal, true
+	case "Byte":
+		return "(byte) " + literal, true
+	case "Float":
+		return literal + "f", true
+	case "Double":
+		return literal + "d", true
+	case "String", "Integer", "Boolean":
+		return literal, true
+	}
+	return "", false
+}
+
+// csharpDefaultValue returns the C# initializer for a property default
+func (g *CodeGenerator) csharpDefaultValue(field types.GoField) (string, bool) {
+	goType, _ := unwrapNillable(strings.TrimPrefix(field.Type, "*"))
+	if named := g.findGoType(goType); named != nil && named.IsEnum {
+		for _, constant := range named.Constants {
+			if strings.Trim(constant.Value, `"`) == field.DefaultValue {
+				return fmt.Sprintf("%s.%s", named.Name, constant.Name), true
+			}
+		}
+		return "", false
+	}
+	if temporalHeldAsString(goType) {
+		goType = "string"
+	}
+	literal, ok := defaultLiteral(goType, field.DefaultValue)
+	if !ok {
+		return "", false
+	}
+	switch g.convertToCSharpType(goType) {
+	case "long":
+		return literal + "L", true
+	case "ulong":
+		return literal + "UL", true
+	case "uint":
+		return literal + "U", true
+	case "float":
+		return literal + "f", true
+	case "double":
+		return literal + "d", true
+	case "short", "sbyte", "byte", "ushort":
+		return fmt.Sprintf("(%s)%s", g.convertToCSharpType(goType), literal), true
+	case "string", "int", "bool":
+		return literal, true
+	}
+	return "", false
+}
@@ -2,6 +2,8 @@ package generator
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"text/template"
 
 	"github.com/suifei/xsd2code/pkg/types"
@@ -30,6 +32,27 @@ func (f *CodeGeneratorFactory) CreateGenerator() (*CodeGenerator, error) {
 
 // GenerateCode generates code for the given types using the configured language
 func (f *CodeGeneratorFactory) GenerateCode(goTypes []types.GoType) error {
+	packages, err := f.Packages(goTypes)
+	if err != nil {
+		return err
+	}
+	if packages != nil {
+		for _, pkg := range packages {
+			generator, err := f.CreateGenerator()
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(pkg.OutputPath), 0755); err != nil {
+				return fmt.Errorf("failed to create package directory: %v", err)
+			}
+			generator.SetPackage(pkg)
+			if err := generator.Generate(); err != nil {
+				return fmt.Errorf("package %s: %w", pkg.Name, err)
+			}
+		}
+		return nil
+	}
+
 	generator, err := f.CreateGenerator()
 	if err != nil {
 		return err
@@ -39,6 +62,15 @@ func (f *CodeGeneratorFactory) GenerateCode(goTypes []types.GoType) error {
 	return generator.Generate()
 }
 
+// Packages splits the types into the configured per-namespace Go packages.
+// It returns nil when the output is a single package.
+func (f *CodeGeneratorFactory) Packages(goTypes []types.GoType) ([]GoPackage, error) {
+	if len(f.config.NamespacePackages) == 0 || f.config.TargetLanguage != LanguageGo {
+		return nil, nil
+	}
+	return SplitByNamespace(goTypes, f.config.NamespacePackages, f.config.PackageName, f.config.OutputPath, f.config.ImportBase)
+}
+
 // TemplateBasedGenerator provides template-based code generation
 type TemplateBasedGenerator struct {
 	*CodeGenerator
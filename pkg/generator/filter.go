@@ -0,0 +1,233 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// RegisterTypes registers types together with the dependencies that their
+// fields, base and item types, union and substitution group members and
+// implemented interfaces create on other registered types
+func (r *TypeRegistry) RegisterTypes(goTypes []types.GoType) {
+	interfaces := make(map[string]string)
+	for _, goType := range goTypes {
+		r.RegisterType(goType)
+		if goType.IsSubstitutionGroup {
+			interfaces[goType.MemberInterface] = goType.Name
+		}
+	}
+
+	for _, goType := range goTypes {
+		seen := make(map[string]bool)
+		depend := func(name string) string {
+			if _, exists := r.types[name]; exists && name != goType.Name && !seen[name] {
+				seen[name] = true
+				r.AddDependency(goType.Name, name)
+			}
+			return name
+		}
+		for _, field := range goType.Fields {
+			types.MapTypeRef(field.Type, depend)
+		}
+		types.MapTypeRef(goType.BaseType, depend)
+		depend(goType.ListItemType)
+		for _, member := range goType.UnionMembers {
+			depend(member.TypeName)
+		}
+		for _, member := range goType.Members {
+			depend(member.TypeName)
+		}
+		// A member type needs the group that declares its interface
+		for _, iface := range goType.Implements {
+			depend(interfaces[iface])
+		}
+	}
+}
+
+// Closure returns the roots and every type they depend on, directly or
+// indirectly, in sorted order. Excluded types are left out and their own
+// dependencies are not followed.
+func (r *TypeRegistry) Closure(roots []string, excluded map[string]bool) []string {
+	visited := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] || excluded[name] {
+			return
+		}
+		visited[name] = true
+		for _, dependency := range r.GetDependencies(name) {
+			visit(dependency)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+
+	result := make([]string, 0, len(visited))
+	for name := range visited {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ExcludedReference is an excluded type that selected types still refer to
+type ExcludedReference struct {
+	Type         string
+	ReferencedBy []string
+}
+
+// SelectTypes keeps the types matched by include and everything they depend
+// on, minus the types matched by exclude. An empty include selects every
+// type. Patterns use path.Match syntax and are matched as matchTypes
+// describes; elements maps global elements to the Go types of their
+// content. The selected types keep their order, and the excluded types that
+// selected ones still refer to are reported.
+func SelectTypes(goTypes []types.GoType, elements map[types.QName]string, include, exclude []string) ([]types.GoType, []ExcludedReference, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return goTypes, nil, nil
+	}
+
+	excluded := make(map[string]bool)
+	for _, pattern := range exclude {
+		names, err := matchTypes(goTypes, elements, pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(names) == 0 {
+			return nil, nil, fmt.Errorf("exclude pattern %q matches no type", pattern)
+		}
+		for _, name := range names {
+			excluded[name] = true
+		}
+	}
+
+	roots := make([]string, 0)
+	if len(include) == 0 {
+		for _, goType := range goTypes {
+			roots = append(roots, goType.Name)
+		}
+	}
+	for _, pattern := range include {
+		names, err := matchTypes(goTypes, elements, pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(names) == 0 {
+			return nil, nil, fmt.Errorf("include pattern %q matches no type", pattern)
+		}
+		roots = append(roots, names...)
+	}
+
+	registry := NewTypeRegistry()
+	registry.RegisterTypes(goTypes)
+	selected := make(map[string]bool)
+	for _, name := range registry.Closure(roots, excluded) {
+		selected[name] = true
+	}
+
+	result := make([]types.GoType, 0, len(selected))
+	referencedBy := make(map[string][]string)
+	for _, goType := range goTypes {
+		if !selected[goType.Name] {
+			continue
+		}
+		result = append(result, goType)
+		for _, dependency := range registry.GetDependencies(goType.Name) {
+			if excluded[dependency] {
+				referencedBy[dependency] = append(referencedBy[dependency], goType.Name)
+			}
+		}
+	}
+
+	references := make([]ExcludedReference, 0, len(referencedBy))
+	for name, users := range referencedBy {
+		references = append(references, ExcludedReference{Type: name, ReferencedBy: users})
+	}
+	sort.Slice(references, func(i, j int) bool {
+		return references[i].Type < references[j].Type
+	})
+	return result, references, nil
+}
+
+// matchTypes returns the names of the types a pattern matches. A pattern
+// of the form {namespace}name matches the XML names of the types and global
+// elements of that namespace, and an element selects the type of its
+// content. Any other pattern matches the Go names of the types, or failing
+// that the XML names of every namespace; a bare XML name found in several
+// namespaces is ambiguous and must be qualified.
+func matchTypes(goTypes []types.GoType, elements map[types.QName]string, pattern string) ([]string, error) {
+	namespace, local, qualified := "", pattern, strings.HasPrefix(pattern, "{")
+	if qualified {
+		end := strings.Index(pattern, "}")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid type pattern %q: missing }", pattern)
+		}
+		namespace, local = pattern[1:end], pattern[end+1:]
+	}
+	if _, err := path.Match(local, ""); err != nil {
+		return nil, fmt.Errorf("invalid type pattern %q: %w", pattern, err)
+	}
+
+	names := make([]string, 0)
+	if !qualified {
+		for _, goType := range goTypes {
+			if matched, _ := path.Match(local, goType.Name); matched {
+				names = append(names, goType.Name)
+			}
+		}
+		if len(names) > 0 {
+			return names, nil
+		}
+	}
+
+	// XML names of types and elements, with the namespaces they were found in
+	generated := make(map[string]bool)
+	found := make(map[string]bool)
+	namespaces := make(map[string]bool)
+	for _, goType := range goTypes {
+		generated[goType.Name] = true
+		if goType.XMLName == "" || (qualified && goType.Namespace != namespace) {
+			continue
+		}
+		if matched, _ := path.Match(local, goType.XMLName); matched && !found[goType.Name] {
+			found[goType.Name] = true
+			namespaces[goType.Namespace] = true
+			names = append(names, goType.Name)
+		}
+	}
+	qnames := make([]types.QName, 0, len(elements))
+	for qname := range elements {
+		qnames = append(qnames, qname)
+	}
+	sort.Slice(qnames, func(i, j int) bool {
+		return qnames[i].String() < qnames[j].String()
+	})
+	for _, qname := range qnames {
+		typeName := elements[qname]
+		if !generated[typeName] || (qualified && qname.Space != namespace) {
+			continue
+		}
+		if matched, _ := path.Match(local, qname.Local); matched {
+			namespaces[qname.Space] = true
+			if !found[typeName] {
+				found[typeName] = true
+				names = append(names, typeName)
+			}
+		}
+	}
+
+	if len(namespaces) > 1 {
+		quoted := make([]string, 0, len(namespaces))
+		for namespace := range namespaces {
+			quoted = append(quoted, fmt.Sprintf("%q", namespace))
+		}
+		sort.Strings(quoted)
+		return nil, fmt.Errorf("type pattern %q matches names of several namespaces (%s); qualify it as {namespace}%s", pattern, strings.Join(quoted, ", "), pattern)
+	}
+	return names, nil
+}
@@ -0,0 +1,289 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// identityField is a field XPath of an identity constraint resolved to a
+// chain of struct fields; an empty chain is the selected value itself
+type identityField []types.GoField
+
+// writeGoReferenceValidators writes ValidateReferences for every type that
+// carries identity constraints
+func (g *CodeGenerator) writeGoReferenceValidators(builder *strings.Builder) {
+	for _, goType := range g.goTypes {
+		if len(goType.IdentityConstraints) > 0 {
+			g.writeGoValidateReferences(builder, goType)
+		}
+	}
+}
+
+// writeGoValidateReferences writes the document-level check of the key,
+// keyref and unique constraints of a type. Constraints whose XPaths cannot
+// be followed through the generated fields are listed but not checked.
+func (g *CodeGenerator) writeGoValidateReferences(builder *strings.Builder, goType types.GoType) {
+	builder.WriteString(fmt.Sprintf("// ValidateReferences checks the key, keyref and unique constraints of %s\n", goType.Name))
+	builder.WriteString(fmt.Sprintf("func (v *%s) ValidateReferences() error {\n", goType.Name))
+
+	var body strings.Builder
+	checked := make(map[string]bool)
+	for _, constraint := range goType.IdentityConstraints {
+		selectors, fields, ok := g.resolveIdentityConstraint(goType, constraint)
+		if ok && constraint.Kind == "keyref" && !checked[constraint.Refer] {
+			ok = false
+		}
+		if !ok {
+			body.WriteString(fmt.Sprintf("\t// %s %s is not checked: its XPaths do not map onto the generated fields\n", constraint.Kind, constraint.Name))
+			continue
+		}
+		body.WriteString(fmt.Sprintf("\t// %s %s\n", constraint.Kind, constraint.Name))
+		if constraint.Kind != "keyref" {
+			checked[constraint.Name] = true
+			body.WriteString(fmt.Sprintf("\ttables[%q] = make(map[string]bool)\n", constraint.Name))
+		}
+		for _, selector := range selectors {
+			g.writeIdentityLoops(&body, selector, func(indent, node string) {
+				g.writeIdentityCheck(&body, indent, node, constraint, fields)
+			})
+		}
+	}
+
+	if len(checked) > 0 {
+		builder.WriteString("\ttables := make(map[string]map[string]bool)\n")
+	}
+	builder.WriteString(body.String())
+	builder.WriteString("\treturn nil\n")
+	builder.WriteString("}\n\n")
+}
+
+// resolveIdentityConstraint maps the selector alternatives of a constraint
+// onto field chains from the scope type and its fields onto field chains
+// from the selected type. All selector alternatives must select one type.
+func (g *CodeGenerator) resolveIdentityConstraint(goType types.GoType, constraint types.GoIdentityConstraint) ([][]types.GoField, []identityField, bool) {
+	paths, err := types.ParseIdentityXPath(constraint.Selector, false)
+	if err != nil {
+		return nil, nil, false
+	}
+	nodeType := ""
+	selectors := make([][]types.GoField, 0, len(paths))
+	for _, path := range paths {
+		if path.Descendant {
+			return nil, nil, false
+		}
+		chain, valueType, ok := g.resolveIdentitySteps(goType.Name, path.Steps, true)
+		if !ok || (nodeType != "" && valueType != nodeType) {
+			return nil, nil, false
+		}
+		nodeType = valueType
+		selectors = append(selectors, chain)
+	}
+
+	fields := make([]identityField, 0, len(constraint.Fields))
+	for _, xpath := range constraint.Fields {
+		paths, err := types.ParseIdentityXPath(xpath, true)
+		if err != nil || len(paths) != 1 || paths[0].Descendant {
+			return nil, nil, false
+		}
+		field, ok := g.resolveIdentityField(nodeType, paths[0])
+		if !ok {
+			return nil, nil, false
+		}
+		fields = append(fields, field)
+	}
+	return selectors, fields, true
+}
+
+// resolveIdentityField maps a field path onto the fields leading from the
+// selected type to a simple value
+func (g *CodeGenerator) resolveIdentityField(nodeType string, path types.IdentityPath) (identityField, bool) {
+	chain, valueType, ok := g.resolveIdentitySteps(nodeType, path.Steps, false)
+	if !ok {
+		return nil, false
+	}
+	if path.Attribute != "" {
+		attribute, ok := g.identityChildField(valueType, path.Attribute, true)
+		if !ok {
+			return nil, false
+		}
+		return append(chain, attribute), true
+	}
+	if g.isIdentityValue(valueType) {
+		return chain, true
+	}
+	// A structured element contributes its character data
+	if len(chain) > 0 && isNillableField(chain[len(chain)-1]) {
+		return nil, false
+	}
+	if named := g.findGoType(valueType); named != nil {
+		for _, field := range named.Fields {
+			if field.IsText {
+				return append(chain, field), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// resolveIdentitySteps follows child element steps through struct fields.
+// Repeated fields are only allowed when selecting, and nillable ones only
+// as the last step of a field.
+func (g *CodeGenerator) resolveIdentitySteps(typeName string, steps []string, selecting bool) ([]types.GoField, string, bool) {
+	chain := make([]types.GoField, 0, len(steps))
+	for i, step := range steps {
+		field, ok := g.identityChildField(typeName, step, false)
+		if !ok || (field.IsArray && !selecting) {
+			return nil, "", false
+		}
+		chain = append(chain, field)
+		typeName = strings.TrimPrefix(strings.TrimPrefix(field.Type, "[]"), "*")
+		if valueType, nillable := unwrapNillable(typeName); nillable {
+			if selecting || i < len(steps)-1 {
+				return nil, "", false
+			}
+			typeName = valueType
+		}
+	}
+	return chain, typeName, true
+}
+
+// isNillableField reports whether a field holds a Nillable wrapper
+func isNillableField(field types.GoField) bool {
+	_, nillable := unwrapNillable(strings.TrimPrefix(field.Type, "*"))
+	return nillable
+}
+
+// identityChildField finds the element or attribute field of a struct type
+// that an XPath name test matches
+func (g *CodeGenerator) identityChildField(typeName, name string, attribute bool) (types.GoField, bool) {
+	goType := g.findGoType(typeName)
+	if goType == nil || name == "*" {
+		return types.GoField{}, false
+	}
+	for _, field := range g.classFields(*goType) {
+		if field.IsAttribute != attribute || field.IsWildcard || field.IsMixed || field.IsText {
+			continue
+		}
+		if tagLocalName(field.XMLTag) == name {
+			return field, true
+		}
+	}
+	return types.GoField{}, false
+}
+
+// isIdentityValue reports whether values of a Go type can be compared as
+// simple values: built-in types and generated simple types
+func (g *CodeGenerator) isIdentityValue(typeName string) bool {
+	named := g.findGoType(typeName)
+	if named == nil {
+		return true
+	}
+	return named.IsEnum || named.IsList || named.IsUnion || hasGoValidation(*named)
+}
+
+// writeIdentityLoops writes the loops and nil checks that visit every value
+// a field chain selects from v, then the body for each selected value. The
+// body receives a non-pointer expression for the value.
+func (g *CodeGenerator) writeIdentityLoops(builder *strings.Builder, chain []types.GoField, body func(indent, node string)) {
+	indent, node, depth := "\t", "v", 0
+	for _, field := range chain {
+		access := node + "." + field.Name
+		switch {
+		case strings.HasPrefix(field.Type, "[]"):
+			depth++
+			node = fmt.Sprintf("n%d", depth)
+			builder.WriteString(fmt.Sprintf("%sfor _, %s := range %s {\n", indent, node, access))
+			indent += "\t"
+		case strings.HasPrefix(field.Type, "*"):
+			builder.WriteString(fmt.Sprintf("%sif %s != nil {\n", indent, access))
+			node = "(*" + access + ")"
+			indent += "\t"
+		default:
+			node = access
+		}
+	}
+	body(indent, node)
+	for len(indent) > 1 {
+		indent = indent[1:]
+		builder.WriteString(indent + "}\n")
+	}
+}
+
+// identityKey returns the expression that turns a value of a Go type into
+// a string identifying it in the value space of its XSD type, so that equal
+// values written differently, such as the decimals 1.0 and 1, match. Named
+// simple types are keyed as the type they restrict.
+func (g *CodeGenerator) identityKey(typeName, value string) string {
+	underlying := g.goUnderlyingType(typeName)
+	if underlying != typeName && (underlying == "Decimal" || isTemporalType(underlying)) {
+		value = fmt.Sprintf("%s(%s)", underlying, value)
+	}
+	switch {
+	case underlying == "Decimal":
+		return value + ".Rat().RatString()"
+	case isTemporalType(underlying):
+		return "xsd.Key(" + value + ")"
+	}
+	return "fmt.Sprint(" + value + ")"
+}
+
+// writeIdentityCheck writes the evaluation of the fields of a constraint on
+// one selected value and the check against the constraint's table
+func (g *CodeGenerator) writeIdentityCheck(builder *strings.Builder, indent, node string, constraint types.GoIdentityConstraint, fields []identityField) {
+	builder.WriteString(fmt.Sprintf("%svar keys, values []string\n", indent))
+	for _, field := range fields {
+		value, conditions := node, make([]string, 0)
+		for _, step := range field {
+			value += "." + step.Name
+			if strings.HasPrefix(step.Type, "*") {
+				conditions = append(conditions, value+" != nil")
+			}
+		}
+		valueType := ""
+		if len(field) > 0 {
+			last := field[len(field)-1]
+			valueType = strings.TrimPrefix(last.Type, "*")
+			if inner, nillable := unwrapNillable(valueType); nillable {
+				conditions = append(conditions, "!"+value+".Nil")
+				value, valueType = value+".Value", inner
+			} else if strings.HasPrefix(last.Type, "*") {
+				value = "(*" + value + ")"
+			}
+		}
+		appendValue := fmt.Sprintf("keys, values = append(keys, %s), append(values, fmt.Sprint(%s))\n", g.identityKey(valueType, value), value)
+		if len(conditions) == 0 {
+			builder.WriteString(indent + appendValue)
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("%sif %s {\n", indent, strings.Join(conditions, " && ")))
+		builder.WriteString(indent + "\t" + appendValue)
+		builder.WriteString(indent + "}\n")
+	}
+
+	count := len(fields)
+	display := "strings.Join(values, \", \")"
+	switch constraint.Kind {
+	case "keyref":
+		builder.WriteString(fmt.Sprintf("%sif len(keys) == %d && !tables[%q][strings.Join(keys, \"\\x00\")] {\n", indent, count, constraint.Refer))
+		message := fmt.Sprintf("keyref %s value %%q does not match any %s key", constraint.Name, constraint.Refer)
+		builder.WriteString(fmt.Sprintf("%s\treturn fmt.Errorf(%s, %s)\n", indent, strconv.Quote(message), display))
+		builder.WriteString(indent + "}\n")
+		return
+	case "key":
+		builder.WriteString(fmt.Sprintf("%sif len(keys) < %d {\n", indent, count))
+		message := fmt.Sprintf("key %s requires a value for every field", constraint.Name)
+		builder.WriteString(fmt.Sprintf("%s\treturn fmt.Errorf(%s)\n", indent, strconv.Quote(message)))
+		builder.WriteString(indent + "}\n")
+	}
+	builder.WriteString(fmt.Sprintf("%sif len(keys) == %d {\n", indent, count))
+	builder.WriteString(fmt.Sprintf("%s\tkey := strings.Join(keys, \"\\x00\")\n", indent))
+	builder.WriteString(fmt.Sprintf("%s\tif tables[%q][key] {\n", indent, constraint.Name))
+	message := fmt.Sprintf("duplicate %s %s value %%q", constraint.Kind, constraint.Name)
+	builder.WriteString(fmt.Sprintf("%s\t\treturn fmt.Errorf(%s, %s)\n", indent, strconv.Quote(message), display))
+	builder.WriteString(indent + "\t}\n")
+	builder.WriteString(fmt.Sprintf("%s\ttables[%q][key] = true\n", indent, constraint.Name))
+	builder.WriteString(indent + "}\n")
+}
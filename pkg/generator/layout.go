@@ -0,0 +1,384 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// OutputLayout selects how the generated types are distributed over files
+type OutputLayout string
+
+const (
+	// LayoutSingleFile writes every type to the output file
+	LayoutSingleFile OutputLayout = "single"
+	// LayoutFilePerType writes each type to a file of its own next to the
+	// output file
+	LayoutFilePerType OutputLayout = "per-type"
+	// LayoutGroupByFeature writes enumerations, other simple types and
+	// complex types to one file each; Java puts each group in a subpackage
+	LayoutGroupByFeature OutputLayout = "grouped"
+)
+
+// Features of the grouped layout
+const (
+	featureEnums  = "enums"
+	featureSimple = "simple"
+	featureTypes  = "types"
+)
+
+// SetOutputLayout sets how the generated types are distributed over files
+func (g *CodeGenerator) SetOutputLayout(layout OutputLayout) {
+	if layout == "" {
+		layout = LayoutSingleFile
+	}
+	g.layout = layout
+}
+
+// outputFile is one file of the generated code
+type outputFile struct {
+	path    string
+	pkg     string // Java subpackage or Python module, empty for the output package itself
+	types   []types.GoType
+	helpers bool // holds the shared Go helper code
+}
+
+// outputFiles distributes the types over the files of the configured layout.
+// Split Go layouts keep the shared helpers in the output file.
+func (g *CodeGenerator) outputFiles() []outputFile {
+	if g.layout == "" || g.layout == LayoutSingleFile {
+		return []outputFile{{path: g.outputPath, types: g.goTypes, helpers: true}}
+	}
+
+	language := g.languageMapper.GetLanguage()
+	dir := filepath.Dir(g.outputPath)
+	ext := filepath.Ext(g.outputPath)
+	if ext == "" {
+		ext = g.languageMapper.GetFileExtension()
+	}
+	base := strings.TrimSuffix(filepath.Base(g.outputPath), filepath.Ext(g.outputPath))
+
+	files := make([]outputFile, 0)
+	used := make(map[string]bool)
+	if language == LanguageGo {
+		files = append(files, outputFile{path: g.outputPath, helpers: true})
+		used[strings.ToLower(base)] = true
+	}
+	unique := func(name string) string {
+		candidate := name
+		for i := 2; used[strings.ToLower(candidate)]; i++ {
+			candidate = fmt.Sprintf("%s%d", name, i)
+		}
+		used[strings.ToLower(candidate)] = true
+		return candidate
+	}
+
+	if g.layout == LayoutFilePerType {
+		for _, goType := range g.goTypes {
+			var file outputFile
+			switch language {
+			case LanguageJava, LanguageCSharp:
+				// The file of a public class carries its name
+				file.path = filepath.Join(dir, goType.Name+ext)
+			case LanguagePython:
+				file.pkg = unique(pythonModuleName(goType.Name))
+				file.path = filepath.Join(dir, file.pkg+ext)
+			default:
+				// Underscores could turn the file into a test or platform file
+				name := strings.ToLower(strings.ReplaceAll(goType.Name, "_", ""))
+				file.path = filepath.Join(dir, unique(name)+ext)
+			}
+			file.types = []types.GoType{goType}
+			files = append(files, file)
+		}
+		return files
+	}
+
+	groups := make(map[string][]types.GoType)
+	for _, goType := range g.goTypes {
+		feature := featureOf(goType)
+		groups[feature] = append(groups[feature], goType)
+	}
+	for _, feature := range []string{featureTypes, featureSimple, featureEnums} {
+		switch {
+		case len(groups[feature]) == 0:
+			continue
+		case language == LanguageJava:
+			for _, goType := range groups[feature] {
+				files = append(files, outputFile{
+					path:  filepath.Join(dir, feature, goType.Name+ext),
+					pkg:   feature,
+					types: []types.GoType{goType},
+				})
+			}
+		default:
+			name := base + "_" + feature
+			file := outputFile{path: filepath.Join(dir, name+ext), types: groups[feature]}
+			if language == LanguagePython {
+				file.pkg = name
+			}
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// featureOf returns the group of a type in the grouped layout
+func featureOf(goType types.GoType) string {
+	switch {
+	case goType.IsList || goType.IsUnion:
+		return featureSimple
+	case goType.IsEnum:
+		return featureEnums
+	case !goType.IsSubstitutionGroup && hasGoValidation(goType):
+		return featureSimple
+	default:
+		return featureTypes
+	}
+}
+
+// pythonKeywords are the lowercase Python keywords, which cannot name modules
+var pythonKeywords = map[string]bool{
+	"and": true, "as": true, "assert": true, "async": true, "await": true, "break": true,
+	"class": true, "continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true,
+	"or": true, "pass": true, "raise": true, "return": true, "try": true, "while": true,
+	"with": true, "yield": true,
+}
+
+// pythonModuleName returns the snake_case module name of a class
+func pythonModuleName(name string) string {
+	var builder strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && runes[i-1] != '_' &&
+			(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			builder.WriteRune('_')
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	module := builder.String()
+	if pythonKeywords[module] {
+		module += "_"
+	}
+	return module
+}
+
+// importIdentifiers lists, for the import statements of the Java and C#
+// headers, the identifiers that require them. A trailing * matches a prefix.
+var importIdentifiers = map[string][]string{
+	"import java.util.*;":                   {"List", "ArrayList", "Arrays", "Map", "HashMap", "Objects", "Collections"},
+	"import java.time.*;":                   {"LocalDate", "LocalDateTime", "LocalTime", "OffsetDateTime", "OffsetTime", "ZonedDateTime", "Instant", "Duration", "Period"},
+	"import java.math.*;":                   {"BigDecimal", "BigInteger"},
+	"import javax.xml.bind.annotation.*;":   {"Xml*"},
+	"using System;":                         {"Array", "ArgumentException", "Convert", "DateTime", "DateTimeOffset", "Exception", "FormatException", "Math", "StringSplitOptions", "TimeSpan", "Uri"},
+	"using System.Collections.Generic;":     {"List", "Dictionary", "HashSet", "IList", "IEnumerable"},
+	"using System.Xml.Serialization;":       {"Xml*"},
+	"using System.Text.Json.Serialization;": {"Json*"},
+}
+
+// fileImports returns the import statements of a file, limited to those its
+// code uses, followed by the imports of generated types in other files
+func (g *CodeGenerator) fileImports(file outputFile, code string, files []outputFile) []string {
+	language := g.languageMapper.GetLanguage()
+	switch language {
+	case LanguageJava, LanguageCSharp, LanguagePython:
+	default:
+		return goFileImports(g.determineNeededImports(), code)
+	}
+
+	comment := "//"
+	if language == LanguagePython {
+		comment = "#"
+	}
+	identifiers := codeIdentifiers(code, comment)
+
+	imports := make([]string, 0)
+	for _, statement := range g.languageMapper.GetImportStatements() {
+		if language == LanguagePython {
+			if statement = pythonImportUsed(statement, identifiers); statement != "" {
+				imports = append(imports, statement)
+			}
+			continue
+		}
+		if uses, known := importIdentifiers[statement]; !known || usesAny(identifiers, uses) {
+			imports = append(imports, statement)
+		}
+	}
+
+	// Java subpackages and Python modules import the generated types they use
+	if language == LanguageJava || language == LanguagePython {
+		local := make(map[string]bool, len(file.types))
+		for _, goType := range file.types {
+			local[goType.Name] = true
+		}
+		refs := make([]string, 0)
+		for _, other := range files {
+			if other.pkg == file.pkg {
+				continue
+			}
+			for _, goType := range other.types {
+				if !identifiers[goType.Name] || local[goType.Name] {
+					continue
+				}
+				if language == LanguageJava {
+					refs = append(refs, "import "+g.packageName+"."+other.pkg+"."+goType.Name+";")
+				} else {
+					refs = append(refs, "from ."+other.pkg+" import "+goType.Name)
+				}
+			}
+		}
+		sort.Strings(refs)
+		imports = append(imports, refs...)
+	}
+	return imports
+}
+
+// goFileImports keeps the candidate Go imports whose package the code refers
+// to, sorted with the standard library first. All candidates are kept if
+// the code cannot be parsed.
+func goFileImports(candidates []string, code string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+code, parser.SkipObjectResolution)
+	used := make(map[string]bool)
+	if err == nil {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if selector, ok := node.(*ast.SelectorExpr); ok {
+				if ident, ok := selector.X.(*ast.Ident); ok {
+					used[ident.Name] = true
+				}
+			}
+			return true
+		})
+	}
+
+	standard := make([]string, 0, len(candidates))
+	generated := make([]string, 0)
+	for _, candidate := range candidates {
+		importPath := strings.Trim(candidate, `"`)
+		if err == nil && !used[path.Base(importPath)] {
+			continue
+		}
+		if strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".") {
+			generated = append(generated, candidate)
+		} else {
+			standard = append(standard, candidate)
+		}
+	}
+	sort.Strings(standard)
+	sort.Strings(generated)
+	if len(standard) > 0 && len(generated) > 0 {
+		standard = append(standard, "")
+	}
+	return append(standard, generated...)
+}
+
+// pythonImportUsed trims a Python import statement to the names the code
+// uses. It returns an empty string if none is used.
+func pythonImportUsed(statement string, identifiers map[string]bool) string {
+	if module, names, found := strings.Cut(strings.TrimPrefix(statement, "from "), " import "); found && strings.HasPrefix(statement, "from ") {
+		kept := make([]string, 0)
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); identifiers[name] {
+				kept = append(kept, name)
+			}
+		}
+		if len(kept) == 0 {
+			return ""
+		}
+		return "from " + module + " import " + strings.Join(kept, ", ")
+	}
+
+	fields := strings.Fields(statement)
+	name := strings.Split(fields[len(fields)-1], ".")[0]
+	if len(fields) == 4 && fields[2] == "as" {
+		name = fields[3]
+	}
+	if !identifiers[name] {
+		return ""
+	}
+	return statement
+}
+
+// usesAny reports whether the identifiers include one of names, where a
+// trailing * matches a prefix
+func usesAny(identifiers map[string]bool, names []string) bool {
+	for _, name := range names {
+		if prefix, isPrefix := strings.CutSuffix(name, "*"); isPrefix {
+			for identifier := range identifiers {
+				if strings.HasPrefix(identifier, prefix) {
+					return true
+				}
+			}
+		} else if identifiers[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// codeIdentifiers returns the identifiers in generated code, leaving out
+// comments and string literals
+func codeIdentifiers(code, lineComment string) map[string]bool {
+	identifiers := make(map[string]bool)
+	for i := 0; i < len(code); {
+		switch c := code[i]; {
+		case strings.HasPrefix(code[i:], lineComment):
+			if end := strings.IndexByte(code[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(code)
+			}
+		case lineComment == "//" && strings.HasPrefix(code[i:], "/*"):
+			if end := strings.Index(code[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(code)
+			}
+		case c == '"' || c == '\'':
+			i++
+			for i < len(code) && code[i] != c && code[i] != '\n' {
+				if code[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case c == '_' || unicode.IsLetter(rune(c)) || c >= 0x80:
+			start := i
+			for i < len(code) && (code[i] == '_' || code[i] >= 0x80 || unicode.IsLetter(rune(code[i])) || unicode.IsDigit(rune(code[i]))) {
+				i++
+			}
+			identifiers[code[start:i]] = true
+		default:
+			i++
+		}
+	}
+	return identifiers
+}
+
+// pythonPackageInit returns the __init__.py of a split Python output, which
+// re-exports every class
+func (g *CodeGenerator) pythonPackageInit(files []outputFile) string {
+	var builder strings.Builder
+	builder.WriteString("# Code generated by xsd2code v3.0; DO NOT EDIT.\n\n")
+	for _, file := range files {
+		names := make([]string, 0, len(file.types))
+		for _, goType := range file.types {
+			names = append(names, goType.Name)
+		}
+		if len(names) > 0 {
+			builder.WriteString("from ." + file.pkg + " import " + strings.Join(names, ", ") + "\n")
+		}
+	}
+	return builder.String()
+}
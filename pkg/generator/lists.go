@@ -0,0 +1,377 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// listItem describes how the items of a list type are read and written
+type listItem struct {
+	typeName string
+	category string // value category of the item or of its base type
+	bits     int
+	isNamed  bool     // item is a type generated from the schema
+	validate bool     // item type has a Validate method
+	values   []string // constant names of an enumeration item type
+	temporal string   // xsd package type of date, time and duration items
+}
+
+// listItemFor classifies the item type of a list
+func (g *CodeGenerator) listItemFor(goType types.GoType) listItem {
+	item := listItem{typeName: goType.ListItemType}
+	baseType := goType.ListItemType
+	if itemType := g.findGoType(goType.ListItemType); itemType != nil {
+		item.isNamed = true
+		baseType = itemType.BaseType
+		switch {
+		case itemType.IsUnion:
+			item.validate = true
+		case itemType.IsEnum:
+			for _, constant := range itemType.Constants {
+				item.values = append(item.values, constant.Name)
+			}
+		case hasGoValidation(*itemType):
+			item.validate = true
+		}
+	}
+	item.category, item.bits = goValueCategory(baseType)
+	if category, ok := g.bigNumberCategory(baseType, ""); ok {
+		item.category = category
+	}
+	if underlying := g.goUnderlyingType(baseType); isTemporalType(underlying) {
+		item.temporal = underlying
+	}
+	if baseType == "float32" {
+		item.bits = 32
+	} else if item.category == valueDecimal {
+		item.bits = 64
+	}
+	return item
+}
+
+// listPackages reports whether list types need strconv to parse items
+func (g *CodeGenerator) listPackages() (needsStrconv bool) {
+	for _, goType := range g.goTypes {
+		if !goType.IsList {
+			continue
+		}
+		switch g.listItemFor(goType).category {
+		case valueBoolean, valueInteger, valueUnsigned, valueDecimal:
+			needsStrconv = true
+		}
+	}
+	return needsStrconv
+}
+
+// writeGoListType writes a Go slice type for a list, written as
+// whitespace-separated text
+func (g *CodeGenerator) writeGoListType(builder *strings.Builder, goType types.GoType) {
+	item := g.listItemFor(goType)
+
+	if g.includeComments && goType.Comment != "" {
+		g.writeComment(builder, fmt.Sprintf("%s %s", goType.Name, goType.Comment), "")
+	} else if g.includeComments {
+		g.writeComment(builder, fmt.Sprintf("%s is a whitespace-separated list of %s values", goType.Name, goType.ListItemType), "")
+	}
+	builder.WriteString(fmt.Sprintf("type %s []%s\n\n", goType.Name, goType.ListItemType))
+
+	// Validate checks the item count facets and every item
+	if g.includeComments {
+		g.writeComment(builder, "Validate checks the number of items and each item value", "")
+	}
+	builder.WriteString(fmt.Sprintf("func (v %s) Validate() bool {\n", goType.Name))
+	if goType.HasLength {
+		builder.WriteString(fmt.Sprintf("\tif len(v) != %s {\n\t\treturn false\n\t}\n", goType.Length))
+	}
+	if goType.HasMinLength {
+		builder.WriteString(fmt.Sprintf("\tif len(v) < %s {\n\t\treturn false\n\t}\n", goType.MinLength))
+	}
+	if goType.HasMaxLength {
+		builder.WriteString(fmt.Sprintf("\tif len(v) > %s {\n\t\treturn false\n\t}\n", goType.MaxLength))
+	}
+	if item.validate {
+		builder.WriteString("\tfor _, item := range v {\n")
+		builder.WriteString("\t\tif !item.Validate() {\n\t\t\treturn false\n\t\t}\n")
+		builder.WriteString("\t}\n")
+	} else if len(item.values) > 0 {
+		builder.WriteString("\tfor _, item := range v {\n")
+		builder.WriteString("\t\tswitch item {\n")
+		builder.WriteString(fmt.Sprintf("\t\tcase %s:\n", strings.Join(item.values, ", ")))
+		builder.WriteString("\t\tdefault:\n\t\t\treturn false\n")
+		builder.WriteString("\t\t}\n")
+		builder.WriteString("\t}\n")
+	}
+	builder.WriteString("\treturn true\n")
+	builder.WriteString("}\n\n")
+
+	if g.includeComments {
+		g.writeComment(builder, "MarshalText joins the items with single spaces", "")
+	}
+	builder.WriteString(fmt.Sprintf("func (v %s) MarshalText() ([]byte, error) {\n", goType.Name))
+	builder.WriteString("\titems := make([]string, len(v))\n")
+	builder.WriteString("\tfor i, item := range v {\n")
+	builder.WriteString(fmt.Sprintf("\t\titems[i] = %s\n", goListFormat(item)))
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn []byte(strings.Join(items, \" \")), nil\n")
+	builder.WriteString("}\n\n")
+
+	if g.includeComments {
+		g.writeComment(builder, "UnmarshalText splits the text on XML whitespace", "")
+	}
+	builder.WriteString(fmt.Sprintf("func (v *%s) UnmarshalText(text []byte) error {\n", goType.Name))
+	builder.WriteString("\tfields := strings.FieldsFunc(string(text), func(r rune) bool {\n")
+	builder.WriteString("\t\treturn r == ' ' || r == '\\t' || r == '\\n' || r == '\\r'\n")
+	builder.WriteString("\t})\n")
+	builder.WriteString(fmt.Sprintf("\tlist := make(%s, 0, len(fields))\n", goType.Name))
+	builder.WriteString("\tfor _, field := range fields {\n")
+	if parse := goListParse(item); parse != "" {
+		builder.WriteString(fmt.Sprintf("\t\tvalue, err := %s\n", parse))
+		builder.WriteString("\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n")
+		builder.WriteString(fmt.Sprintf("\t\tlist = append(list, %s(value))\n", item.typeName))
+	} else {
+		builder.WriteString(fmt.Sprintf("\t\tlist = append(list, %s(field))\n", item.typeName))
+	}
+	builder.WriteString("\t}\n")
+	builder.WriteString("\t*v = list\n")
+	builder.WriteString("\treturn nil\n")
+	builder.WriteString("}\n")
+}
+
+// goListParse returns the Go expression parsing one list field, or "" when
+// the field converts directly to the item type
+func goListParse(item listItem) string {
+	if item.temporal != "" {
+		return temporalParser(item.temporal) + "(field)"
+	}
+	switch item.category {
+	case valueBoolean:
+		return "strconv.ParseBool(field)"
+	case valueInteger:
+		return fmt.Sprintf("strconv.ParseInt(field, 10, %d)", item.bits)
+	case valueUnsigned:
+		return fmt.Sprintf("strconv.ParseUint(field, 10, %d)", item.bits)
+	case valueDecimal:
+		return fmt.Sprintf("strconv.ParseFloat(field, %d)", item.bits)
+	case valueBigDecimal:
+		return "ParseDecimal(field)"
+	case valueBigInteger:
+		return "ParseInteger(field)"
+	}
+	return ""
+}
+
+// goListFormat returns the Go expression formatting one list item
+func goListFormat(item listItem) string {
+	if item.temporal != "" {
+		return item.temporal + "(item).String()"
+	}
+	switch item.category {
+	case valueBoolean:
+		return "strconv.FormatBool(bool(item))"
+	case valueInteger:
+		return "strconv.FormatInt(int64(item), 10)"
+	case valueUnsigned:
+		return "strconv.FormatUint(uint64(item), 10)"
+	case valueDecimal:
+		return fmt.Sprintf("strconv.FormatFloat(float64(item), 'g', -1, %d)", item.bits)
+	case valueBigDecimal:
+		return "Decimal(item).String()"
+	case valueBigInteger:
+		return "Integer(item).String()"
+	}
+	return "string(item)"
+}
+
+// writeJavaListType writes a Java value class for a list using @XmlList
+func (g *CodeGenerator) writeJavaListType(builder *strings.Builder, goType types.GoType) {
+	itemType := g.convertToJavaType(g.listItemBaseType(goType))
+	if g.includeComments && goType.Comment != "" {
+		g.writeComment(builder, fmt.Sprintf("%s %s", goType.Name, goType.Comment), "")
+	} else if g.includeComments {
+		g.writeComment(builder, fmt.Sprintf("%s is a whitespace-separated list of %s values", goType.Name, goType.ListItemType), "")
+	}
+	builder.WriteString(fmt.Sprintf("public class %s {\n", goType.Name))
+	builder.WriteString("    @XmlValue\n")
+	builder.WriteString("    @XmlList\n")
+	builder.WriteString(fmt.Sprintf("    private List<%s> items = new ArrayList<>();\n\n", itemType))
+	builder.WriteString(fmt.Sprintf("    public List<%s> getItems() {\n", itemType))
+	builder.WriteString("        return items;\n")
+	builder.WriteString("    }\n\n")
+	builder.WriteString(fmt.Sprintf("    public void setItems(List<%s> items) {\n", itemType))
+	builder.WriteString("        this.items = items;\n")
+	builder.WriteString("    }\n\n")
+	check := g.unionCheckFor(types.GoUnionMember{TypeName: goType.ListItemType})
+
+	// Items bound to other Java types were checked by parsing them
+	builder.WriteString("    public boolean validate() {\n")
+	g.writeListItemsCheck(builder, goType, "items.size()", "        for (String item : items) {\n", itemType == "String" && !g.acceptsEveryItem(check))
+	builder.WriteString("    }\n\n")
+
+	builder.WriteString("    public static boolean isValidText(String text) {\n")
+	builder.WriteString("        String trimmed = text.trim();\n")
+	builder.WriteString("        String[] items = trimmed.isEmpty() ? new String[0] : trimmed.split(\"[ \\\\t\\\\n\\\\r]+\");\n")
+	g.writeListItemsCheck(builder, goType, "items.length", "        for (String item : items) {\n", true)
+	builder.WriteString("    }\n\n")
+
+	builder.WriteString("    static boolean isValidItem(String value) {\n")
+	if !g.writeJavaMemberCheck(builder, check) {
+		builder.WriteString("        return false;\n")
+	}
+	builder.WriteString("    }\n")
+	builder.WriteString("}\n")
+}
+
+// acceptsEveryItem reports whether the item type of a list accepts any string
+func (g *CodeGenerator) acceptsEveryItem(check unionCheck) bool {
+	return check.category == valueString && (check.kind == unionCheckBuiltin ||
+		check.kind == unionCheckRestricted && javaStringCondition(check.member) == "")
+}
+
+// writeListItemsCheck writes the body of a Java or C# method checking the
+// item count facets and, if checkItems is set, each item with isValidItem
+func (g *CodeGenerator) writeListItemsCheck(builder *strings.Builder, goType types.GoType, count, loop string, checkItems bool) {
+	csharp := g.languageMapper.GetLanguage() == LanguageCSharp
+	if condition := listLengthCondition(goType, count, "&&", ""); condition != "" {
+		if csharp {
+			builder.WriteString(fmt.Sprintf("        if (!(%s))\n        {\n            return false;\n        }\n", condition))
+		} else {
+			builder.WriteString(fmt.Sprintf("        if (!(%s)) {\n            return false;\n        }\n", condition))
+		}
+	}
+	if checkItems {
+		builder.WriteString(loop)
+		if csharp {
+			builder.WriteString("        {\n")
+			builder.WriteString("            if (!IsValidItem(item))\n            {\n                return false;\n            }\n")
+		} else {
+			builder.WriteString("            if (!isValidItem(item)) {\n                return false;\n            }\n")
+		}
+		builder.WriteString("        }\n")
+	}
+	builder.WriteString("        return true;\n")
+}
+
+// writeCSharpListType writes a C# class for a list that serializes its items
+// as whitespace-separated text
+func (g *CodeGenerator) writeCSharpListType(builder *strings.Builder, goType types.GoType) {
+	item := g.listItemFor(goType)
+	itemType := g.convertToCSharpType(g.listItemBaseType(goType))
+	if g.includeComments && goType.Comment != "" {
+		g.writeComment(builder, fmt.Sprintf("%s %s", goType.Name, goType.Comment), "")
+	} else if g.includeComments {
+		g.writeComment(builder, fmt.Sprintf("%s is a whitespace-separated list of %s values", goType.Name, goType.ListItemType), "")
+	}
+
+	parse, format := "item", "item"
+	const invariant = "System.Globalization.CultureInfo.InvariantCulture"
+	switch item.category {
+	case valueBoolean:
+		parse, format = "item == \"true\" || item == \"1\"", "item ? \"true\" : \"false\""
+	case valueInteger, valueUnsigned, valueDecimal, valueBigInteger, valueBigDecimal:
+		parse, format = fmt.Sprintf("%s.Parse(item, %s)", itemType, invariant), fmt.Sprintf("item.ToString(%s)", invariant)
+	case valueDate, valueTime, valueDateTime:
+		parse, format = fmt.Sprintf("DateTime.Parse(item, %s)", invariant), "item.ToString(\"o\")"
+	}
+
+	builder.WriteString(fmt.Sprintf("public class %s\n{\n", goType.Name))
+	builder.WriteString("    private static readonly char[] Separators = { ' ', '\\t', '\\n', '\\r' };\n\n")
+	builder.WriteString("    [XmlIgnore]\n")
+	builder.WriteString(fmt.Sprintf("    public List<%s> Items { get; set; } = new List<%s>();\n\n", itemType, itemType))
+	builder.WriteString("    [XmlText]\n")
+	builder.WriteString("    public string Text\n    {\n")
+	builder.WriteString(fmt.Sprintf("        get => string.Join(\" \", Items.ConvertAll(item => %s));\n", format))
+	builder.WriteString(fmt.Sprintf("        set => Items = new List<%s>(Array.ConvertAll(value.Split(Separators, StringSplitOptions.RemoveEmptyEntries), item => %s));\n", itemType, parse))
+	builder.WriteString("    }\n\n")
+	check := g.unionCheckFor(types.GoUnionMember{TypeName: goType.ListItemType})
+
+	// Items bound to other C# types were checked by parsing them
+	builder.WriteString("    public bool Validate()\n    {\n")
+	g.writeListItemsCheck(builder, goType, "Items.Count", "        foreach (var item in Items)\n", itemType == "string" && !g.acceptsEveryItem(check))
+	builder.WriteString("    }\n\n")
+
+	builder.WriteString("    public static bool IsValidText(string text)\n    {\n")
+	builder.WriteString("        var items = text.Split(Separators, StringSplitOptions.RemoveEmptyEntries);\n")
+	g.writeListItemsCheck(builder, goType, "items.Length", "        foreach (var item in items)\n", true)
+	builder.WriteString("    }\n\n")
+
+	builder.WriteString("    static bool IsValidItem(string value)\n    {\n")
+	if !g.writeCSharpMemberCheck(builder, check, "value") {
+		builder.WriteString("        return false;\n")
+	}
+	builder.WriteString("    }\n")
+	builder.WriteString("}\n")
+}
+
+// writePythonListType writes a Python list subclass for a list type
+func (g *CodeGenerator) writePythonListType(builder *strings.Builder, goType types.GoType) {
+	item := g.listItemFor(goType)
+	parse, format := "item", "item"
+	switch {
+	case len(item.values) > 0:
+		parse, format = goType.ListItemType+"(item)", "item.value"
+	case item.category == valueBoolean:
+		parse, format = "item in (\"true\", \"1\")", "(\"true\" if item else \"false\")"
+	case item.category == valueInteger || item.category == valueUnsigned || item.category == valueBigInteger:
+		parse, format = "int(item)", "str(item)"
+	case item.category == valueBigDecimal:
+		parse, format = "Decimal(item)", "str(item)"
+	case item.category == valueDecimal:
+		parse, format = "float(item)", "repr(item)"
+	case item.category == valueDate:
+		parse, format = "date.fromisoformat(item)", "item.isoformat()"
+	case item.category == valueTime:
+		parse, format = "time.fromisoformat(item)", "item.isoformat()"
+	case item.category == valueDateTime:
+		parse, format = "datetime.fromisoformat(item)", "item.isoformat()"
+	case item.isNamed:
+		parse = goType.ListItemType + "(item)"
+	}
+
+	builder.WriteString(fmt.Sprintf("class %s(list):\n", goType.Name))
+	builder.WriteString("    \"\"\"\n")
+	if g.includeComments && goType.Comment != "" {
+		builder.WriteString("    " + goType.Comment + "\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("    Whitespace-separated list of %s values.\n", goType.ListItemType))
+	}
+	builder.WriteString("    \"\"\"\n\n")
+	builder.WriteString("    @classmethod\n")
+	builder.WriteString("    def from_text(cls, text):\n")
+	builder.WriteString(fmt.Sprintf("        return cls(%s for item in text.split())\n\n", parse))
+	builder.WriteString("    def to_text(self):\n")
+	builder.WriteString(fmt.Sprintf("        return \" \".join(%s for item in self)\n\n", format))
+	builder.WriteString("    def validate(self):\n")
+	builder.WriteString(fmt.Sprintf("        return %s\n", listLengthCondition(goType, "len(self)", "and", "True")))
+}
+
+// listItemBaseType returns the item type of a list, or the base type of a
+// generated item type that non-Go targets represent by its value
+func (g *CodeGenerator) listItemBaseType(goType types.GoType) string {
+	if itemType := g.findGoType(goType.ListItemType); itemType != nil {
+		if itemType.IsEnum || itemType.IsUnion || itemType.BaseType == "" {
+			return "string"
+		}
+		return itemType.BaseType
+	}
+	return goType.ListItemType
+}
+
+// listLengthCondition builds the item count check of a list type
+func listLengthCondition(goType types.GoType, count, and, empty string) string {
+	conditions := make([]string, 0, 3)
+	if goType.HasLength {
+		conditions = append(conditions, fmt.Sprintf("%s == %s", count, goType.Length))
+	}
+	if goType.HasMinLength {
+		conditions = append(conditions, fmt.Sprintf("%s >= %s", count, goType.MinLength))
+	}
+	if goType.HasMaxLength {
+		conditions = append(conditions, fmt.Sprintf("%s <= %s", count, goType.MaxLength))
+	}
+	if len(conditions) == 0 {
+		return empty
+	}
+	return strings.Join(conditions, " "+and+" ")
+}
@@ -0,0 +1,296 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// MixedContentMode selects how the Go output represents mixed content
+type MixedContentMode string
+
+const (
+	// MixedContentOrdered keeps character data and typed child elements in
+	// document order in a []MixedItem field
+	MixedContentOrdered MixedContentMode = "ordered"
+	// MixedContentInnerXML keeps the raw content in a ",innerxml" string field
+	MixedContentInnerXML MixedContentMode = "innerxml"
+)
+
+// SetMixedContentMode sets how mixed content is generated for Go
+func (g *CodeGenerator) SetMixedContentMode(mode MixedContentMode) {
+	if mode == "" {
+		mode = MixedContentOrdered
+	}
+	g.mixedContentMode = mode
+}
+
+// classFields returns the fields written for a type. Mixed types keep their
+// attributes and replace the child element fields with a single content field.
+func (g *CodeGenerator) classFields(goType types.GoType) []types.GoField {
+	if !goType.IsMixed {
+		return goType.Fields
+	}
+	fields := make([]types.GoField, 0, len(goType.Fields)+1)
+	for _, field := range goType.Fields {
+		if field.IsAttribute {
+			fields = append(fields, field)
+		}
+	}
+	return append(fields, g.mixedContentField())
+}
+
+// mixedContentField returns the field holding the content of a mixed type
+func (g *CodeGenerator) mixedContentField() types.GoField {
+	if g.languageMapper.GetLanguage() == LanguageGo && g.mixedContentMode == MixedContentInnerXML {
+		return types.GoField{
+			Name:       "InnerXML",
+			Type:       "string",
+			XMLTag:     ",innerxml",
+			Comment:    "holds the raw mixed content",
+			IsOptional: true,
+			IsMixed:    true,
+		}
+	}
+	return types.GoField{
+		Name:       "Content",
+		Type:       "[]MixedItem",
+		XMLTag:     "-",
+		Comment:    "holds character data and child elements in document order",
+		IsOptional: true,
+		IsArray:    true,
+		IsMixed:    true,
+	}
+}
+
+// mixedChildFields returns the element fields of a mixed type whose types the
+// ordered Go representation decodes children into
+func (g *CodeGenerator) mixedChildFields(goType types.GoType) []types.GoField {
+	if !goType.IsMixed || g.mixedContentMode == MixedContentInnerXML {
+		return nil
+	}
+	fields := make([]types.GoField, 0, len(goType.Fields))
+	for _, field := range goType.Fields {
+		if !field.IsAttribute {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// needsMixedItems checks if any type uses the ordered mixed content helpers
+func (g *CodeGenerator) needsMixedItems() bool {
+	if g.mixedContentMode == MixedContentInnerXML {
+		return false
+	}
+	for _, goType := range g.goTypes {
+		if goType.IsMixed {
+			return true
+		}
+	}
+	return false
+}
+
+// writeGoMixedMethods writes the UnmarshalXML and MarshalXML methods that
+// keep the content of an ordered mixed type in document order. Attributes
+// go through an anonymous struct so encoding/xml handles their tags.
+func (g *CodeGenerator) writeGoMixedMethods(builder *strings.Builder, goType types.GoType) {
+	if g.mixedContentMode == MixedContentInnerXML {
+		return
+	}
+	attributes := make([]types.GoField, 0, len(goType.Fields))
+	for _, field := range goType.Fields {
+		if field.IsAttribute {
+			attributes = append(attributes, field)
+		}
+	}
+	writeAttrs := func(indent string) {
+		if len(attributes) == 0 {
+			builder.WriteString("struct{}")
+			return
+		}
+		builder.WriteString("struct {\n")
+		for _, field := range attributes {
+			builder.WriteString(fmt.Sprintf("%s\t%s %s `xml:\"%s\"`\n", indent, field.Name, field.Type, field.XMLTag))
+		}
+		builder.WriteString(indent + "}")
+	}
+
+	builder.WriteString("\n// UnmarshalXML decodes the attributes and keeps the content in document order\n")
+	builder.WriteString(fmt.Sprintf("func (v *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n", goType.Name))
+	builder.WriteString("\tvar attrs ")
+	writeAttrs("\t")
+	builder.WriteString("\n")
+	builder.WriteString("\tif err := decodeMixedAttrs(&attrs, start); err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	if goType.XMLName != "" {
+		builder.WriteString("\tv.XMLName = start.Name\n")
+	}
+	for _, field := range attributes {
+		builder.WriteString(fmt.Sprintf("\tv.%s = attrs.%s\n", field.Name, field.Name))
+	}
+	if g.hasGoDefaults(goType) {
+		builder.WriteString("\tv.SetDefaults()\n")
+	}
+	builder.WriteString("\tcontent, err := decodeMixedContent(d, func(name xml.Name) interface{} {\n")
+	fallback := ""
+	cases := make([]types.GoField, 0)
+	for _, field := range g.mixedChildFields(goType) {
+		name := tagLocalName(field.XMLTag)
+		if name == "" {
+			if fallback == "" && strings.HasPrefix(field.XMLTag, ",any") {
+				fallback = mixedChildType(field)
+			}
+			continue
+		}
+		cases = append(cases, field)
+	}
+	if len(cases) > 0 {
+		builder.WriteString("\t\tswitch name.Local {\n")
+		for _, field := range cases {
+			builder.WriteString(fmt.Sprintf("\t\tcase \"%s\":\n", tagLocalName(field.XMLTag)))
+			builder.WriteString(fmt.Sprintf("\t\t\treturn new(%s)\n", mixedChildType(field)))
+		}
+		builder.WriteString("\t\t}\n")
+	}
+	if fallback != "" {
+		builder.WriteString(fmt.Sprintf("\t\treturn new(%s)\n", fallback))
+	} else {
+		builder.WriteString("\t\treturn nil\n")
+	}
+	builder.WriteString("\t})\n")
+	builder.WriteString("\tv.Content = content\n")
+	builder.WriteString("\treturn err\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// MarshalXML encodes the attributes followed by the content in document order\n")
+	builder.WriteString(fmt.Sprintf("func (v %s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n", goType.Name))
+	if goType.XMLName != "" {
+		// The XMLName tag takes precedence over the name chosen by the caller
+		builder.WriteString(fmt.Sprintf("\tstart.Name = xml.Name{Space: %q, Local: %q}\n", goType.Namespace, goType.XMLName))
+	}
+	builder.WriteString("\tattrs := ")
+	writeAttrs("\t")
+	builder.WriteString("{")
+	for i, field := range attributes {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString("v." + field.Name)
+	}
+	builder.WriteString("}\n")
+	builder.WriteString("\treturn encodeMixed(e, start, &attrs, v.Content)\n")
+	builder.WriteString("}\n")
+}
+
+// mixedChildType returns the type a child element of a mixed type decodes into
+func mixedChildType(field types.GoField) string {
+	return strings.TrimPrefix(strings.TrimPrefix(field.Type, "[]"), "*")
+}
+
+// writeGoMixedHelpers writes MixedItem and the functions shared by the
+// methods of ordered mixed types
+func (g *CodeGenerator) writeGoMixedHelpers(builder *strings.Builder) {
+	builder.WriteString("// MixedItem is a piece of mixed content: character data, or a child\n")
+	builder.WriteString("// element when Element is set\n")
+	builder.WriteString("type MixedItem struct {\n")
+	builder.WriteString("\tText    string\n")
+	builder.WriteString("\tXMLName xml.Name\n")
+	builder.WriteString("\tElement interface{}\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// mixedTokens replays a fixed sequence of tokens\n")
+	builder.WriteString("type mixedTokens []xml.Token\n\n")
+	builder.WriteString("// Token implements xml.TokenReader\n")
+	builder.WriteString("func (t *mixedTokens) Token() (xml.Token, error) {\n")
+	builder.WriteString("\tif len(*t) == 0 {\n")
+	builder.WriteString("\t\treturn nil, io.EOF\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\ttoken := (*t)[0]\n")
+	builder.WriteString("\t*t = (*t)[1:]\n")
+	builder.WriteString("\treturn token, nil\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// decodeMixedAttrs decodes the attributes of start into attrs\n")
+	builder.WriteString("func decodeMixedAttrs(attrs interface{}, start xml.StartElement) error {\n")
+	builder.WriteString("\ttokens := mixedTokens{start, start.End()}\n")
+	builder.WriteString("\treturn xml.NewTokenDecoder(&tokens).Decode(attrs)\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// decodeMixedContent reads character data and child elements up to the end\n")
+	builder.WriteString("// of the current element. newElement returns the value a child decodes\n")
+	builder.WriteString("// into, or nil to skip it.\n")
+	builder.WriteString("func decodeMixedContent(d *xml.Decoder, newElement func(xml.Name) interface{}) ([]MixedItem, error) {\n")
+	builder.WriteString("\tvar items []MixedItem\n")
+	builder.WriteString("\tfor {\n")
+	builder.WriteString("\t\ttoken, err := d.Token()\n")
+	builder.WriteString("\t\tif err != nil {\n")
+	builder.WriteString("\t\t\treturn items, err\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t\tswitch t := token.(type) {\n")
+	builder.WriteString("\t\tcase xml.CharData:\n")
+	builder.WriteString("\t\t\titems = append(items, MixedItem{Text: string(t)})\n")
+	builder.WriteString("\t\tcase xml.StartElement:\n")
+	builder.WriteString("\t\t\telement := newElement(t.Name)\n")
+	builder.WriteString("\t\t\tif element == nil {\n")
+	builder.WriteString("\t\t\t\tif err := d.Skip(); err != nil {\n")
+	builder.WriteString("\t\t\t\t\treturn items, err\n")
+	builder.WriteString("\t\t\t\t}\n")
+	builder.WriteString("\t\t\t\tcontinue\n")
+	builder.WriteString("\t\t\t}\n")
+	builder.WriteString("\t\t\tif err := d.DecodeElement(element, &t); err != nil {\n")
+	builder.WriteString("\t\t\t\treturn items, err\n")
+	builder.WriteString("\t\t\t}\n")
+	builder.WriteString("\t\t\titems = append(items, MixedItem{XMLName: t.Name, Element: element})\n")
+	builder.WriteString("\t\tcase xml.EndElement:\n")
+	builder.WriteString("\t\t\treturn items, nil\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// encodeMixed writes start with the attributes encoded from attrs, the\n")
+	builder.WriteString("// content items in order and the matching end element\n")
+	builder.WriteString("func encodeMixed(e *xml.Encoder, start xml.StartElement, attrs interface{}, items []MixedItem) error {\n")
+	builder.WriteString("\tvar buf bytes.Buffer\n")
+	builder.WriteString("\tif err := xml.NewEncoder(&buf).EncodeElement(attrs, xml.StartElement{Name: start.Name}); err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\ttoken, err := xml.NewDecoder(&buf).Token()\n")
+	builder.WriteString("\tif err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tif encoded, ok := token.(xml.StartElement); ok {\n")
+	builder.WriteString("\t\tfor _, attr := range encoded.Attr {\n")
+	builder.WriteString("\t\t\tif !isNamespaceDecl(attr) {\n")
+	builder.WriteString("\t\t\t\tstart.Attr = append(start.Attr, attr)\n")
+	builder.WriteString("\t\t\t}\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tif err := e.EncodeToken(start); err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tfor _, item := range items {\n")
+	builder.WriteString("\t\tif item.Element == nil {\n")
+	builder.WriteString("\t\t\terr = e.EncodeToken(xml.CharData(item.Text))\n")
+	builder.WriteString("\t\t} else {\n")
+	builder.WriteString("\t\t\terr = e.EncodeElement(item.Element, xml.StartElement{Name: item.XMLName})\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t\tif err != nil {\n")
+	builder.WriteString("\t\t\treturn err\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn e.EncodeToken(start.End())\n")
+	builder.WriteString("}\n\n")
+}
+
+// tagLocalName returns the local element or attribute name of an XML struct
+// tag, without its namespace and options
+func tagLocalName(tag string) string {
+	name := strings.Split(tag, ",")[0]
+	if space := strings.LastIndex(name, " "); space != -1 {
+		name = name[space+1:]
+	}
+	return name
+}
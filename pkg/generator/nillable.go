@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// nillablePrefix starts the Go type of a field declared nillable="true"
+const nillablePrefix = "Nillable["
+
+// unwrapNillable returns the value type of a Nillable[T] Go type
+func unwrapNillable(goType string) (string, bool) {
+	if !strings.HasPrefix(goType, nillablePrefix) || !strings.HasSuffix(goType, "]") {
+		return goType, false
+	}
+	return goType[len(nillablePrefix) : len(goType)-1], true
+}
+
+// needsNillable checks if any field uses the Nillable wrapper
+func (g *CodeGenerator) needsNillable() bool {
+	for _, goType := range g.goTypes {
+		fields := append(g.classFields(goType), g.mixedChildFields(goType)...)
+		for _, field := range fields {
+			if field.IsNillable {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeGoNillableType writes the generic wrapper that keeps xsi:nil apart
+// from the zero value of a nillable element
+func (g *CodeGenerator) writeGoNillableType(builder *strings.Builder) {
+	builder.WriteString("// xsiNamespace is the namespace of the xsi:nil attribute\n")
+	builder.WriteString("const xsiNamespace = \"" + types.XSINamespace + "\"\n\n")
+
+	builder.WriteString("// Nillable holds the value of a nillable element or records that it was xsi:nil\n")
+	builder.WriteString("type Nillable[T any] struct {\n")
+	builder.WriteString("\tValue T\n")
+	builder.WriteString("\tNil   bool\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// UnmarshalXML decodes the element value unless it carries xsi:nil=\"true\"\n")
+	builder.WriteString("func (n *Nillable[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n")
+	builder.WriteString("\tfor _, attr := range start.Attr {\n")
+	builder.WriteString("\t\tif attr.Name.Space == xsiNamespace && attr.Name.Local == \"nil\" && (attr.Value == \"true\" || attr.Value == \"1\") {\n")
+	builder.WriteString("\t\t\tvar zero T\n")
+	builder.WriteString("\t\t\tn.Value, n.Nil = zero, true\n")
+	builder.WriteString("\t\t\treturn d.Skip()\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tn.Nil = false\n")
+	builder.WriteString("\treturn d.DecodeElement(&n.Value, &start)\n")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString("// MarshalXML writes an empty element with xsi:nil=\"true\" for a nil value\n")
+	builder.WriteString("func (n Nillable[T]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n")
+	builder.WriteString("\tif !n.Nil {\n")
+	builder.WriteString("\t\treturn e.EncodeElement(n.Value, start)\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tstart.Attr = append(start.Attr,\n")
+	builder.WriteString("\t\txml.Attr{Name: xml.Name{Local: \"xmlns:xsi\"}, Value: xsiNamespace},\n")
+	builder.WriteString("\t\txml.Attr{Name: xml.Name{Local: \"xsi:nil\"}, Value: \"true\"},\n")
+	builder.WriteString("\t)\n")
+	builder.WriteString("\tif err := e.EncodeToken(start); err != nil {\n")
+	builder.WriteString("\t\treturn err\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn e.EncodeToken(start.End())\n")
+	builder.WriteString("}\n\n")
+}
+
+// nullableCSharpType makes a C# value type nullable so it can hold xsi:nil
+func nullableCSharpType(csharpType string) string {
+	switch csharpType {
+	case "int", "sbyte", "short", "long", "uint", "byte", "ushort", "ulong",
+		"float", "double", "decimal", "bool", "DateTime", "TimeSpan":
+		return csharpType + "?"
+	}
+	return csharpType
+}
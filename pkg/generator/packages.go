@@ -0,0 +1,266 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// GoPackage is the Go package generated for one or more target namespaces
+type GoPackage struct {
+	Name       string
+	ImportPath string
+	OutputPath string
+	Namespaces []string
+	Types      []types.GoType
+	Imports    []string // import paths of the generated packages it uses
+
+	// external holds the types of the other packages under the qualified
+	// names this package refers to them by
+	external map[string]types.GoType
+}
+
+// SplitByNamespace distributes types over one Go package per namespace.
+// packageNames maps namespaces to package names; namespaces mapped to the
+// same name share a package, and unmapped namespaces go to defaultPackage.
+// Each package is written to <dir of outputPath>/<name>/<file of outputPath>
+// and imported as <importBase>/<name>. References to types of other packages
+// are qualified with the package name.
+func SplitByNamespace(goTypes []types.GoType, packageNames map[string]string, defaultPackage, outputPath, importBase string) ([]GoPackage, error) {
+	packageOf := func(namespace string) string {
+		if name, exists := packageNames[namespace]; exists {
+			return name
+		}
+		return defaultPackage
+	}
+
+	// Packages are ordered by their first type, which puts the main schema first
+	owners := make(map[string]string, len(goTypes))
+	index := make(map[string]int)
+	seen := make(map[string]bool)
+	packages := make([]GoPackage, 0)
+	add := func(name string, goType types.GoType) {
+		owners[goType.Name] = name
+		i, exists := index[name]
+		if !exists {
+			i = len(packages)
+			index[name] = i
+			packages = append(packages, GoPackage{
+				Name:       name,
+				ImportPath: strings.TrimSuffix(importBase, "/") + "/" + name,
+				OutputPath: filepath.Join(filepath.Dir(outputPath), name, filepath.Base(outputPath)),
+			})
+		}
+		if !seen[goType.Namespace] {
+			seen[goType.Namespace] = true
+			packages[i].Namespaces = append(packages[i].Namespaces, goType.Namespace)
+		}
+		packages[i].Types = append(packages[i].Types, goType)
+	}
+	for _, goType := range goTypes {
+		if !goType.IsSubstitutionGroup {
+			add(packageOf(goType.Namespace), goType)
+		}
+	}
+	// A substitution group holder refers to all member types, so it goes to
+	// the earliest package of its head and members, which imports the others
+	for _, goType := range goTypes {
+		if !goType.IsSubstitutionGroup {
+			continue
+		}
+		name := packageOf(goType.Namespace)
+		for _, member := range goType.Members {
+			owner, exists := owners[member.TypeName]
+			if !exists {
+				continue
+			}
+			if current, placed := index[name]; !placed || index[owner] < current {
+				name = owner
+			}
+		}
+		add(name, goType)
+	}
+
+	uses := make(map[string]map[string]bool, len(packages))
+	for i := range packages {
+		pkg := &packages[i]
+		used := make(map[string]bool)
+		qualify := func(name string) string {
+			if owner, exists := owners[name]; exists && owner != pkg.Name {
+				used[owner] = true
+				return owner + "." + name
+			}
+			return name
+		}
+		for j := range pkg.Types {
+			pkg.Types[j] = qualifyTypeRefs(pkg.Types[j], qualify)
+		}
+
+		pkg.external = make(map[string]types.GoType)
+		for _, other := range packages {
+			if other.Name == pkg.Name {
+				continue
+			}
+			for _, goType := range other.Types {
+				external := qualifyTypeRefs(goType, func(name string) string {
+					if owner, exists := owners[name]; exists && owner != pkg.Name {
+						return owner + "." + name
+					}
+					return name
+				})
+				external.Name = other.Name + "." + goType.Name
+				for k := range external.Constants {
+					external.Constants[k].Name = other.Name + "." + external.Constants[k].Name
+				}
+				pkg.external[external.Name] = external
+			}
+		}
+
+		uses[pkg.Name] = used
+	}
+	for i := range packages {
+		for name := range uses[packages[i].Name] {
+			packages[i].Imports = append(packages[i].Imports, packages[index[name]].ImportPath)
+		}
+		sort.Strings(packages[i].Imports)
+	}
+
+	if cycle := findImportCycle(packages, uses); cycle != nil {
+		return nil, fmt.Errorf("packages %s import each other; map their namespaces to the same package", strings.Join(cycle, " -> "))
+	}
+	return packages, nil
+}
+
+// qualifyTypeRefs returns a copy of a type whose references to other types
+// are passed through qualify. Implemented interfaces only name marker
+// methods and stay as they are.
+func qualifyTypeRefs(goType types.GoType, qualify func(string) string) types.GoType {
+	goType.Fields = append([]types.GoField(nil), goType.Fields...)
+	for i := range goType.Fields {
+		goType.Fields[i].Type = types.MapTypeRef(goType.Fields[i].Type, qualify)
+	}
+	if goType.BaseType != "" {
+		goType.BaseType = types.MapTypeRef(goType.BaseType, qualify)
+	}
+	if goType.ListItemType != "" {
+		goType.ListItemType = qualify(goType.ListItemType)
+	}
+	goType.UnionMembers = append([]types.GoUnionMember(nil), goType.UnionMembers...)
+	for i := range goType.UnionMembers {
+		goType.UnionMembers[i].TypeName = qualify(goType.UnionMembers[i].TypeName)
+	}
+	goType.Members = append([]types.GoSubstitutionMember(nil), goType.Members...)
+	for i := range goType.Members {
+		goType.Members[i].TypeName = qualify(goType.Members[i].TypeName)
+	}
+	goType.Constants = append([]types.GoConstant(nil), goType.Constants...)
+	return goType
+}
+
+// findImportCycle returns the packages of an import cycle, or nil
+func findImportCycle(packages []GoPackage, uses map[string]map[string]bool) []string {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+		imported := make([]string, 0, len(uses[name]))
+		for other := range uses[name] {
+			imported = append(imported, other)
+		}
+		sort.Strings(imported)
+		for _, other := range imported {
+			switch state[other] {
+			case visiting:
+				for i, entry := range path {
+					if entry == other {
+						return append(append([]string(nil), path[i:]...), other)
+					}
+				}
+			case 0:
+				if cycle := visit(other); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
+	}
+	for _, pkg := range packages {
+		if state[pkg.Name] == 0 {
+			if cycle := visit(pkg.Name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// SetPackage makes the generator write one package of a per-namespace output
+func (g *CodeGenerator) SetPackage(pkg GoPackage) {
+	g.packageName = pkg.Name
+	g.outputPath = pkg.OutputPath
+	g.goTypes = pkg.Types
+	g.goPackage = &pkg
+}
+
+// memberMethod returns the marker method of a substitution group interface.
+// Members may live in other packages, which can only implement exported methods.
+func (g *CodeGenerator) memberMethod(iface string) string {
+	if g.goPackage != nil {
+		return "Is" + iface[strings.LastIndex(iface, ".")+1:]
+	}
+	return "is" + iface
+}
+
+// DetectImportPath derives the Go import path of a directory from the
+// go.mod file of the module containing it
+func DetectImportPath(dir string) (string, error) {
+	absolute, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for current := absolute; ; current = filepath.Dir(current) {
+		if module, ok := readModulePath(filepath.Join(current, "go.mod")); ok {
+			rel, err := filepath.Rel(current, absolute)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return module, nil
+			}
+			return module + "/" + filepath.ToSlash(rel), nil
+		}
+		if filepath.Dir(current) == current {
+			return "", fmt.Errorf("no go.mod found above %s", absolute)
+		}
+	}
+}
+
+// readModulePath reads the module path declared by a go.mod file
+func readModulePath(path string) (string, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if module, found := strings.CutPrefix(line, "module "); found {
+			return strings.Trim(strings.TrimSpace(module), `"`), true
+		}
+	}
+	return "", false
+}
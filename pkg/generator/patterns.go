@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/xsdregex"
+)
+
+// patternDialects maps target languages to the regular expression syntax
+// of their standard library
+var patternDialects = map[TargetLanguage]xsdregex.Dialect{
+	LanguageGo:     xsdregex.RE2,
+	LanguageJava:   xsdregex.Java,
+	LanguageCSharp: xsdregex.DotNet,
+	LanguagePython: xsdregex.Python,
+}
+
+// translatePatterns converts the XSD patterns of a type into anchored
+// regular expressions for the target language. Generated code skips the
+// checks of patterns that cannot be translated and says why in a comment.
+func (g *CodeGenerator) translatePatterns(patterns []string) ([]string, []error) {
+	return translatePatterns(patterns, patternDialects[g.languageMapper.GetLanguage()])
+}
+
+// translatePatterns converts XSD patterns into anchored regular expressions
+// of a dialect, returning the errors of those it cannot translate
+func translatePatterns(patterns []string, dialect xsdregex.Dialect) ([]string, []error) {
+	translated := make([]string, 0, len(patterns))
+	var errs []error
+	for _, pattern := range patterns {
+		result, err := xsdregex.TranslateTo(pattern, dialect)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		translated = append(translated, result)
+	}
+	return translated, errs
+}
+
+// matchAll combines anchored regular expressions into one that matches the
+// values all of them match, using lookaheads, which Go does not support
+func matchAll(translated []string) string {
+	last := len(translated) - 1
+	var builder strings.Builder
+	for _, pattern := range translated[:last] {
+		builder.WriteString("(?=" + pattern + ")")
+	}
+	builder.WriteString(translated[last])
+	return builder.String()
+}
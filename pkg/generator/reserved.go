@@ -0,0 +1,30 @@
+package generator
+
+// ReservedTypeNames are the package-level identifiers the Go generator writes
+// next to the schema types. Schema types must not take these names.
+var ReservedTypeNames = []string{
+	// Wildcards, mixed content and nillable elements
+	"AnyElement", "AnyAttributes", "MixedItem", "Nillable", "mixedTokens",
+	"anyElementTokens", "isNamespaceDecl", "decodeMixedContent", "decodeMixedAttrs", "encodeMixed", "xsiNamespace",
+	// Validation code
+	"Validator", "applyWhiteSpaceProcessing", "validatePattern", "validateIntRange",
+	"validateFixedValue", "validateDateTime",
+	// Test code
+	"stringPtr", "intPtr", "uintPtr", "floatPtr", "boolPtr", "timePtr", "durationPtr",
+}
+
+// ReservedBigNumberNames are the identifiers of the arbitrary-precision
+// number types, reserved when they are enabled
+var ReservedBigNumberNames = []string{
+	"Decimal", "NewDecimal", "ParseDecimal", "Integer", "NewInteger", "ParseInteger",
+}
+
+// ReservedFieldNames are the names a generated struct uses besides its
+// schema fields: the XMLName field and the methods written for the type
+var ReservedFieldNames = []string{
+	"XMLName", "Validate", "ValidateReferences", "SetDefaults",
+	"MarshalXML", "UnmarshalXML", "MarshalText", "UnmarshalText",
+}
+
+// ReservedMixedFieldNames are the content fields of mixed types
+var ReservedMixedFieldNames = []string{"Content", "InnerXML"}
@@ -317,7 +317,7 @@ func getBuiltinTypeMappings() []CommonTypeMapping {
 		// Date and time types
 		{
 			XSDType:    "dateTime",
-			GoType:     "time.Time",
+			GoType:     "xsd.DateTime",
 			JavaType:   "LocalDateTime",
 			CSharpType: "DateTime",
 			PythonType: "datetime",
@@ -325,7 +325,7 @@ func getBuiltinTypeMappings() []CommonTypeMapping {
 		},
 		{
 			XSDType:    "date",
-			GoType:     "string",
+			GoType:     "xsd.Date",
 			JavaType:   "LocalDate",
 			CSharpType: "DateTime",
 			PythonType: "date",
@@ -333,7 +333,7 @@ func getBuiltinTypeMappings() []CommonTypeMapping {
 		},
 		{
 			XSDType:    "time",
-			GoType:     "string",
+			GoType:     "xsd.Time",
 			JavaType:   "LocalTime",
 			CSharpType: "TimeSpan",
 			PythonType: "time",
@@ -341,7 +341,7 @@ func getBuiltinTypeMappings() []CommonTypeMapping {
 		},
 		{
 			XSDType:    "duration",
-			GoType:     "string",
+			GoType:     "xsd.Duration",
 			JavaType:   "Duration",
 			CSharpType: "TimeSpan",
 			PythonType: "timedelta",
@@ -349,7 +349,7 @@ func getBuiltinTypeMappings() []CommonTypeMapping {
 		},
 		{
 			XSDType:    "gYearMonth",
-			GoType:     "string",
+			GoType:     "xsd.GYearMonth",
 			JavaType:   "YearMonth",
 			CSharpType: "DateTime",
 			PythonType: "str",
@@ -357,7 +357,7 @@ func getBuiltinTypeMappings() []CommonTypeMapping {
 		},
 		{
 			XSDType:    "gYear",
-			GoType:     "string",
+			GoType:     "xsd.GYear",
 			JavaType:   "Year",
 			CSharpType: "DateTime",
 			PythonType: "str",
@@ -365,7 +365,7 @@ func getBuiltinTypeMappings() []CommonTypeMapping {
 		},
 		{
 			XSDType:    "gMonthDay",
-			GoType:     "string",
+			GoType:     "xsd.GMonthDay",
 			JavaType:   "MonthDay",
 			CSharpType: "DateTime",
 			PythonType: "str",
@@ -373,7 +373,7 @@ func getBuiltinTypeMappings() []CommonTypeMapping {
 		},
 		{
 			XSDType:    "gDay",
-			GoType:     "string",
+			GoType:     "xsd.GDay",
 			JavaType:   "String",
 			CSharpType: "string",
 			PythonType: "str",
@@ -381,7 +381,7 @@ func getBuiltinTypeMappings() []CommonTypeMapping {
 		},
 		{
 			XSDType:    "gMonth",
-			GoType:     "string",
+			GoType:     "xsd.GMonth",
 			JavaType:   "String",
 			CSharpType: "string",
 			PythonType: "str",
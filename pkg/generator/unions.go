@@ -0,0 +1,827 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+	"github.com/suifei/xsd2code/pkg/xsdregex"
+)
+
+// Kinds of checks used to test a value against a union member type
+const (
+	unionCheckBuiltin    = "builtin"
+	unionCheckEnum       = "enum"
+	unionCheckUnion      = "union"
+	unionCheckList       = "list"
+	unionCheckRestricted = "restricted"
+)
+
+// Value categories of built-in member types
+const (
+	valueString   = "string"
+	valueBoolean  = "boolean"
+	valueInteger  = "integer"
+	valueUnsigned = "unsigned"
+	valueDecimal  = "decimal"
+	valueDate     = "date"
+	valueTime     = "time"
+	valueDateTime = "dateTime"
+
+	// Values held by the arbitrary-precision Decimal and Integer types
+	valueBigInteger = "bigInteger"
+	valueBigDecimal = "bigDecimal"
+)
+
+// unionCheck describes how to test a value against one union member
+type unionCheck struct {
+	kind     string
+	typeName string
+	category string   // value category of the member or of its base type
+	bits     int      // integer size for integer and unsigned categories
+	values   []string // quoted values of enumeration members
+	temporal string   // xsd package type of date, time and duration members
+	decimal  bool     // xs:decimal rather than xs:float or xs:double member
+	sign     string   // comparison with zero of integer types bounded by it
+	member   *types.GoType
+}
+
+// integerSigns maps the built-in integer types bounded by zero to the
+// comparison their values satisfy
+var integerSigns = map[string]string{
+	"positiveInteger":    "> 0",
+	"nonNegativeInteger": ">= 0",
+	"negativeInteger":    "< 0",
+	"nonPositiveInteger": "<= 0",
+}
+
+// zeroBound returns the comparison with zero a parsed value of the member
+// must satisfy, or "" when parsing it checks the bound already
+func (check unionCheck) zeroBound() string {
+	if check.category == valueUnsigned && check.sign == ">= 0" {
+		return ""
+	}
+	return check.sign
+}
+
+// Lexical spaces of the decimal and floating-point types, checked before
+// parsing as the parsers of the target languages accept more
+const (
+	decimalLexical = `[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)`
+	floatLexical   = `[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([Ee][+-]?[0-9]+)?|[+-]?INF|NaN`
+)
+
+// lexical returns the lexical space of a decimal or floating-point member
+func (check unionCheck) lexical() string {
+	if check.decimal {
+		return decimalLexical
+	}
+	return floatLexical
+}
+
+// unionChecks returns the checks for the members of a union, in order
+func (g *CodeGenerator) unionChecks(goType types.GoType) []unionCheck {
+	checks := make([]unionCheck, 0, len(goType.UnionMembers))
+	for _, member := range goType.UnionMembers {
+		checks = append(checks, g.unionCheckFor(member))
+	}
+	return checks
+}
+
+// unionCheckFor classifies a union member type
+func (g *CodeGenerator) unionCheckFor(member types.GoUnionMember) unionCheck {
+	check := unionCheck{kind: unionCheckBuiltin, typeName: member.TypeName}
+	if member.XSDType != "" {
+		check.typeName = "xs:" + member.XSDType
+		check.category, check.bits = xsdValueCategory(member.XSDType)
+		if category, ok := g.bigNumberCategory("", member.XSDType); ok {
+			check.category = category
+		}
+		check.temporal = temporalGoType(member.XSDType)
+		check.decimal = member.XSDType == "decimal"
+		check.sign = integerSigns[member.XSDType]
+		return check
+	}
+
+	memberType := g.findGoType(member.TypeName)
+	if memberType == nil {
+		check.category, check.bits = goValueCategory(member.TypeName)
+		if isTemporalType(member.TypeName) {
+			check.temporal = member.TypeName
+		}
+		return check
+	}
+	check.category, check.bits = goValueCategory(memberType.BaseType)
+	if category, ok := g.bigNumberCategory(memberType.BaseType, ""); ok {
+		check.category = category
+	}
+	if underlying := g.goUnderlyingType(memberType.BaseType); isTemporalType(underlying) {
+		check.temporal = underlying
+	}
+	check.member = memberType
+	check.decimal = memberType.BuiltinBase == "decimal"
+	check.sign = integerSigns[memberType.BuiltinBase]
+
+	switch {
+	case memberType.IsList:
+		check.kind = unionCheckList
+	case memberType.IsUnion:
+		check.kind = unionCheckUnion
+	case memberType.IsEnum:
+		check.kind = unionCheckEnum
+		for _, constant := range memberType.Constants {
+			check.values = append(check.values, constant.Value)
+		}
+	case hasGoValidation(*memberType):
+		check.kind = unionCheckRestricted
+	}
+	return check
+}
+
+// xsdValueCategory returns the value category of a built-in XSD type
+func xsdValueCategory(xsdType string) (string, int) {
+	switch xsdType {
+	case "boolean":
+		return valueBoolean, 0
+	case "byte":
+		return valueInteger, 8
+	case "short":
+		return valueInteger, 16
+	case "int":
+		return valueInteger, 32
+	case "long", "integer", "negativeInteger", "nonPositiveInteger":
+		return valueInteger, 64
+	case "unsignedByte":
+		return valueUnsigned, 8
+	case "unsignedShort":
+		return valueUnsigned, 16
+	case "unsignedInt":
+		return valueUnsigned, 32
+	case "unsignedLong", "nonNegativeInteger", "positiveInteger":
+		return valueUnsigned, 64
+	case "decimal", "float", "double":
+		return valueDecimal, 0
+	case "date":
+		return valueDate, 0
+	case "time":
+		return valueTime, 0
+	case "dateTime":
+		return valueDateTime, 0
+	default:
+		return valueString, 0
+	}
+}
+
+// goValueCategory returns the value category of a Go base type
+func goValueCategory(goType string) (string, int) {
+	switch goType {
+	case "bool":
+		return valueBoolean, 0
+	case "int8":
+		return valueInteger, 8
+	case "int16":
+		return valueInteger, 16
+	case "int32":
+		return valueInteger, 32
+	case "int", "int64":
+		return valueInteger, 64
+	case "uint8":
+		return valueUnsigned, 8
+	case "uint16":
+		return valueUnsigned, 16
+	case "uint32":
+		return valueUnsigned, 32
+	case "uint", "uint64":
+		return valueUnsigned, 64
+	case "float32", "float64":
+		return valueDecimal, 0
+	case "time.Time", "xsd.DateTime":
+		return valueDateTime, 0
+	default:
+		return valueString, 0
+	}
+}
+
+// unionMemberNames lists the member types of a union for comments
+func unionMemberNames(goType types.GoType) string {
+	names := make([]string, 0, len(goType.UnionMembers))
+	for _, member := range goType.UnionMembers {
+		if member.XSDType != "" {
+			names = append(names, "xs:"+member.XSDType)
+		} else {
+			names = append(names, member.TypeName)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// unionPackages reports whether union types need strconv to parse values
+func (g *CodeGenerator) unionPackages() (needsStrconv bool) {
+	for _, goType := range g.goTypes {
+		if !goType.IsUnion {
+			continue
+		}
+		for _, check := range g.unionChecks(goType) {
+			if check.kind == unionCheckEnum || check.kind == unionCheckUnion || check.kind == unionCheckList || check.temporal != "" {
+				continue
+			}
+			switch check.category {
+			case valueInteger, valueUnsigned:
+				needsStrconv = true
+			case valueDecimal, valueBoolean:
+				needsStrconv = needsStrconv || check.kind == unionCheckRestricted
+			}
+		}
+	}
+	return needsStrconv
+}
+
+// needsNumberLexical reports whether union types check decimal or
+// floating-point literals
+func (g *CodeGenerator) needsNumberLexical() bool {
+	for _, goType := range g.goTypes {
+		if !goType.IsUnion {
+			continue
+		}
+		for _, check := range g.unionChecks(goType) {
+			if g.checksNumberLexical(check) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checksNumberLexical reports whether a union member is tested with
+// isNumberLexical
+func (g *CodeGenerator) checksNumberLexical(check unionCheck) bool {
+	switch check.kind {
+	case unionCheckBuiltin, unionCheckRestricted:
+		return check.category == valueDecimal && check.temporal == ""
+	}
+	return false
+}
+
+// writeGoNumberLexical writes the helper checking the lexical space of
+// decimal and floating-point union members, which strconv.ParseFloat is
+// more lenient about
+func (g *CodeGenerator) writeGoNumberLexical(builder *strings.Builder) {
+	builder.WriteString("// isNumberLexical reports whether s is an xs:decimal literal or, if floating\n")
+	builder.WriteString("// is set, an xs:float or xs:double literal, which may also have an exponent\n")
+	builder.WriteString("// or be INF, -INF or NaN\n")
+	builder.WriteString("func isNumberLexical(s string, floating bool) bool {\n")
+	builder.WriteString("\tif floating && (s == \"INF\" || s == \"+INF\" || s == \"-INF\" || s == \"NaN\") {\n")
+	builder.WriteString("\t\treturn true\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tif s != \"\" && (s[0] == '+' || s[0] == '-') {\n")
+	builder.WriteString("\t\ts = s[1:]\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\tdigits, point := 0, false\n")
+	builder.WriteString("\tfor i := 0; i < len(s); i++ {\n")
+	builder.WriteString("\t\tswitch c := s[i]; {\n")
+	builder.WriteString("\t\tcase c >= '0' && c <= '9':\n")
+	builder.WriteString("\t\t\tdigits++\n")
+	builder.WriteString("\t\tcase c == '.' && !point:\n")
+	builder.WriteString("\t\t\tpoint = true\n")
+	builder.WriteString("\t\tcase (c == 'e' || c == 'E') && floating && digits > 0:\n")
+	builder.WriteString("\t\t\texponent := s[i+1:]\n")
+	builder.WriteString("\t\t\tif exponent != \"\" && (exponent[0] == '+' || exponent[0] == '-') {\n")
+	builder.WriteString("\t\t\t\texponent = exponent[1:]\n")
+	builder.WriteString("\t\t\t}\n")
+	builder.WriteString("\t\t\tfor j := 0; j < len(exponent); j++ {\n")
+	builder.WriteString("\t\t\t\tif exponent[j] < '0' || exponent[j] > '9' {\n")
+	builder.WriteString("\t\t\t\t\treturn false\n")
+	builder.WriteString("\t\t\t\t}\n")
+	builder.WriteString("\t\t\t}\n")
+	builder.WriteString("\t\t\treturn exponent != \"\"\n")
+	builder.WriteString("\t\tdefault:\n")
+	builder.WriteString("\t\t\treturn false\n")
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t}\n")
+	builder.WriteString("\treturn digits > 0\n")
+	builder.WriteString("}\n\n")
+}
+
+// writeGoUnionType writes a string-backed Go type for a union
+func (g *CodeGenerator) writeGoUnionType(builder *strings.Builder, goType types.GoType) {
+	if g.includeComments && goType.Comment != "" {
+		g.writeComment(builder, fmt.Sprintf("%s %s", goType.Name, goType.Comment), "")
+	} else if g.includeComments {
+		g.writeComment(builder, fmt.Sprintf("%s is a union of %s", goType.Name, unionMemberNames(goType)), "")
+	}
+	builder.WriteString(fmt.Sprintf("type %s string\n\n", goType.Name))
+
+	if g.includeComments {
+		g.writeComment(builder, "Validate reports whether the value matches one of the member types, tried in order", "")
+	}
+	builder.WriteString(fmt.Sprintf("func (v %s) Validate() bool {\n", goType.Name))
+	checks := g.unionChecks(goType)
+	if checks[0].kind == unionCheckBuiltin && checks[0].category == valueString && checks[0].temporal == "" {
+		builder.WriteString("\treturn true\n")
+	} else {
+		builder.WriteString("\ts := string(v)\n")
+		terminal := false
+		for _, check := range checks {
+			if terminal = g.writeGoUnionCheck(builder, check); terminal {
+				break
+			}
+		}
+		if !terminal {
+			builder.WriteString("\treturn false\n")
+		}
+	}
+	builder.WriteString("}\n\n")
+
+	if g.includeComments {
+		g.writeComment(builder, "MarshalText implements encoding.TextMarshaler", "")
+	}
+	builder.WriteString(fmt.Sprintf("func (v %s) MarshalText() ([]byte, error) {\n", goType.Name))
+	builder.WriteString("\treturn []byte(v), nil\n")
+	builder.WriteString("}\n\n")
+
+	if g.includeComments {
+		g.writeComment(builder, "UnmarshalText implements encoding.TextUnmarshaler", "")
+	}
+	builder.WriteString(fmt.Sprintf("func (v *%s) UnmarshalText(text []byte) error {\n", goType.Name))
+	builder.WriteString(fmt.Sprintf("\t*v = %s(strings.TrimSpace(string(text)))\n", goType.Name))
+	builder.WriteString("\treturn nil\n")
+	builder.WriteString("}\n")
+}
+
+// writeGoUnionCheck writes the test for one member and reports whether it
+// accepts every value, making later members unreachable
+func (g *CodeGenerator) writeGoUnionCheck(builder *strings.Builder, check unionCheck) bool {
+	switch check.kind {
+	case unionCheckEnum:
+		builder.WriteString(fmt.Sprintf("\tswitch s { // %s\n", check.typeName))
+		builder.WriteString(fmt.Sprintf("\tcase %s:\n", strings.Join(check.values, ", ")))
+		builder.WriteString("\t\treturn true\n")
+		builder.WriteString("\t}\n")
+		return false
+	case unionCheckUnion:
+		builder.WriteString(fmt.Sprintf("\tif %s(s).Validate() {\n\t\treturn true\n\t}\n", check.typeName))
+		return false
+	case unionCheckList:
+		builder.WriteString(fmt.Sprintf("\tif list := new(%s); list.UnmarshalText([]byte(s)) == nil && list.Validate() {\n", check.typeName))
+		builder.WriteString("\t\treturn true\n\t}\n")
+		return false
+	case unionCheckRestricted:
+		if check.temporal != "" {
+			builder.WriteString(fmt.Sprintf("\tif t, err := %s(s); err == nil && %s(t).Validate() {\n", temporalParser(check.temporal), check.typeName))
+			builder.WriteString("\t\treturn true\n\t}\n")
+			return false
+		}
+		switch check.category {
+		case valueInteger:
+			builder.WriteString(fmt.Sprintf("\tif n, err := strconv.ParseInt(s, 10, %d); err == nil%s && %s(n).Validate() {\n", check.bits, goZeroBound(check, "n"), check.typeName))
+		case valueUnsigned:
+			builder.WriteString(fmt.Sprintf("\tif n, err := strconv.ParseUint(s, 10, %d); err == nil%s && %s(n).Validate() {\n", check.bits, goZeroBound(check, "n"), check.typeName))
+		case valueDecimal:
+			builder.WriteString(fmt.Sprintf("\tif f, err := strconv.ParseFloat(s, 64); err == nil && isNumberLexical(s, %t) && %s(f).Validate() {\n", !check.decimal, check.typeName))
+		case valueBigDecimal:
+			builder.WriteString(fmt.Sprintf("\tif d, err := ParseDecimal(s); err == nil && %s(d).Validate() {\n", check.typeName))
+		case valueBigInteger:
+			builder.WriteString(fmt.Sprintf("\tif n, err := ParseInteger(s); err == nil%s && %s(n).Validate() {\n", goZeroBound(check, "n.Int().Sign()"), check.typeName))
+		case valueBoolean:
+			builder.WriteString(fmt.Sprintf("\tif b, err := strconv.ParseBool(s); err == nil && %s(b).Validate() {\n", check.typeName))
+		default:
+			builder.WriteString(fmt.Sprintf("\tif %s(s).Validate() {\n", check.typeName))
+		}
+		builder.WriteString("\t\treturn true\n\t}\n")
+		return false
+	}
+
+	builder.WriteString(fmt.Sprintf("\t// %s\n", check.typeName))
+	if check.temporal != "" {
+		builder.WriteString(fmt.Sprintf("\tif _, err := %s(s); err == nil {\n", temporalParser(check.temporal)))
+		builder.WriteString("\t\treturn true\n\t}\n")
+		return false
+	}
+	switch check.category {
+	case valueBoolean:
+		builder.WriteString("\tif s == \"true\" || s == \"false\" || s == \"1\" || s == \"0\" {\n")
+	case valueInteger:
+		builder.WriteString(fmt.Sprintf("\tif %s, err := strconv.ParseInt(s, 10, %d); err == nil%s {\n", goParsedName(check), check.bits, goZeroBound(check, "n")))
+	case valueUnsigned:
+		builder.WriteString(fmt.Sprintf("\tif %s, err := strconv.ParseUint(s, 10, %d); err == nil%s {\n", goParsedName(check), check.bits, goZeroBound(check, "n")))
+	case valueDecimal:
+		builder.WriteString(fmt.Sprintf("\tif isNumberLexical(s, %t) {\n", !check.decimal))
+	case valueBigDecimal:
+		builder.WriteString("\tif _, err := ParseDecimal(s); err == nil {\n")
+	case valueBigInteger:
+		builder.WriteString(fmt.Sprintf("\tif %s, err := ParseInteger(s); err == nil%s {\n", goParsedName(check), goZeroBound(check, "n.Int().Sign()")))
+	default:
+		builder.WriteString("\treturn true\n")
+		return true
+	}
+	builder.WriteString("\t\treturn true\n\t}\n")
+	return false
+}
+
+// goZeroBound returns the Go condition bounding the parsed value by zero,
+// joined with &&, or ""
+func goZeroBound(check unionCheck, value string) string {
+	if bound := check.zeroBound(); bound != "" {
+		return fmt.Sprintf(" && %s %s", value, bound)
+	}
+	return ""
+}
+
+// goParsedName returns the name the parsed value of a built-in member is
+// bound to, which is blank unless it is compared with zero
+func goParsedName(check unionCheck) string {
+	if check.zeroBound() != "" {
+		return "n"
+	}
+	return "_"
+}
+
+// javaParseCalls returns the Java expressions that parse a value of a category
+func javaParseCalls(category string, bits int) []string {
+	switch category {
+	case valueInteger:
+		switch bits {
+		case 8:
+			return []string{"Byte.parseByte(value)"}
+		case 16:
+			return []string{"Short.parseShort(value)"}
+		case 32:
+			return []string{"Integer.parseInt(value)"}
+		}
+		return []string{"Long.parseLong(value)"}
+	case valueUnsigned:
+		if bits == 64 {
+			return []string{"Long.parseUnsignedLong(value)"}
+		}
+		return []string{"Long.parseLong(value)"} // bounded by javaUnsignedMax
+	case valueBigDecimal:
+		return []string{"new BigDecimal(value)"}
+	case valueBigInteger:
+		return []string{"new BigInteger(value)"}
+	case valueDate:
+		return []string{"LocalDate.parse(value)"}
+	case valueTime:
+		return []string{"LocalTime.parse(value)", "OffsetTime.parse(value)"}
+	case valueDateTime:
+		return []string{"LocalDateTime.parse(value)", "OffsetDateTime.parse(value)"}
+	}
+	return nil
+}
+
+// javaZeroBound returns the Java condition bounding the value parsed by call
+// by zero, or ""
+func javaZeroBound(check unionCheck, call string) string {
+	bound := check.zeroBound()
+	switch {
+	case bound == "":
+		return ""
+	case check.category == valueBigInteger:
+		return fmt.Sprintf("%s.signum() %s", call, bound)
+	case check.category == valueUnsigned:
+		return call + " != 0" // parseUnsignedLong wraps large values to negative longs
+	}
+	return call + " " + bound
+}
+
+// javaUnsignedMax returns the largest value of an unsigned type parsed as a
+// Java long, or "" if Long.parseUnsignedLong checks the bounds itself
+func javaUnsignedMax(bits int) string {
+	switch bits {
+	case 8:
+		return "255"
+	case 16:
+		return "65535"
+	case 32:
+		return "4294967295L"
+	}
+	return ""
+}
+
+// javaStringCondition returns the Java condition checking the pattern and
+// length facets of a restricted string type, or "" if it has none.
+// Patterns that cannot be translated are not checked.
+func javaStringCondition(goType *types.GoType) string {
+	conditions := make([]string, 0, 3)
+	if goType.HasPattern {
+		if translated, _ := translatePatterns(goType.Patterns, xsdregex.Java); len(translated) > 0 {
+			conditions = append(conditions, fmt.Sprintf("value.matches(%q)", matchAll(translated)))
+		}
+	}
+	if goType.HasLength {
+		conditions = append(conditions, "value.length() == "+goType.Length)
+	}
+	if goType.HasMinLength {
+		conditions = append(conditions, "value.length() >= "+goType.MinLength)
+	}
+	if goType.HasMaxLength {
+		conditions = append(conditions, "value.length() <= "+goType.MaxLength)
+	}
+	return strings.Join(conditions, " && ")
+}
+
+// writeJavaUnionType writes a Java value class for a union
+func (g *CodeGenerator) writeJavaUnionType(builder *strings.Builder, goType types.GoType) {
+	if g.includeComments && goType.Comment != "" {
+		g.writeComment(builder, fmt.Sprintf("%s %s", goType.Name, goType.Comment), "")
+	} else if g.includeComments {
+		g.writeComment(builder, fmt.Sprintf("%s is a union of %s", goType.Name, unionMemberNames(goType)), "")
+	}
+	builder.WriteString(fmt.Sprintf("public class %s {\n", goType.Name))
+	builder.WriteString("    @XmlValue\n")
+	builder.WriteString("    private String value;\n\n")
+	builder.WriteString(fmt.Sprintf("    public %s() {\n    }\n\n", goType.Name))
+	builder.WriteString(fmt.Sprintf("    public %s(String value) {\n", goType.Name))
+	builder.WriteString("        this.value = value;\n")
+	builder.WriteString("    }\n\n")
+	builder.WriteString("    public String getValue() {\n")
+	builder.WriteString("        return value;\n")
+	builder.WriteString("    }\n\n")
+	builder.WriteString("    public void setValue(String value) {\n")
+	builder.WriteString("        this.value = value;\n")
+	builder.WriteString("    }\n\n")
+
+	// Members are tried in order; the first one accepting the value wins
+	builder.WriteString("    public boolean validate() {\n")
+	builder.WriteString("        if (value == null) {\n")
+	builder.WriteString("            return false;\n")
+	builder.WriteString("        }\n")
+	terminal := false
+	for _, check := range g.unionChecks(goType) {
+		if terminal = g.writeJavaMemberCheck(builder, check); terminal {
+			break
+		}
+	}
+	if !terminal {
+		builder.WriteString("        return false;\n")
+	}
+	builder.WriteString("    }\n")
+	builder.WriteString("}\n")
+}
+
+// writeJavaMemberCheck writes the Java test of the String value against one
+// member type and reports whether it accepts every value
+func (g *CodeGenerator) writeJavaMemberCheck(builder *strings.Builder, check unionCheck) bool {
+	switch {
+	case check.kind == unionCheckEnum:
+		builder.WriteString(fmt.Sprintf("        if (Arrays.asList(%s).contains(value)) {\n", strings.Join(check.values, ", ")))
+		builder.WriteString("            return true;\n")
+		builder.WriteString("        }\n")
+	case check.kind == unionCheckUnion:
+		builder.WriteString(fmt.Sprintf("        if (new %s(value).validate()) {\n", check.typeName))
+		builder.WriteString("            return true;\n")
+		builder.WriteString("        }\n")
+	case check.kind == unionCheckList:
+		builder.WriteString(fmt.Sprintf("        if (%s.isValidText(value)) {\n", check.typeName))
+		builder.WriteString("            return true;\n")
+		builder.WriteString("        }\n")
+	case check.kind == unionCheckRestricted && check.category == valueString && javaStringCondition(check.member) != "":
+		builder.WriteString(fmt.Sprintf("        if (%s) {\n", javaStringCondition(check.member)))
+		builder.WriteString("            return true;\n")
+		builder.WriteString("        }\n")
+	case check.category == valueBoolean:
+		builder.WriteString("        if (value.equals(\"true\") || value.equals(\"false\") || value.equals(\"1\") || value.equals(\"0\")) {\n")
+		builder.WriteString("            return true;\n")
+		builder.WriteString("        }\n")
+	case check.category == valueDecimal:
+		builder.WriteString(fmt.Sprintf("        if (value.matches(%q)) {\n", check.lexical()))
+		builder.WriteString("            return true;\n")
+		builder.WriteString("        }\n")
+	case check.category == valueString:
+		builder.WriteString("        return true;\n")
+		return true
+	default:
+		for _, call := range javaParseCalls(check.category, check.bits) {
+			builder.WriteString("        try {\n")
+			if max := javaUnsignedMax(check.bits); check.category == valueUnsigned && max != "" {
+				builder.WriteString(fmt.Sprintf("            long n = %s;\n", call))
+				builder.WriteString(fmt.Sprintf("            if (n >= 0 && n <= %s) {\n", max))
+				builder.WriteString("                return true;\n")
+				builder.WriteString("            }\n")
+			} else if bound := javaZeroBound(check, call); bound != "" {
+				builder.WriteString(fmt.Sprintf("            if (%s) {\n", bound))
+				builder.WriteString("                return true;\n")
+				builder.WriteString("            }\n")
+			} else {
+				builder.WriteString(fmt.Sprintf("            %s;\n", call))
+				builder.WriteString("            return true;\n")
+			}
+			builder.WriteString("        } catch (RuntimeException e) {\n")
+			builder.WriteString(fmt.Sprintf("            // not a %s\n", check.typeName))
+			builder.WriteString("        }\n")
+		}
+	}
+	return false
+}
+
+// csharpParseCondition returns the C# condition that parses the string
+// expression value as a value of a category
+func csharpParseCondition(category string, bits int, value string) string {
+	const invariant = "System.Globalization.CultureInfo.InvariantCulture"
+	switch category {
+	case valueInteger, valueUnsigned:
+		names := map[int]string{8: "sbyte", 16: "short", 32: "int", 64: "long"}
+		if category == valueUnsigned {
+			names = map[int]string{8: "byte", 16: "ushort", 32: "uint", 64: "ulong"}
+		}
+		return fmt.Sprintf("%s.TryParse(%s, System.Globalization.NumberStyles.Integer, %s, out _)", names[bits], value, invariant)
+	case valueBigDecimal:
+		return fmt.Sprintf("decimal.TryParse(%s, System.Globalization.NumberStyles.Number, %s, out _)", value, invariant)
+	case valueBigInteger:
+		return fmt.Sprintf("System.Numerics.BigInteger.TryParse(%s, System.Globalization.NumberStyles.Integer, %s, out _)", value, invariant)
+	case valueDate, valueTime, valueDateTime:
+		return fmt.Sprintf("DateTime.TryParse(%s, %s, System.Globalization.DateTimeStyles.None, out _)", value, invariant)
+	case valueBoolean:
+		return fmt.Sprintf("%[1]s == \"true\" || %[1]s == \"false\" || %[1]s == \"1\" || %[1]s == \"0\"", value)
+	}
+	return "true"
+}
+
+// csharpZeroBound returns the C# condition bounding the parsed value by
+// zero, or ""
+func csharpZeroBound(check unionCheck, value string) string {
+	const invariant = "System.Globalization.CultureInfo.InvariantCulture"
+	bound := check.zeroBound()
+	switch {
+	case bound == "":
+		return ""
+	case check.category == valueBigInteger:
+		return fmt.Sprintf("System.Numerics.BigInteger.Parse(%s, System.Globalization.NumberStyles.Integer, %s).Sign %s", value, invariant, bound)
+	case check.category == valueUnsigned:
+		return fmt.Sprintf("ulong.Parse(%s, System.Globalization.NumberStyles.Integer, %s) %s", value, invariant, bound)
+	}
+	return fmt.Sprintf("long.Parse(%s, System.Globalization.NumberStyles.Integer, %s) %s", value, invariant, bound)
+}
+
+// writeCSharpUnionType writes a C# value class for a union
+func (g *CodeGenerator) writeCSharpUnionType(builder *strings.Builder, goType types.GoType) {
+	if g.includeComments && goType.Comment != "" {
+		g.writeComment(builder, fmt.Sprintf("%s %s", goType.Name, goType.Comment), "")
+	} else if g.includeComments {
+		g.writeComment(builder, fmt.Sprintf("%s is a union of %s", goType.Name, unionMemberNames(goType)), "")
+	}
+	builder.WriteString(fmt.Sprintf("public class %s\n{\n", goType.Name))
+	builder.WriteString("    [XmlText]\n")
+	builder.WriteString("    public string Value { get; set; }\n\n")
+	builder.WriteString(fmt.Sprintf("    public %s()\n    {\n    }\n\n", goType.Name))
+	builder.WriteString(fmt.Sprintf("    public %s(string value)\n    {\n        Value = value;\n    }\n\n", goType.Name))
+	builder.WriteString("    public override string ToString() => Value;\n\n")
+
+	// Members are tried in order; the first one accepting the value wins
+	builder.WriteString("    public bool Validate()\n    {\n")
+	builder.WriteString("        if (Value == null)\n        {\n            return false;\n        }\n")
+	terminal := false
+	for _, check := range g.unionChecks(goType) {
+		if terminal = g.writeCSharpMemberCheck(builder, check, "Value"); terminal {
+			break
+		}
+	}
+	if !terminal {
+		builder.WriteString("        return false;\n")
+	}
+	builder.WriteString("    }\n")
+	builder.WriteString("}\n")
+}
+
+// writeCSharpMemberCheck writes the C# test of a string value against one
+// member type and reports whether it accepts every value
+func (g *CodeGenerator) writeCSharpMemberCheck(builder *strings.Builder, check unionCheck, value string) bool {
+	switch {
+	case check.kind == unionCheckEnum:
+		builder.WriteString(fmt.Sprintf("        if (Array.IndexOf(new[] { %s }, %s) >= 0)\n", strings.Join(check.values, ", "), value))
+		builder.WriteString("        {\n            return true;\n        }\n")
+	case check.kind == unionCheckUnion:
+		builder.WriteString(fmt.Sprintf("        if (new %s(%s).Validate())\n", check.typeName, value))
+		builder.WriteString("        {\n            return true;\n        }\n")
+	case check.kind == unionCheckList:
+		builder.WriteString(fmt.Sprintf("        if (%s.IsValidText(%s))\n", check.typeName, value))
+		builder.WriteString("        {\n            return true;\n        }\n")
+	case check.kind == unionCheckRestricted && check.category == valueString:
+		// Restricted string types reject invalid values in their constructor
+		builder.WriteString("        try\n        {\n")
+		builder.WriteString(fmt.Sprintf("            new %s(%s);\n", check.typeName, value))
+		builder.WriteString("            return true;\n")
+		builder.WriteString("        }\n        catch (ArgumentException)\n        {\n        }\n")
+	case check.category == valueDecimal:
+		builder.WriteString(fmt.Sprintf("        if (System.Text.RegularExpressions.Regex.IsMatch(%s, @\"^(?:%s)\\z\"))\n", value, check.lexical()))
+		builder.WriteString("        {\n            return true;\n        }\n")
+	case check.category == valueString:
+		builder.WriteString("        return true;\n")
+		return true
+	default:
+		condition := csharpParseCondition(check.category, check.bits, value)
+		if bound := csharpZeroBound(check, value); bound != "" {
+			condition += " && " + bound
+		}
+		builder.WriteString(fmt.Sprintf("        if (%s)\n", condition))
+		builder.WriteString("        {\n            return true;\n        }\n")
+	}
+	return false
+}
+
+// pythonParseCalls returns the Python expressions that parse a value of a category
+func pythonParseCalls(category string) []string {
+	switch category {
+	case valueInteger, valueUnsigned, valueBigInteger:
+		return []string{"int(value)"}
+	case valueDecimal, valueBigDecimal:
+		return []string{"float(value)"}
+	case valueDate:
+		return []string{"date.fromisoformat(value)"}
+	case valueTime:
+		return []string{"time.fromisoformat(value)"}
+	case valueDateTime:
+		return []string{"datetime.fromisoformat(value)"}
+	}
+	return nil
+}
+
+// writePythonUnionType writes a Python str subclass for a union
+func (g *CodeGenerator) writePythonUnionType(builder *strings.Builder, goType types.GoType) {
+	// Add import for re module if a member checks decimal literals
+	for _, check := range g.unionChecks(goType) {
+		if g.checksNumberLexical(check) {
+			builder.WriteString("import re\n")
+			break
+		}
+	}
+	builder.WriteString(fmt.Sprintf("class %s(str):\n", goType.Name))
+	builder.WriteString("    \"\"\"\n")
+	if g.includeComments && goType.Comment != "" {
+		builder.WriteString("    " + goType.Comment + "\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("    Union of %s.\n", unionMemberNames(goType)))
+	}
+	builder.WriteString("    \"\"\"\n\n")
+
+	builder.WriteString("    def validate(self):\n")
+	builder.WriteString("        \"\"\"Reports whether the value matches one of the member types, tried in order.\"\"\"\n")
+	builder.WriteString("        value = str(self)\n")
+	terminal := false
+	for _, check := range g.unionChecks(goType) {
+		switch {
+		case check.kind == unionCheckEnum:
+			builder.WriteString(fmt.Sprintf("        if value in (%s,):\n", strings.Join(check.values, ", ")))
+			builder.WriteString("            return True\n")
+		case check.kind == unionCheckUnion:
+			builder.WriteString(fmt.Sprintf("        if %s(value).validate():\n", check.typeName))
+			builder.WriteString("            return True\n")
+		case check.kind == unionCheckList:
+			builder.WriteString("        try:\n")
+			builder.WriteString(fmt.Sprintf("            if %s.from_text(value).validate():\n", check.typeName))
+			builder.WriteString("                return True\n")
+			builder.WriteString("        except ValueError:\n")
+			builder.WriteString("            pass\n")
+		case check.kind == unionCheckRestricted:
+			// Restricted types reject invalid values when constructed
+			builder.WriteString("        try:\n")
+			for _, call := range pythonParseCalls(check.category) {
+				builder.WriteString(fmt.Sprintf("            %s\n", call))
+			}
+			builder.WriteString(fmt.Sprintf("            %s(value)\n", check.typeName))
+			if g.checksNumberLexical(check) {
+				builder.WriteString(fmt.Sprintf("            if re.fullmatch(r\"%s\", value):\n", check.lexical()))
+				builder.WriteString("                return True\n")
+			} else if check.sign != "" {
+				builder.WriteString(fmt.Sprintf("            if int(value) %s:\n", check.sign))
+				builder.WriteString("                return True\n")
+			} else {
+				builder.WriteString("            return True\n")
+			}
+			builder.WriteString("        except ValueError:\n")
+			builder.WriteString("            pass\n")
+		case check.category == valueBoolean:
+			builder.WriteString("        if value in (\"true\", \"false\", \"1\", \"0\"):\n")
+			builder.WriteString("            return True\n")
+		case check.category == valueDecimal:
+			builder.WriteString(fmt.Sprintf("        if re.fullmatch(r\"%s\", value):\n", check.lexical()))
+			builder.WriteString("            return True\n")
+		case check.category == valueString:
+			builder.WriteString("        return True\n")
+			terminal = true
+		default:
+			for _, call := range pythonParseCalls(check.category) {
+				builder.WriteString("        try:\n")
+				switch {
+				case check.sign != "":
+					builder.WriteString(fmt.Sprintf("            if %s %s:\n", call, check.sign))
+					builder.WriteString("                return True\n")
+				case check.category == valueUnsigned:
+					builder.WriteString(fmt.Sprintf("            %s\n", call))
+					builder.WriteString("            if not value.lstrip().startswith(\"-\"):\n")
+					builder.WriteString("                return True\n")
+				default:
+					builder.WriteString(fmt.Sprintf("            %s\n", call))
+					builder.WriteString("            return True\n")
+				}
+				builder.WriteString("        except ValueError:\n")
+				builder.WriteString("            pass\n")
+			}
+		}
+		if terminal {
+			break
+		}
+	}
+	if !terminal {
+		builder.WriteString("        return False\n")
+	}
+}
@@ -0,0 +1,236 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/xsd"
+)
+
+// facet is the value of a single-valued facet and whether it is fixed
+type facet struct {
+	value string
+	fixed bool
+}
+
+// facetNames lists the single-valued facets in the order they are checked
+var facetNames = []string{
+	"length", "minLength", "maxLength",
+	"minInclusive", "maxInclusive", "minExclusive", "maxExclusive",
+	"totalDigits", "fractionDigits", "whiteSpace",
+}
+
+// singleFacets returns the single-valued facets a restriction sets
+func singleFacets(restriction *XSDRestriction) map[string]facet {
+	facets := make(map[string]facet)
+	add := func(name, value, fixed string) {
+		facets[name] = facet{value: value, fixed: fixed == "true" || fixed == "1"}
+	}
+	if restriction.Length != nil {
+		add("length", restriction.Length.Value, restriction.Length.Fixed)
+	}
+	if restriction.MinLength != nil {
+		add("minLength", restriction.MinLength.Value, restriction.MinLength.Fixed)
+	}
+	if restriction.MaxLength != nil {
+		add("maxLength", restriction.MaxLength.Value, restriction.MaxLength.Fixed)
+	}
+	if restriction.MinInclusive != nil {
+		add("minInclusive", restriction.MinInclusive.Value, restriction.MinInclusive.Fixed)
+	}
+	if restriction.MaxInclusive != nil {
+		add("maxInclusive", restriction.MaxInclusive.Value, restriction.MaxInclusive.Fixed)
+	}
+	if restriction.MinExclusive != nil {
+		add("minExclusive", restriction.MinExclusive.Value, restriction.MinExclusive.Fixed)
+	}
+	if restriction.MaxExclusive != nil {
+		add("maxExclusive", restriction.MaxExclusive.Value, restriction.MaxExclusive.Fixed)
+	}
+	if restriction.TotalDigits != nil {
+		add("totalDigits", restriction.TotalDigits.Value, restriction.TotalDigits.Fixed)
+	}
+	if restriction.FractionDigits != nil {
+		add("fractionDigits", restriction.FractionDigits.Value, restriction.FractionDigits.Fixed)
+	}
+	if restriction.WhiteSpace != nil {
+		add("whiteSpace", restriction.WhiteSpace.Value, restriction.WhiteSpace.Fixed)
+	}
+	return facets
+}
+
+// setFacet stores a single-valued facet in a restriction
+func setFacet(restriction *XSDRestriction, name string, f facet) {
+	fixed := ""
+	if f.fixed {
+		fixed = "true"
+	}
+	switch name {
+	case "length":
+		restriction.Length = &XSDLength{Value: f.value, Fixed: fixed}
+	case "minLength":
+		restriction.MinLength = &XSDMinLength{Value: f.value, Fixed: fixed}
+	case "maxLength":
+		restriction.MaxLength = &XSDMaxLength{Value: f.value, Fixed: fixed}
+	case "minInclusive":
+		restriction.MinInclusive = &XSDMinInclusive{Value: f.value, Fixed: fixed}
+	case "maxInclusive":
+		restriction.MaxInclusive = &XSDMaxInclusive{Value: f.value, Fixed: fixed}
+	case "minExclusive":
+		restriction.MinExclusive = &XSDMinExclusive{Value: f.value, Fixed: fixed}
+	case "maxExclusive":
+		restriction.MaxExclusive = &XSDMaxExclusive{Value: f.value, Fixed: fixed}
+	case "totalDigits":
+		restriction.TotalDigits = &XSDTotalDigits{Value: f.value, Fixed: fixed}
+	case "fractionDigits":
+		restriction.FractionDigits = &XSDFractionDigits{Value: f.value, Fixed: fixed}
+	case "whiteSpace":
+		restriction.WhiteSpace = &XSDWhiteSpace{Value: f.value, Fixed: fixed}
+	}
+}
+
+// whiteSpaceOrder ranks whiteSpace values from least to most normalizing
+var whiteSpaceOrder = map[string]int{"preserve": 0, "replace": 1, "collapse": 2}
+
+// facetLimits maps each facet of a derived type to the facets of the base
+// type that limit it, with the results of comparing the derived value to
+// the base value that keep within the base type's value space
+var facetLimits = map[string]map[string][]int{
+	"length":         {"length": {0}, "minLength": {0, 1}, "maxLength": {-1, 0}},
+	"minLength":      {"minLength": {0, 1}, "maxLength": {-1, 0}},
+	"maxLength":      {"maxLength": {-1, 0}, "minLength": {0, 1}},
+	"minInclusive":   {"minInclusive": {0, 1}, "minExclusive": {1}},
+	"minExclusive":   {"minExclusive": {0, 1}, "minInclusive": {0, 1}},
+	"maxInclusive":   {"maxInclusive": {-1, 0}, "maxExclusive": {-1}},
+	"maxExclusive":   {"maxExclusive": {-1, 0}, "maxInclusive": {-1, 0}},
+	"totalDigits":    {"totalDigits": {-1, 0}},
+	"fractionDigits": {"fractionDigits": {-1, 0}},
+	"whiteSpace":     {"whiteSpace": {0, 1}},
+}
+
+// compareFacet orders two values of a facet: whiteSpace from least to most
+// normalizing, the bounds of date, time and duration types in their value
+// space and other values as numbers. It reports false for values it cannot
+// order, such as the bounds of other non-numeric types.
+func compareFacet(name, primitive, a, b string) (int, bool) {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if name == "whiteSpace" {
+		x, okA := whiteSpaceOrder[a]
+		y, okB := whiteSpaceOrder[b]
+		return x - y, okA && okB
+	}
+	if strings.HasSuffix(name, "clusive") && xsd.IsType(primitive) {
+		x, err := xsd.Parse(primitive, a)
+		if err != nil {
+			return 0, false
+		}
+		y, err := xsd.Parse(primitive, b)
+		if err != nil {
+			return 0, false
+		}
+		order := xsd.Compare(x, y)
+		return order, order != xsd.Indeterminate
+	}
+	x, ok := new(big.Rat).SetString(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := new(big.Rat).SetString(b)
+	if !ok {
+		return 0, false
+	}
+	return x.Cmp(y), true
+}
+
+// sameFacet reports whether two values of a facet are equal, comparing
+// values that cannot be ordered by their text
+func sameFacet(name, primitive, a, b string) bool {
+	if order, ok := compareFacet(name, primitive, a, b); ok {
+		return order == 0
+	}
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
+
+// narrows checks a facet a derived type sets against the facets of its base
+// types that limit it. Values that cannot be ordered are not checked.
+func narrows(name, value, primitive string, base map[string]facet) error {
+	for _, baseName := range facetNames {
+		orders, limited := facetLimits[name][baseName]
+		limit, exists := base[baseName]
+		if !limited || !exists {
+			continue
+		}
+		order, ok := compareFacet(name, primitive, value, limit.value)
+		if !ok {
+			continue
+		}
+		if order > 0 {
+			order = 1
+		} else if order < 0 {
+			order = -1
+		}
+		if !containsOrder(orders, order) {
+			return fmt.Errorf("facet %s=%s widens %s=%s of the base type", name, value, baseName, limit.value)
+		}
+	}
+	return nil
+}
+
+// containsOrder reports whether a comparison result is among orders
+func containsOrder(orders []int, order int) bool {
+	for _, o := range orders {
+		if o == order {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveFacets merges the single-valued facets of a restriction chain,
+// most derived first, into one restriction in which each facet has the
+// value of the most derived step that sets it. Patterns and enumerations
+// constrain each step on their own and are left out. A step may not change
+// a facet a base step fixes, nor widen the length, digit, bound or
+// whiteSpace facets of its base; values are compared in the value space of
+// the built-in type the chain derives from.
+func EffectiveFacets(chain []*XSDRestriction) (XSDRestriction, error) {
+	primitive := ""
+	if len(chain) > 0 {
+		primitive = ParseQName(chain[len(chain)-1].Base).Local
+	}
+
+	merged := make(map[string]facet)
+	for i := len(chain) - 1; i >= 0; i-- {
+		step := singleFacets(chain[i])
+		for _, name := range facetNames {
+			f, exists := step[name]
+			if !exists {
+				continue
+			}
+			if base, exists := merged[name]; exists && base.fixed && !sameFacet(name, primitive, f.value, base.value) {
+				return XSDRestriction{}, fmt.Errorf("facet %s is fixed to %s by the base type and cannot be changed to %s", name, base.value, f.value)
+			}
+			if err := narrows(name, f.value, primitive, merged); err != nil {
+				return XSDRestriction{}, err
+			}
+		}
+		for name, f := range step {
+			if base, exists := merged[name]; exists {
+				f.fixed = f.fixed || base.fixed
+			}
+			merged[name] = f
+		}
+	}
+
+	var result XSDRestriction
+	if len(chain) > 0 {
+		result.Base = chain[0].Base
+	}
+	for _, name := range facetNames {
+		if f, exists := merged[name]; exists {
+			setFacet(&result, name, f)
+		}
+	}
+	return result, nil
+}
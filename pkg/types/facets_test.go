@@ -0,0 +1,146 @@
+package types
+
+import "testing"
+
+func TestEffectiveFacets(t *testing.T) {
+	// Chains are given from the built-in base up to the most derived step
+	tests := []struct {
+		name  string
+		base  string
+		steps []XSDRestriction
+		check func(XSDRestriction) bool
+		err   string
+	}{
+		{
+			name: "same value restated",
+			base: "xs:decimal",
+			steps: []XSDRestriction{
+				{MinInclusive: &XSDMinInclusive{Value: "0", Fixed: "true"}},
+				{MinInclusive: &XSDMinInclusive{Value: " +0.0"}},
+			},
+			check: func(r XSDRestriction) bool { return r.MinInclusive.Value == " +0.0" && r.MinInclusive.Fixed == "true" },
+		},
+		{
+			name: "same date restated",
+			base: "xs:date",
+			steps: []XSDRestriction{
+				{MaxInclusive: &XSDMaxInclusive{Value: "2024-01-01Z", Fixed: "true"}},
+				{MaxInclusive: &XSDMaxInclusive{Value: "2024-01-01+00:00"}},
+			},
+			check: func(r XSDRestriction) bool { return r.MaxInclusive.Value == "2024-01-01+00:00" },
+		},
+		{
+			name: "fixed facet overridden",
+			base: "xs:decimal",
+			steps: []XSDRestriction{
+				{MinInclusive: &XSDMinInclusive{Value: "0", Fixed: "true"}},
+				{MinInclusive: &XSDMinInclusive{Value: "1"}},
+			},
+			err: "facet minInclusive is fixed to 0 by the base type and cannot be changed to 1",
+		},
+		{
+			name: "fixed whiteSpace overridden",
+			base: "xs:string",
+			steps: []XSDRestriction{
+				{WhiteSpace: &XSDWhiteSpace{Value: "replace", Fixed: "true"}},
+				{},
+				{WhiteSpace: &XSDWhiteSpace{Value: "collapse"}},
+			},
+			err: "facet whiteSpace is fixed to replace by the base type and cannot be changed to collapse",
+		},
+		{
+			name: "narrowed",
+			base: "xs:int",
+			steps: []XSDRestriction{
+				{MinInclusive: &XSDMinInclusive{Value: "0"}, MaxInclusive: &XSDMaxInclusive{Value: "100"}},
+				{MaxInclusive: &XSDMaxInclusive{Value: "50"}},
+			},
+			check: func(r XSDRestriction) bool { return r.MinInclusive.Value == "0" && r.MaxInclusive.Value == "50" },
+		},
+		{
+			name: "widened",
+			base: "xs:int",
+			steps: []XSDRestriction{
+				{MaxInclusive: &XSDMaxInclusive{Value: "100"}},
+				{MaxInclusive: &XSDMaxInclusive{Value: "1E3"}},
+			},
+			err: "facet maxInclusive=1E3 widens maxInclusive=100 of the base type",
+		},
+		{
+			name: "widened date",
+			base: "xs:date",
+			steps: []XSDRestriction{
+				{MinInclusive: &XSDMinInclusive{Value: "2024-01-01Z"}},
+				{MinInclusive: &XSDMinInclusive{Value: "2023-12-31Z"}},
+			},
+			err: "facet minInclusive=2023-12-31Z widens minInclusive=2024-01-01Z of the base type",
+		},
+		{
+			name: "inclusive bound on an exclusive one",
+			base: "xs:decimal",
+			steps: []XSDRestriction{
+				{MinExclusive: &XSDMinExclusive{Value: "0"}},
+				{MinInclusive: &XSDMinInclusive{Value: "0"}},
+			},
+			err: "facet minInclusive=0 widens minExclusive=0 of the base type",
+		},
+		{
+			name: "exclusive bound on an inclusive one",
+			base: "xs:decimal",
+			steps: []XSDRestriction{
+				{MaxInclusive: &XSDMaxInclusive{Value: "10"}},
+				{MaxExclusive: &XSDMaxExclusive{Value: "10"}},
+			},
+			check: func(r XSDRestriction) bool { return r.MaxInclusive.Value == "10" && r.MaxExclusive.Value == "10" },
+		},
+		{
+			name: "inclusive bound past an exclusive one",
+			base: "xs:decimal",
+			steps: []XSDRestriction{
+				{MaxExclusive: &XSDMaxExclusive{Value: "10"}},
+				{MaxInclusive: &XSDMaxInclusive{Value: "10.5"}},
+			},
+			err: "facet maxInclusive=10.5 widens maxExclusive=10 of the base type",
+		},
+		{
+			name: "length outside the base lengths",
+			base: "xs:string",
+			steps: []XSDRestriction{
+				{MinLength: &XSDMinLength{Value: "2"}, MaxLength: &XSDMaxLength{Value: "8"}},
+				{Length: &XSDLength{Value: "9"}},
+			},
+			err: "facet length=9 widens maxLength=8 of the base type",
+		},
+		{
+			name: "whiteSpace relaxed",
+			base: "xs:string",
+			steps: []XSDRestriction{
+				{WhiteSpace: &XSDWhiteSpace{Value: "collapse"}},
+				{WhiteSpace: &XSDWhiteSpace{Value: "preserve"}},
+			},
+			err: "facet whiteSpace=preserve widens whiteSpace=collapse of the base type",
+		},
+	}
+	for _, test := range tests {
+		chain := make([]*XSDRestriction, len(test.steps))
+		for i := range test.steps {
+			step := test.steps[i]
+			step.Base = test.base
+			chain[len(chain)-1-i] = &step
+		}
+		got, err := EffectiveFacets(chain)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: error = %v, want %s", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: error = %v", test.name, err)
+			continue
+		}
+		if !test.check(got) {
+			t.Errorf("%s: merged facets %+v", test.name, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IdentityPath is one alternative of a selector or field XPath in the
+// restricted subset identity constraints allow. Names are matched by local
+// name; "*" matches any element or attribute.
+type IdentityPath struct {
+	Descendant bool     // the path starts with .//
+	Steps      []string // child element steps, "." steps removed
+	Attribute  string   // final @name step of a field path, if any
+}
+
+// ParseIdentityXPath parses a selector or field XPath into its alternatives.
+// Only fields may end in an attribute step.
+func ParseIdentityXPath(xpath string, field bool) ([]IdentityPath, error) {
+	alternatives := strings.Split(xpath, "|")
+	paths := make([]IdentityPath, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		path, err := parseIdentityPath(strings.TrimSpace(alternative), field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xpath %q: %v", xpath, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// parseIdentityPath parses a single path of the identity constraint subset
+func parseIdentityPath(value string, field bool) (IdentityPath, error) {
+	var path IdentityPath
+	if value == "" {
+		return path, fmt.Errorf("empty path")
+	}
+	if strings.HasPrefix(value, ".//") {
+		path.Descendant = true
+		value = value[len(".//"):]
+	}
+
+	steps := strings.Split(value, "/")
+	for i, step := range steps {
+		step = strings.TrimSpace(step)
+		last := i == len(steps)-1
+		switch {
+		case step == "":
+			return path, fmt.Errorf("empty step")
+		case step == ".":
+			continue
+		case strings.HasPrefix(step, "@") || strings.HasPrefix(step, "attribute::"):
+			if !field || !last {
+				return path, fmt.Errorf("attribute step %q is only allowed at the end of a field", step)
+			}
+			step = strings.TrimPrefix(strings.TrimPrefix(step, "@"), "attribute::")
+			path.Attribute = identityNameTest(strings.TrimSpace(step))
+		default:
+			step = strings.TrimSpace(strings.TrimPrefix(step, "child::"))
+			if strings.ContainsAny(step, "[]()=") {
+				return path, fmt.Errorf("unsupported step %q", step)
+			}
+			path.Steps = append(path.Steps, identityNameTest(step))
+		}
+	}
+	return path, nil
+}
+
+// identityNameTest reduces a name test to the local name it matches, or "*"
+func identityNameTest(test string) string {
+	if test == "*" || strings.HasSuffix(test, ":*") {
+		return "*"
+	}
+	return LocalName(test)
+}
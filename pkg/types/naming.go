@@ -0,0 +1,202 @@
+package types
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// NameKind classifies the identifiers handed out by a NamingRegistry
+type NameKind string
+
+const (
+	NameType      NameKind = "type"      // named, anonymous and derived types
+	NameElement   NameKind = "element"   // types of global elements
+	NameConstant  NameKind = "constant"  // enumeration constants
+	NameField     NameKind = "field"     // element and text fields
+	NameAttribute NameKind = "attribute" // attribute fields
+)
+
+// NameSuffixes holds the suffix appended to each kind of name that collides
+// with a name already in use or with a Go keyword
+type NameSuffixes map[NameKind]string
+
+// DefaultNameSuffixes returns the suffixes used unless configured otherwise
+func DefaultNameSuffixes() NameSuffixes {
+	return NameSuffixes{
+		NameType:      "Type",
+		NameElement:   "Element",
+		NameConstant:  "Value",
+		NameField:     "Field",
+		NameAttribute: "Attr",
+	}
+}
+
+// ParseNameSuffixes parses a comma separated list of kind=suffix pairs, e.g.
+// "element=Elem,attribute=Attribute". Kinds not listed keep their default.
+func ParseNameSuffixes(spec string) (NameSuffixes, error) {
+	suffixes := DefaultNameSuffixes()
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kind, suffix, found := strings.Cut(pair, "=")
+		kind, suffix = strings.TrimSpace(kind), strings.TrimSpace(suffix)
+		if _, known := suffixes[NameKind(kind)]; !found || !known {
+			return nil, fmt.Errorf("invalid name suffix %q (want kind=suffix with kind one of type, element, constant, field, attribute)", pair)
+		}
+		if suffix == "" || !token.IsIdentifier("X"+suffix) {
+			return nil, fmt.Errorf("invalid name suffix %q for %s", suffix, kind)
+		}
+		suffixes[NameKind(kind)] = suffix
+	}
+	return suffixes, nil
+}
+
+// NameChange records a generated name that differs from the one derived
+// from the schema
+type NameChange struct {
+	Kind     NameKind
+	Scope    string // empty for package-level names, else the owning type
+	Original string
+	Name     string
+	Reason   string
+}
+
+// String formats a change for reports
+func (c NameChange) String() string {
+	name := c.Original
+	if c.Scope != "" {
+		name = c.Scope + "." + name
+	}
+	return fmt.Sprintf("%s %s -> %s (%s)", c.Kind, name, c.Name, c.Reason)
+}
+
+// NamingRegistry hands out unique Go identifiers within one scope, such as
+// the package or the fields of a struct. Names that are not valid Go
+// identifiers are cleaned up, and names that collide with a keyword or a
+// name already in use get the suffix of their kind, numbered if needed.
+type NamingRegistry struct {
+	scope    string
+	suffixes NameSuffixes
+	used     map[string]bool
+	claimed  map[string]string
+	changes  *[]NameChange
+}
+
+// NewNamingRegistry creates a package-level registry. Missing suffixes fall
+// back to the defaults.
+func NewNamingRegistry(suffixes NameSuffixes) *NamingRegistry {
+	merged := DefaultNameSuffixes()
+	for kind, suffix := range suffixes {
+		merged[kind] = suffix
+	}
+	return &NamingRegistry{
+		suffixes: merged,
+		used:     make(map[string]bool),
+		claimed:  make(map[string]string),
+		changes:  new([]NameChange),
+	}
+}
+
+// Scope returns an empty registry for a nested scope that shares the
+// suffixes and the change log
+func (r *NamingRegistry) Scope(scope string) *NamingRegistry {
+	return &NamingRegistry{
+		scope:    scope,
+		suffixes: r.suffixes,
+		used:     make(map[string]bool),
+		claimed:  make(map[string]string),
+		changes:  r.changes,
+	}
+}
+
+// Reserve marks names as used without recording them as claimed
+func (r *NamingRegistry) Reserve(names ...string) {
+	for _, name := range names {
+		r.used[name] = true
+	}
+}
+
+// Used reports whether a name is reserved or claimed
+func (r *NamingRegistry) Used(name string) bool {
+	return r.used[name]
+}
+
+// Claim returns a unique identifier for name and marks it as used. Claims
+// with the same non-empty key return the first result, so a component that
+// is converted more than once keeps its name.
+func (r *NamingRegistry) Claim(kind NameKind, key, name string) string {
+	if key != "" {
+		if claimed, exists := r.claimed[key]; exists {
+			return claimed
+		}
+	}
+
+	candidate, reason := SafeIdentifier(name), ""
+	if candidate != name {
+		reason = "not a valid Go identifier"
+	}
+	if token.IsKeyword(candidate) {
+		candidate += r.suffixes[kind]
+		reason = "Go keyword"
+	}
+	if r.used[candidate] {
+		base := candidate + r.suffixes[kind]
+		candidate = base
+		for i := 2; r.used[candidate]; i++ {
+			candidate = fmt.Sprintf("%s%d", base, i)
+		}
+		reason = "collides with an existing name"
+	}
+
+	r.used[candidate] = true
+	if key != "" {
+		r.claimed[key] = candidate
+	}
+	if candidate != name {
+		*r.changes = append(*r.changes, NameChange{Kind: kind, Scope: r.scope, Original: name, Name: candidate, Reason: reason})
+	}
+	return candidate
+}
+
+// Changes returns every rename made by the registry and its scopes, package
+// names first and then by scope, in the order they were made
+func (r *NamingRegistry) Changes() []NameChange {
+	changes := append([]NameChange(nil), *r.changes...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Scope < changes[j].Scope
+	})
+	return changes
+}
+
+// SafeIdentifier turns a name into a valid Go identifier. Characters that
+// cannot appear in identifiers are dropped and the letter following them is
+// capitalized; a leading digit gets an X prefix.
+func SafeIdentifier(name string) string {
+	var builder strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			builder.WriteRune(r)
+			upper = false
+		default:
+			upper = builder.Len() > 0
+		}
+	}
+	result := builder.String()
+	if result == "" {
+		return "X"
+	}
+	if unicode.IsDigit([]rune(result)[0]) {
+		return "X" + result
+	}
+	return result
+}
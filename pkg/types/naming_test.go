@@ -0,0 +1,135 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamingRegistry(t *testing.T) {
+	// Each claim is kind, key and name; want holds the claimed names
+	type claim struct {
+		kind      NameKind
+		key, name string
+	}
+	tests := []struct {
+		name    string
+		claims  []claim
+		want    []string
+		changes int
+	}{
+		{
+			name:   "distinct names",
+			claims: []claim{{NameType, "", "Order"}, {NameType, "", "Item"}},
+			want:   []string{"Order", "Item"},
+		},
+		{
+			name:    "collisions numbered after the suffix",
+			claims:  []claim{{NameType, "", "Order"}, {NameElement, "", "Order"}, {NameElement, "", "Order"}},
+			want:    []string{"Order", "OrderElement", "OrderElement2"},
+			changes: 2,
+		},
+		{
+			name:    "reserved names",
+			claims:  []claim{{NameField, "", "XMLName"}},
+			want:    []string{"XMLNameField"},
+			changes: 1,
+		},
+		{
+			name:    "Go keyword",
+			claims:  []claim{{NameConstant, "", "type"}},
+			want:    []string{"typeValue"},
+			changes: 1,
+		},
+		{
+			name:    "invalid identifier",
+			claims:  []claim{{NameType, "", "a-b.c"}, {NameType, "", "1st"}},
+			want:    []string{"aBC", "X1st"},
+			changes: 2,
+		},
+		{
+			name:    "cleaned up names colliding",
+			claims:  []claim{{NameConstant, "", "ColorAB"}, {NameConstant, "", "Color-AB"}},
+			want:    []string{"ColorAB", "ColorABValue"},
+			changes: 1,
+		},
+		{
+			name:   "same key claimed again",
+			claims: []claim{{NameType, "type Order", "Order"}, {NameType, "type Order", "Order"}},
+			want:   []string{"Order", "Order"},
+		},
+	}
+	for _, test := range tests {
+		registry := NewNamingRegistry(nil)
+		registry.Reserve("XMLName")
+		got := make([]string, len(test.claims))
+		for i, c := range test.claims {
+			got[i] = registry.Claim(c.kind, c.key, c.name)
+		}
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%s: claimed %v, want %v", test.name, got, test.want)
+		}
+		if changes := registry.Changes(); len(changes) != test.changes {
+			t.Errorf("%s: changes %v, want %d", test.name, changes, test.changes)
+		}
+	}
+}
+
+func TestNamingRegistryScopes(t *testing.T) {
+	registry := NewNamingRegistry(NameSuffixes{NameField: "F"})
+	registry.Claim(NameType, "", "Order")
+	fields := registry.Scope("Order")
+	tests := []struct {
+		kind NameKind
+		name string
+		want string
+	}{
+		{NameField, "Order", "Order"},
+		{NameField, "Id", "Id"},
+		{NameAttribute, "Id", "IdAttr"},
+		{NameField, "Id", "IdF"},
+	}
+	for _, test := range tests {
+		if got := fields.Claim(test.kind, "", test.name); got != test.want {
+			t.Errorf("Claim(%s, %s) = %s, want %s", test.kind, test.name, got, test.want)
+		}
+	}
+
+	changes := registry.Changes()
+	if len(changes) != 2 || changes[0].String() != "attribute Order.Id -> IdAttr (collides with an existing name)" {
+		t.Errorf("changes %v", changes)
+	}
+}
+
+func TestParseNameSuffixes(t *testing.T) {
+	tests := []struct {
+		spec string
+		want NameSuffixes
+		err  bool
+	}{
+		{"", DefaultNameSuffixes(), false},
+		{"element=Elem, attribute=Attribute", NameSuffixes{NameType: "Type", NameElement: "Elem", NameConstant: "Value", NameField: "Field", NameAttribute: "Attribute"}, false},
+		{"field=_", NameSuffixes{NameType: "Type", NameElement: "Element", NameConstant: "Value", NameField: "_", NameAttribute: "Attr"}, false},
+		{"method=M", nil, true},
+		{"type", nil, true},
+		{"type=", nil, true},
+		{"type=a-b", nil, true},
+	}
+	for _, test := range tests {
+		got, err := ParseNameSuffixes(test.spec)
+		if test.err {
+			if err == nil {
+				t.Errorf("ParseNameSuffixes(%q) = %v, want an error", test.spec, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseNameSuffixes(%q): %v", test.spec, err)
+			continue
+		}
+		for kind, suffix := range test.want {
+			if got[kind] != suffix {
+				t.Errorf("ParseNameSuffixes(%q)[%s] = %q, want %q", test.spec, kind, got[kind], suffix)
+			}
+		}
+	}
+}
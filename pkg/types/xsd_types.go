@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/xml"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -14,6 +15,7 @@ type XSDSchema struct {
 	AttributeFormDefault string              `xml:"attributeFormDefault,attr"`
 	Xmlns                map[string]string   `xml:"-"`
 	Elements             []XSDElement        `xml:"element"`
+	Attributes           []XSDAttribute      `xml:"attribute"`
 	ComplexTypes         []XSDComplexType    `xml:"complexType"`
 	SimpleTypes          []XSDSimpleType     `xml:"simpleType"`
 	Groups               []XSDGroup          `xml:"group"`
@@ -25,18 +27,55 @@ type XSDSchema struct {
 
 // XSDElement represents an XSD element
 type XSDElement struct {
-	XMLName     xml.Name        `xml:"element"`
-	Name        string          `xml:"name,attr"`
-	Type        string          `xml:"type,attr"`
-	Ref         string          `xml:"ref,attr"`
-	MinOccurs   string          `xml:"minOccurs,attr"`
-	MaxOccurs   string          `xml:"maxOccurs,attr"`
-	Default     string          `xml:"default,attr"`
-	Fixed       string          `xml:"fixed,attr"`
-	Nillable    string          `xml:"nillable,attr"`
-	ComplexType *XSDComplexType `xml:"complexType"`
-	SimpleType  *XSDSimpleType  `xml:"simpleType"`
-	Annotation  *XSDAnnotation  `xml:"annotation"`
+	XMLName           xml.Name        `xml:"element"`
+	Name              string          `xml:"name,attr"`
+	Type              string          `xml:"type,attr"`
+	Ref               string          `xml:"ref,attr"`
+	MinOccurs         string          `xml:"minOccurs,attr"`
+	MaxOccurs         string          `xml:"maxOccurs,attr"`
+	Default           string          `xml:"default,attr"`
+	Fixed             string          `xml:"fixed,attr"`
+	Nillable          string          `xml:"nillable,attr"`
+	Abstract          string          `xml:"abstract,attr"`
+	SubstitutionGroup string          `xml:"substitutionGroup,attr"`
+	ComplexType       *XSDComplexType `xml:"complexType"`
+	SimpleType        *XSDSimpleType  `xml:"simpleType"`
+	Annotation        *XSDAnnotation  `xml:"annotation"`
+
+	// Identity constraints scoped to this element
+	Keys    []XSDIdentityConstraint `xml:"key"`
+	KeyRefs []XSDIdentityConstraint `xml:"keyref"`
+	Uniques []XSDIdentityConstraint `xml:"unique"`
+}
+
+// XSDIdentityConstraint represents an xs:key, xs:keyref or xs:unique
+// constraint; XMLName.Local tells which
+type XSDIdentityConstraint struct {
+	XMLName    xml.Name
+	Name       string         `xml:"name,attr"`
+	Refer      string         `xml:"refer,attr"` // referenced key of a keyref
+	Selector   XSDSelector    `xml:"selector"`
+	Fields     []XSDField     `xml:"field"`
+	Annotation *XSDAnnotation `xml:"annotation"`
+}
+
+// XSDSelector represents the xs:selector of an identity constraint
+type XSDSelector struct {
+	XPath string `xml:"xpath,attr"`
+}
+
+// XSDField represents an xs:field of an identity constraint
+type XSDField struct {
+	XPath string `xml:"xpath,attr"`
+}
+
+// IdentityConstraints returns the keys and unique constraints of an element
+// followed by its keyrefs
+func (e XSDElement) IdentityConstraints() []XSDIdentityConstraint {
+	constraints := make([]XSDIdentityConstraint, 0, len(e.Keys)+len(e.Uniques)+len(e.KeyRefs))
+	constraints = append(constraints, e.Keys...)
+	constraints = append(constraints, e.Uniques...)
+	return append(constraints, e.KeyRefs...)
 }
 
 // XSDComplexType represents an XSD complex type
@@ -51,6 +90,7 @@ type XSDComplexType struct {
 	Group           *XSDGroupRef           `xml:"group"`
 	Attributes      []XSDAttribute         `xml:"attribute"`
 	AttributeGroups []XSDAttributeGroupRef `xml:"attributeGroup"`
+	AnyAttribute    *XSDAnyAttribute       `xml:"anyAttribute"`
 	SimpleContent   *XSDSimpleContent      `xml:"simpleContent"`
 	ComplexContent  *XSDComplexContent     `xml:"complexContent"`
 	Annotation      *XSDAnnotation         `xml:"annotation"`
@@ -75,6 +115,59 @@ type XSDSequence struct {
 	Groups    []XSDGroupRef `xml:"group"`
 	Choices   []XSDChoice   `xml:"choice"`
 	Sequences []XSDSequence `xml:"sequence"`
+	Any       []XSDAny      `xml:"any"`
+}
+
+// UnmarshalXML decodes a sequence, recording where each wildcard stands
+// among the element particles, which the separate slices lose
+func (s *XSDSequence) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	s.XMLName = start.Name
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "minOccurs":
+			s.MinOccurs = attr.Value
+		case "maxOccurs":
+			s.MaxOccurs = attr.Value
+		}
+	}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "element":
+				var element XSDElement
+				err = d.DecodeElement(&element, &t)
+				s.Elements = append(s.Elements, element)
+			case "group":
+				var group XSDGroupRef
+				err = d.DecodeElement(&group, &t)
+				s.Groups = append(s.Groups, group)
+			case "choice":
+				var choice XSDChoice
+				err = d.DecodeElement(&choice, &t)
+				s.Choices = append(s.Choices, choice)
+			case "sequence":
+				var sequence XSDSequence
+				err = d.DecodeElement(&sequence, &t)
+				s.Sequences = append(s.Sequences, sequence)
+			case "any":
+				wildcard := XSDAny{Position: len(s.Elements)}
+				err = d.DecodeElement(&wildcard, &t)
+				s.Any = append(s.Any, wildcard)
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
 }
 
 // XSDChoice represents an XSD choice
@@ -86,6 +179,7 @@ type XSDChoice struct {
 	Groups    []XSDGroupRef `xml:"group"`
 	Choices   []XSDChoice   `xml:"choice"`
 	Sequences []XSDSequence `xml:"sequence"`
+	Any       []XSDAny      `xml:"any"`
 }
 
 // XSDAll represents an XSD all
@@ -96,6 +190,25 @@ type XSDAll struct {
 	Elements  []XSDElement `xml:"element"`
 }
 
+// XSDAny represents an XSD any element wildcard
+type XSDAny struct {
+	XMLName         xml.Name       `xml:"any"`
+	Namespace       string         `xml:"namespace,attr"`
+	ProcessContents string         `xml:"processContents,attr"` // strict, lax, skip
+	MinOccurs       string         `xml:"minOccurs,attr"`
+	MaxOccurs       string         `xml:"maxOccurs,attr"`
+	Annotation      *XSDAnnotation `xml:"annotation"`
+	Position        int            `xml:"-"` // number of element particles before it in a sequence
+}
+
+// XSDAnyAttribute represents an XSD anyAttribute wildcard
+type XSDAnyAttribute struct {
+	XMLName         xml.Name       `xml:"anyAttribute"`
+	Namespace       string         `xml:"namespace,attr"`
+	ProcessContents string         `xml:"processContents,attr"` // strict, lax, skip
+	Annotation      *XSDAnnotation `xml:"annotation"`
+}
+
 // XSDAttribute represents an XSD attribute
 type XSDAttribute struct {
 	XMLName    xml.Name       `xml:"attribute"`
@@ -139,6 +252,7 @@ type XSDAttributeGroup struct {
 	Ref             string                 `xml:"ref,attr"`
 	Attributes      []XSDAttribute         `xml:"attribute"`
 	AttributeGroups []XSDAttributeGroupRef `xml:"attributeGroup"`
+	AnyAttribute    *XSDAnyAttribute       `xml:"anyAttribute"`
 	Annotation      *XSDAnnotation         `xml:"annotation"`
 }
 
@@ -153,8 +267,9 @@ type XSDAttributeGroupRef struct {
 type XSDRestriction struct {
 	XMLName         xml.Name               `xml:"restriction"`
 	Base            string                 `xml:"base,attr"`
+	SimpleType      *XSDSimpleType         `xml:"simpleType"` // anonymous base type
 	Enumerations    []XSDEnumeration       `xml:"enumeration"`
-	Pattern         *XSDPattern            `xml:"pattern"`
+	Patterns        []XSDPattern           `xml:"pattern"`
 	Length          *XSDLength             `xml:"length"`
 	MinLength       *XSDMinLength          `xml:"minLength"`
 	MaxLength       *XSDMaxLength          `xml:"maxLength"`
@@ -167,6 +282,7 @@ type XSDRestriction struct {
 	WhiteSpace      *XSDWhiteSpace         `xml:"whiteSpace"`
 	Attributes      []XSDAttribute         `xml:"attribute"`
 	AttributeGroups []XSDAttributeGroupRef `xml:"attributeGroup"`
+	AnyAttribute    *XSDAnyAttribute       `xml:"anyAttribute"`
 	Sequence        *XSDSequence           `xml:"sequence"`
 	Choice          *XSDChoice             `xml:"choice"`
 	All             *XSDAll                `xml:"all"`
@@ -188,10 +304,24 @@ type XSDPattern struct {
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
+// JoinPatterns joins the patterns of a single restriction, any of which a
+// value may match, into one XSD pattern
+func JoinPatterns(patterns []XSDPattern) string {
+	if len(patterns) == 1 {
+		return patterns[0].Value
+	}
+	alternatives := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		alternatives[i] = "(" + pattern.Value + ")"
+	}
+	return strings.Join(alternatives, "|")
+}
+
 // XSDMinLength represents an XSD minLength
 type XSDMinLength struct {
 	XMLName    xml.Name       `xml:"minLength"`
 	Value      string         `xml:"value,attr"`
+	Fixed      string         `xml:"fixed,attr"`
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
@@ -199,6 +329,7 @@ type XSDMinLength struct {
 type XSDMaxLength struct {
 	XMLName    xml.Name       `xml:"maxLength"`
 	Value      string         `xml:"value,attr"`
+	Fixed      string         `xml:"fixed,attr"`
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
@@ -206,6 +337,7 @@ type XSDMaxLength struct {
 type XSDMinInclusive struct {
 	XMLName    xml.Name       `xml:"minInclusive"`
 	Value      string         `xml:"value,attr"`
+	Fixed      string         `xml:"fixed,attr"`
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
@@ -213,6 +345,7 @@ type XSDMinInclusive struct {
 type XSDMaxInclusive struct {
 	XMLName    xml.Name       `xml:"maxInclusive"`
 	Value      string         `xml:"value,attr"`
+	Fixed      string         `xml:"fixed,attr"`
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
@@ -220,6 +353,7 @@ type XSDMaxInclusive struct {
 type XSDMinExclusive struct {
 	XMLName    xml.Name       `xml:"minExclusive"`
 	Value      string         `xml:"value,attr"`
+	Fixed      string         `xml:"fixed,attr"`
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
@@ -227,6 +361,7 @@ type XSDMinExclusive struct {
 type XSDMaxExclusive struct {
 	XMLName    xml.Name       `xml:"maxExclusive"`
 	Value      string         `xml:"value,attr"`
+	Fixed      string         `xml:"fixed,attr"`
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
@@ -234,6 +369,7 @@ type XSDMaxExclusive struct {
 type XSDTotalDigits struct {
 	XMLName    xml.Name       `xml:"totalDigits"`
 	Value      string         `xml:"value,attr"`
+	Fixed      string         `xml:"fixed,attr"`
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
@@ -241,6 +377,7 @@ type XSDTotalDigits struct {
 type XSDFractionDigits struct {
 	XMLName    xml.Name       `xml:"fractionDigits"`
 	Value      string         `xml:"value,attr"`
+	Fixed      string         `xml:"fixed,attr"`
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
@@ -248,6 +385,7 @@ type XSDFractionDigits struct {
 type XSDWhiteSpace struct {
 	XMLName    xml.Name       `xml:"whiteSpace"`
 	Value      string         `xml:"value,attr"` // preserve, replace, collapse
+	Fixed      string         `xml:"fixed,attr"`
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
@@ -255,6 +393,7 @@ type XSDWhiteSpace struct {
 type XSDLength struct {
 	XMLName    xml.Name       `xml:"length"`
 	Value      string         `xml:"value,attr"`
+	Fixed      string         `xml:"fixed,attr"`
 	Annotation *XSDAnnotation `xml:"annotation"`
 }
 
@@ -301,6 +440,7 @@ type XSDExtension struct {
 	Group           *XSDGroupRef           `xml:"group"`
 	Attributes      []XSDAttribute         `xml:"attribute"`
 	AttributeGroups []XSDAttributeGroupRef `xml:"attributeGroup"`
+	AnyAttribute    *XSDAnyAttribute       `xml:"anyAttribute"`
 	Annotation      *XSDAnnotation         `xml:"annotation"`
 }
 
@@ -353,12 +493,47 @@ type GoType struct {
 	IsEnum    bool
 	BaseType  string
 
+	// BuiltinBase is the built-in XSD type a restricted simple type
+	// ultimately derives from, such as "decimal"
+	BuiltinBase string
+
+	// SourceFile is the schema document that defines the type, set when
+	// the schema spans more than one file
+	SourceFile string
+
+	// IsAbstract marks abstract complex types, which never appear directly
+	IsAbstract bool
+	// IsMixed marks complex types whose content interleaves text and elements
+	IsMixed bool
+
+	// Substitution group support: a group type holds any member element of
+	// the head element named by XMLName, and every member type implements
+	// MemberInterface (listed in the member type's Implements)
+	IsSubstitutionGroup bool
+	MemberInterface     string
+	Members             []GoSubstitutionMember
+	Implements          []string
+
+	// Union support: a string-backed type whose value must match one of
+	// the member types, tried in order
+	IsUnion      bool
+	UnionMembers []GoUnionMember
+
+	// List support: a slice of ListItemType written as whitespace-separated
+	// text; the length facets count list items
+	IsList       bool
+	ListItemType string
+
+	// Identity constraints scoped to the element the type was generated for
+	IdentityConstraints []GoIdentityConstraint
+
 	// Validation properties
 	NeedsValidation bool // Flag indicating if the type needs validation
 
-	// Pattern restriction
-	HasPattern   bool
-	PatternValue string
+	// Pattern restriction: the value must match every entry of Patterns,
+	// which holds the patterns of each derivation step, base type first
+	HasPattern bool
+	Patterns   []string
 
 	// Length restrictions
 	HasMinLength bool
@@ -406,11 +581,44 @@ type GoField struct {
 	IsOptional  bool
 	IsArray     bool
 	MinOccurs   int
-	MaxOccurs   int // -1 for unbounded
+	MaxOccurs   int  // -1 for unbounded
+	IsWildcard  bool // catch-all field for xs:any or xs:anyAttribute
+	IsText      bool // character data of a simpleContent type
+	IsMixed     bool // ordered text and child elements of a mixed type
+	IsNillable  bool // element declared nillable="true"
 
 	// Fixed value support
 	HasFixedValue bool
 	FixedValue    string
+
+	// Default value support
+	HasDefault   bool
+	DefaultValue string
+}
+
+// GoIdentityConstraint is an xs:key, xs:keyref or xs:unique with its
+// selector and field XPaths
+type GoIdentityConstraint struct {
+	Kind     string // key, keyref or unique
+	Name     string
+	Refer    string // local name of the key a keyref refers to
+	Selector string
+	Fields   []string
+}
+
+// GoSubstitutionMember is an element that may appear in place of a
+// substitution group head
+type GoSubstitutionMember struct {
+	ElementName string
+	Namespace   string
+	TypeName    string
+}
+
+// GoUnionMember is a member type of a union. XSDType is the local name of
+// a built-in XSD type and empty for types defined in the schema.
+type GoUnionMember struct {
+	TypeName string
+	XSDType  string
 }
 
 // GoConstant represents a Go constant (for enums)
@@ -420,17 +628,77 @@ type GoConstant struct {
 	Comment string
 }
 
+// XSDNamespace is the namespace URI of the XML Schema vocabulary
+const XSDNamespace = "http://www.w3.org/2001/XMLSchema"
+
+// XMLNamespace is the namespace URI bound to the reserved xml prefix
+const XMLNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// XSINamespace is the namespace URI of the XML Schema instance attributes
+// such as xsi:nil and xsi:type
+const XSINamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// QName is a namespace-qualified name
+type QName struct {
+	Space string
+	Local string
+}
+
+// ParseQName parses a resolved "{namespace}local" value. Unresolved
+// "prefix:local" values yield a QName without a namespace.
+func ParseQName(value string) QName {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "{") {
+		if end := strings.Index(value, "}"); end != -1 {
+			return QName{Space: value[1:end], Local: value[end+1:]}
+		}
+	}
+	return QName{Local: LocalName(value)}
+}
+
+// String returns the QName in "{namespace}local" form
+func (q QName) String() string {
+	if q.Space == "" {
+		return q.Local
+	}
+	return "{" + q.Space + "}" + q.Local
+}
+
+// LocalName returns the local part of a QName value in either
+// "{namespace}local" or "prefix:local" form
+func LocalName(value string) string {
+	if end := strings.LastIndex(value, "}"); end != -1 {
+		return value[end+1:]
+	}
+	if colonIndex := strings.LastIndex(value, ":"); colonIndex != -1 {
+		return value[colonIndex+1:]
+	}
+	return value
+}
+
 // ToGoTypeName converts an XSD type name to a valid Go type name
 func ToGoTypeName(xsdType string) string {
 	// Remove namespace prefix
-	if colonIndex := strings.LastIndex(xsdType, ":"); colonIndex != -1 {
-		xsdType = xsdType[colonIndex+1:]
-	}
+	xsdType = LocalName(xsdType)
 
 	// Convert to PascalCase
 	return ToPascalCase(xsdType)
 }
 
+// MapTypeRef applies fn to the type name inside slice, pointer and Nillable
+// wrappers of a type reference
+func MapTypeRef(fieldType string, fn func(string) string) string {
+	switch {
+	case strings.HasPrefix(fieldType, "[]"):
+		return "[]" + MapTypeRef(fieldType[2:], fn)
+	case strings.HasPrefix(fieldType, "*"):
+		return "*" + MapTypeRef(fieldType[1:], fn)
+	case strings.HasPrefix(fieldType, "Nillable[") && strings.HasSuffix(fieldType, "]"):
+		return "Nillable[" + MapTypeRef(fieldType[len("Nillable["):len(fieldType)-1], fn) + "]"
+	}
+	return fn(fieldType)
+}
+
 // ToGoFieldName converts an XSD element/attribute name to a valid Go field name
 func ToGoFieldName(name string) string {
 	return ToPascalCase(name)
@@ -452,27 +720,20 @@ func ToSnakeCase(s string) string {
 	return s
 }
 
-// ParseOccurs parses minOccurs and maxOccurs attributes
+// ParseOccurs parses minOccurs and maxOccurs attributes. Missing or invalid
+// values default to 1 and an unbounded maximum is returned as -1.
 func ParseOccurs(minOccurs, maxOccurs string) (min int, max int) {
 	min = 1
 	max = 1
 
-	if minOccurs != "" {
-		if minOccurs == "0" {
-			min = 0
-		} else {
-			min = 1
-		}
+	if value, err := strconv.Atoi(strings.TrimSpace(minOccurs)); err == nil && value >= 0 {
+		min = value
 	}
 
-	if maxOccurs != "" {
-		if maxOccurs == "unbounded" {
-			max = -1
-		} else if maxOccurs == "0" {
-			max = 0
-		} else {
-			max = 1
-		}
+	if maxOccurs == "unbounded" {
+		max = -1
+	} else if value, err := strconv.Atoi(strings.TrimSpace(maxOccurs)); err == nil && value >= 0 {
+		max = value
 	}
 
 	return min, max
@@ -0,0 +1,203 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+	"github.com/suifei/xsd2code/pkg/xsd"
+	"github.com/suifei/xsd2code/pkg/xsdregex"
+)
+
+// findSimpleType looks up a named simple type of the schema
+func (v *XSDValidator) findSimpleType(typeName string) *types.XSDSimpleType {
+	qname := types.ParseQName(typeName)
+	if qname.Space == types.XSDNamespace {
+		return nil
+	}
+	for i := range v.schema.SimpleTypes {
+		if v.schema.SimpleTypes[i].Name == qname.Local {
+			return &v.schema.SimpleTypes[i]
+		}
+	}
+	return nil
+}
+
+// simpleTypeRestriction returns the restriction of the anonymous or named
+// simple type a value is declared with, or nil
+func (v *XSDValidator) simpleTypeRestriction(typeName string, simpleType *types.XSDSimpleType) *types.XSDRestriction {
+	if simpleType == nil {
+		simpleType = v.findSimpleType(typeName)
+	}
+	if simpleType == nil {
+		return nil
+	}
+	return simpleType.Restriction
+}
+
+// restrictionChain returns a restriction followed by those of its anonymous
+// and named base types, most derived first
+func (v *XSDValidator) restrictionChain(restriction *types.XSDRestriction) []*types.XSDRestriction {
+	chain := make([]*types.XSDRestriction, 0)
+	visited := make(map[string]bool)
+	for restriction != nil {
+		chain = append(chain, restriction)
+		if restriction.SimpleType != nil {
+			restriction = restriction.SimpleType.Restriction
+			continue
+		}
+		if visited[restriction.Base] {
+			break
+		}
+		visited[restriction.Base] = true
+		restriction = v.simpleTypeRestriction(restriction.Base, nil)
+	}
+	return chain
+}
+
+// primitiveBase returns the local name of the built-in type a restriction
+// chain derives from
+func primitiveBase(chain []*types.XSDRestriction) string {
+	if len(chain) == 0 {
+		return ""
+	}
+	return types.LocalName(chain[len(chain)-1].Base)
+}
+
+// numericTypes lists the built-in XSD types whose values are numbers
+var numericTypes = map[string]bool{
+	"decimal": true, "float": true, "double": true,
+	"integer": true, "long": true, "int": true, "short": true, "byte": true,
+	"nonNegativeInteger": true, "positiveInteger": true,
+	"nonPositiveInteger": true, "negativeInteger": true,
+	"unsignedLong": true, "unsignedInt": true, "unsignedShort": true, "unsignedByte": true,
+}
+
+// isNumericType reports whether a built-in XSD type has numeric values
+func isNumericType(typeName string) bool {
+	return numericTypes[typeName]
+}
+
+// validateSimpleContent validates the text of an element declared with a
+// simple type against the facets of that type
+func (v *XSDValidator) validateSimpleContent(element XMLElement, elementDef *types.XSDElement, ctx *ValidationContext) {
+	if restriction := v.simpleTypeRestriction(elementDef.Type, elementDef.SimpleType); restriction != nil {
+		v.validateStringValue(element.Content, restriction, elementDef.Name, ctx)
+	} else if types.ParseQName(elementDef.Type).Space == types.XSDNamespace {
+		v.validateTemporalValue(element.Content, types.LocalName(elementDef.Type), nil, elementDef.Name, ctx)
+	}
+}
+
+// validateTemporalValue checks a value of a built-in date, time or duration
+// type, and its range restrictions when given. Values of other types are
+// left alone.
+func (v *XSDValidator) validateTemporalValue(value, typeName string, restriction *types.XSDRestriction, fieldName string, ctx *ValidationContext) {
+	if !xsd.IsType(typeName) {
+		return
+	}
+	parsed, err := xsd.Parse(typeName, value)
+	if err != nil {
+		ctx.errors = append(ctx.errors, ValidationError{
+			Message: fmt.Sprintf("value '%s' is not a valid %s for field '%s'", value, typeName, fieldName),
+			Line:    ctx.line,
+			Column:  ctx.column,
+			Element: fieldName,
+		})
+		return
+	}
+	if restriction == nil {
+		return
+	}
+
+	type rangeBound struct {
+		name, value string
+		fails       func(int) bool
+	}
+	var bounds []rangeBound
+	if restriction.MinInclusive != nil {
+		bounds = append(bounds, rangeBound{"minimum inclusive", restriction.MinInclusive.Value, func(c int) bool { return c < 0 }})
+	}
+	if restriction.MinExclusive != nil {
+		bounds = append(bounds, rangeBound{"minimum exclusive", restriction.MinExclusive.Value, func(c int) bool { return c <= 0 }})
+	}
+	if restriction.MaxInclusive != nil {
+		bounds = append(bounds, rangeBound{"maximum inclusive", restriction.MaxInclusive.Value, func(c int) bool { return c > 0 }})
+	}
+	if restriction.MaxExclusive != nil {
+		bounds = append(bounds, rangeBound{"maximum exclusive", restriction.MaxExclusive.Value, func(c int) bool { return c >= 0 }})
+	}
+	for _, bound := range bounds {
+		limit, err := xsd.Parse(typeName, bound.value)
+		if err != nil {
+			ctx.errors = append(ctx.errors, ValidationError{
+				Message: fmt.Sprintf("invalid %s value '%s' for field '%s': %v", bound.name, bound.value, fieldName, err),
+				Line:    ctx.line,
+				Column:  ctx.column,
+				Element: fieldName,
+			})
+			continue
+		}
+		if c := xsd.Compare(parsed, limit); c == xsd.Indeterminate || bound.fails(c) {
+			ctx.errors = append(ctx.errors, ValidationError{
+				Message: fmt.Sprintf("value '%s' is outside the %s value '%s' for field '%s'", value, bound.name, bound.value, fieldName),
+				Line:    ctx.line,
+				Column:  ctx.column,
+				Element: fieldName,
+			})
+		}
+	}
+}
+
+// validatePatterns checks a value against the patterns of every derivation
+// step, reporting the first step it fails. The patterns of one step are
+// alternatives.
+func (v *XSDValidator) validatePatterns(value string, chain []*types.XSDRestriction, fieldName string, ctx *ValidationContext) {
+	for _, restriction := range chain {
+		if len(restriction.Patterns) == 0 {
+			continue
+		}
+		pattern := types.JoinPatterns(restriction.Patterns)
+		matched, err := xsdregex.MatchString(pattern, value)
+		if err != nil {
+			ctx.errors = append(ctx.errors, ValidationError{
+				Message: fmt.Sprintf("invalid pattern '%s' for field '%s': %v", pattern, fieldName, err),
+				Line:    ctx.line,
+				Column:  ctx.column,
+				Element: fieldName,
+			})
+		} else if !matched {
+			ctx.errors = append(ctx.errors, ValidationError{
+				Message: fmt.Sprintf("value '%s' does not match pattern '%s' for field '%s'", value, pattern, fieldName),
+				Line:    ctx.line,
+				Column:  ctx.column,
+				Element: fieldName,
+			})
+			return
+		}
+	}
+}
+
+// validateEnumerations checks a value against the enumerations of every
+// derivation step that has any, reporting the first step it fails
+func (v *XSDValidator) validateEnumerations(value string, chain []*types.XSDRestriction, fieldName string, ctx *ValidationContext) {
+	for _, restriction := range chain {
+		if len(restriction.Enumerations) == 0 {
+			continue
+		}
+		validValues := make([]string, len(restriction.Enumerations))
+		found := false
+		for i, enum := range restriction.Enumerations {
+			validValues[i] = enum.Value
+			found = found || enum.Value == value
+		}
+		if !found {
+			ctx.errors = append(ctx.errors, ValidationError{
+				Message: fmt.Sprintf("value '%s' is not in enumeration [%s] for field '%s'", value, strings.Join(validValues, ", "), fieldName),
+				Line:    ctx.line,
+				Column:  ctx.column,
+				Element: fieldName,
+			})
+			return
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+const facetStepsSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:t" xmlns="urn:t" elementFormDefault="qualified">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string"><xs:pattern value="[A-Z]{3}"/><xs:pattern value="[0-9]{3}"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="LetterCode">
+    <xs:restriction base="Code"><xs:pattern value="A.*"/><xs:pattern value="B.*"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Color">
+    <xs:restriction base="xs:string"><xs:enumeration value="red"/><xs:enumeration value="green"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Warm">
+    <xs:restriction base="Color"><xs:enumeration value="red"/><xs:enumeration value="orange"/></xs:restriction>
+  </xs:simpleType>
+  <xs:element name="root">
+    <xs:complexType>
+      <xs:sequence><xs:element name="code" type="LetterCode"/></xs:sequence>
+      <xs:attribute name="warm" type="Warm"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+func TestFacetSteps(t *testing.T) {
+	var schema types.XSDSchema
+	if err := xml.Unmarshal([]byte(facetStepsSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	v := NewXSDValidator(&schema)
+
+	tests := []struct {
+		document string
+		err      string
+	}{
+		{`<root xmlns="urn:t" warm="red"><code>ABC</code></root>`, ""},
+		{`<root xmlns="urn:t"><code>BCD</code></root>`, ""},
+		{`<root xmlns="urn:t"><code>CDE</code></root>`, "value 'CDE' does not match pattern '(A.*)|(B.*)' for field 'code'"},
+		{`<root xmlns="urn:t"><code>A12</code></root>`, "value 'A12' does not match pattern '([A-Z]{3})|([0-9]{3})' for field 'code'"},
+		{`<root xmlns="urn:t" warm="green"><code>ABC</code></root>`, "value 'green' is not in enumeration [red, orange] for field 'warm'"},
+		{`<root xmlns="urn:t" warm="orange"><code>ABC</code></root>`, "value 'orange' is not in enumeration [red, green] for field 'warm'"},
+	}
+	for _, test := range tests {
+		err := v.ValidateXMLContent([]byte(test.document))
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: error = %v", test.document, err)
+		case test.err != "" && (err == nil || err.Error() != test.err):
+			t.Errorf("%s: error = %v, want %s", test.document, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,604 @@
+package validator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+	"github.com/suifei/xsd2code/pkg/xsd"
+)
+
+// identityConstraints collects the identity constraints of all element
+// declarations in the schema
+func (v *XSDValidator) identityConstraints() []types.XSDIdentityConstraint {
+	collector := identityCollector{}
+	for _, element := range v.schema.Elements {
+		collector.element(element)
+	}
+	for i := range v.schema.ComplexTypes {
+		collector.complexType(&v.schema.ComplexTypes[i])
+	}
+	for _, group := range v.schema.Groups {
+		collector.sequence(group.Sequence)
+		collector.choice(group.Choice)
+		collector.all(group.All)
+	}
+	return collector.constraints
+}
+
+// identityCollector walks element declarations and their anonymous types
+type identityCollector struct {
+	constraints []types.XSDIdentityConstraint
+}
+
+// element collects the constraints of an element and its anonymous type
+func (c *identityCollector) element(element types.XSDElement) {
+	c.constraints = append(c.constraints, element.IdentityConstraints()...)
+	if element.ComplexType != nil {
+		c.complexType(element.ComplexType)
+	}
+}
+
+// complexType collects the constraints of the elements a type declares
+func (c *identityCollector) complexType(complexType *types.XSDComplexType) {
+	c.sequence(complexType.Sequence)
+	c.choice(complexType.Choice)
+	c.all(complexType.All)
+	if content := complexType.ComplexContent; content != nil {
+		if content.Extension != nil {
+			c.sequence(content.Extension.Sequence)
+			c.choice(content.Extension.Choice)
+			c.all(content.Extension.All)
+		}
+		if content.Restriction != nil {
+			c.sequence(content.Restriction.Sequence)
+			c.choice(content.Restriction.Choice)
+			c.all(content.Restriction.All)
+		}
+	}
+}
+
+// sequence collects the constraints of the elements in a sequence
+func (c *identityCollector) sequence(sequence *types.XSDSequence) {
+	if sequence == nil {
+		return
+	}
+	for _, element := range sequence.Elements {
+		c.element(element)
+	}
+	for i := range sequence.Choices {
+		c.choice(&sequence.Choices[i])
+	}
+	for i := range sequence.Sequences {
+		c.sequence(&sequence.Sequences[i])
+	}
+}
+
+// choice collects the constraints of the elements in a choice
+func (c *identityCollector) choice(choice *types.XSDChoice) {
+	if choice == nil {
+		return
+	}
+	for _, element := range choice.Elements {
+		c.element(element)
+	}
+	for i := range choice.Choices {
+		c.choice(&choice.Choices[i])
+	}
+	for i := range choice.Sequences {
+		c.sequence(&choice.Sequences[i])
+	}
+}
+
+// all collects the constraints of the elements in an all group
+func (c *identityCollector) all(all *types.XSDAll) {
+	if all == nil {
+		return
+	}
+	for _, element := range all.Elements {
+		c.element(element)
+	}
+}
+
+// identityNode is an element of the document with the declaration it was
+// matched to, which is nil when no declaration applies
+type identityNode struct {
+	element  XMLElement
+	decl     *types.XSDElement
+	children []*identityNode
+}
+
+// identityTree matches the children of an element to the declarations of
+// its type by name, and elements no particle declares, such as those of
+// wildcards, to the global declarations
+func (v *XSDValidator) identityTree(element XMLElement, decl *types.XSDElement) *identityNode {
+	node := &identityNode{element: element, decl: decl}
+	particles, _ := v.typeContent(v.elementComplexType(decl))
+	for _, child := range element.Children {
+		node.children = append(node.children, v.identityTree(child, v.matchDeclaration(child.XMLName, particles)))
+	}
+	return node
+}
+
+// matchDeclaration returns the declaration an element name matches
+func (v *XSDValidator) matchDeclaration(name xml.Name, particles []types.XSDElement) *types.XSDElement {
+	for i := range particles {
+		if particles[i].Name == name.Local {
+			return &particles[i]
+		}
+	}
+	return v.findRootElement(name)
+}
+
+// elementComplexType returns the anonymous or named complex type of an
+// element declaration, or nil
+func (v *XSDValidator) elementComplexType(decl *types.XSDElement) *types.XSDComplexType {
+	if decl == nil {
+		return nil
+	}
+	if decl.ComplexType != nil {
+		return decl.ComplexType
+	}
+	return v.findComplexType(decl.Type)
+}
+
+// findComplexType looks up a named complex type of the schema
+func (v *XSDValidator) findComplexType(typeName string) *types.XSDComplexType {
+	qname := types.ParseQName(typeName)
+	if qname.Local == "" || qname.Space == types.XSDNamespace {
+		return nil
+	}
+	for i := range v.schema.ComplexTypes {
+		if v.schema.ComplexTypes[i].Name == qname.Local {
+			return &v.schema.ComplexTypes[i]
+		}
+	}
+	return nil
+}
+
+// typeContent returns the element and attribute declarations of a complex
+// type, including those of the types and groups it is built from
+func (v *XSDValidator) typeContent(complexType *types.XSDComplexType) ([]types.XSDElement, []types.XSDAttribute) {
+	collector := contentCollector{validator: v, visited: make(map[string]bool)}
+	collector.complexType(complexType)
+	return collector.elements, collector.attributes
+}
+
+// contentCollector walks the content model of a complex type without
+// descending into the types of the elements it declares
+type contentCollector struct {
+	validator  *XSDValidator
+	visited    map[string]bool
+	elements   []types.XSDElement
+	attributes []types.XSDAttribute
+}
+
+// complexType collects the declarations of a complex type and its base types
+func (c *contentCollector) complexType(complexType *types.XSDComplexType) {
+	if complexType == nil {
+		return
+	}
+	c.attributes = append(c.attributes, c.validator.complexTypeAttributes(complexType)...)
+	c.particles(complexType.Sequence, complexType.Choice, complexType.All, complexType.Group)
+	if content := complexType.SimpleContent; content != nil && content.Extension != nil {
+		c.extension(content.Extension)
+	}
+	if content := complexType.ComplexContent; content != nil {
+		if content.Extension != nil {
+			c.extension(content.Extension)
+		}
+		if content.Restriction != nil {
+			c.attributes = append(c.attributes, c.validator.complexTypeAttributes(&types.XSDComplexType{
+				Attributes: content.Restriction.Attributes, AttributeGroups: content.Restriction.AttributeGroups,
+			})...)
+			c.particles(content.Restriction.Sequence, content.Restriction.Choice, content.Restriction.All, content.Restriction.Group)
+		}
+	}
+}
+
+// extension collects the declarations of an extension and of its base type
+func (c *contentCollector) extension(extension *types.XSDExtension) {
+	if !c.visited[extension.Base] {
+		c.visited[extension.Base] = true
+		c.complexType(c.validator.findComplexType(extension.Base))
+	}
+	c.attributes = append(c.attributes, c.validator.complexTypeAttributes(&types.XSDComplexType{
+		Attributes: extension.Attributes, AttributeGroups: extension.AttributeGroups,
+	})...)
+	c.particles(extension.Sequence, extension.Choice, extension.All, extension.Group)
+}
+
+// particles collects the element declarations of a model group
+func (c *contentCollector) particles(sequence *types.XSDSequence, choice *types.XSDChoice, all *types.XSDAll, group *types.XSDGroupRef) {
+	if sequence != nil {
+		c.add(sequence.Elements)
+		c.groups(sequence.Groups)
+		for i := range sequence.Choices {
+			c.particles(nil, &sequence.Choices[i], nil, nil)
+		}
+		for i := range sequence.Sequences {
+			c.particles(&sequence.Sequences[i], nil, nil, nil)
+		}
+	}
+	if choice != nil {
+		c.add(choice.Elements)
+		c.groups(choice.Groups)
+		for i := range choice.Choices {
+			c.particles(nil, &choice.Choices[i], nil, nil)
+		}
+		for i := range choice.Sequences {
+			c.particles(&choice.Sequences[i], nil, nil, nil)
+		}
+	}
+	if all != nil {
+		c.add(all.Elements)
+	}
+	if group != nil {
+		c.groups([]types.XSDGroupRef{*group})
+	}
+}
+
+// groups collects the element declarations of referenced model groups
+func (c *contentCollector) groups(refs []types.XSDGroupRef) {
+	for _, ref := range refs {
+		name := types.LocalName(ref.Ref)
+		if c.visited["group "+name] {
+			continue
+		}
+		c.visited["group "+name] = true
+		for i := range c.validator.schema.Groups {
+			if group := &c.validator.schema.Groups[i]; group.Name == name {
+				c.particles(group.Sequence, group.Choice, group.All, nil)
+				break
+			}
+		}
+	}
+}
+
+// add collects element declarations, resolving references
+func (c *contentCollector) add(elements []types.XSDElement) {
+	for _, element := range elements {
+		c.elements = append(c.elements, c.validator.resolveElementRef(element))
+	}
+}
+
+// validateIdentityConstraints checks every xs:key, xs:keyref and xs:unique
+// in the document. Each element whose declaration carries constraints is a
+// scope; keyrefs match keys declared on the scope or its descendants.
+func (v *XSDValidator) validateIdentityConstraints(root XMLElement, decl *types.XSDElement, ctx *ValidationContext) {
+	constraints := v.identityConstraints()
+	if len(constraints) == 0 {
+		return
+	}
+	v.validateIdentityScopes(v.identityTree(root, decl), constraints, ctx)
+}
+
+// validateIdentityScopes validates the constraints scoped to a node and to
+// each of its descendants
+func (v *XSDValidator) validateIdentityScopes(node *identityNode, constraints []types.XSDIdentityConstraint, ctx *ValidationContext) {
+	for _, constraint := range node.constraints() {
+		v.validateIdentityConstraint(node, constraint, constraints, ctx)
+	}
+	for _, child := range node.children {
+		v.validateIdentityScopes(child, constraints, ctx)
+	}
+}
+
+// constraints returns the identity constraints of the declaration of a node
+func (n *identityNode) constraints() []types.XSDIdentityConstraint {
+	if n.decl == nil {
+		return nil
+	}
+	return n.decl.IdentityConstraints()
+}
+
+// validateIdentityConstraint validates one constraint within a scope
+func (v *XSDValidator) validateIdentityConstraint(scope *identityNode, constraint types.XSDIdentityConstraint, constraints []types.XSDIdentityConstraint, ctx *ValidationContext) {
+	kind := constraint.XMLName.Local
+	if kind != "keyref" {
+		v.identityTable(scope, constraint, ctx)
+		return
+	}
+
+	refer := types.LocalName(constraint.Refer)
+	if !keyDeclared(constraints, refer) {
+		v.identityError(ctx, scope, fmt.Sprintf("keyref '%s' refers to unknown key '%s'", constraint.Name, refer))
+		return
+	}
+	referenced := make(map[string]bool)
+	v.referencedKeyTable(scope, refer, referenced)
+	nodes, fields, ok := v.identityPaths(scope, constraint, ctx)
+	if !ok {
+		return
+	}
+	for _, node := range nodes {
+		tuple, display, complete := v.identityTuple(node, constraint, fields, ctx)
+		if complete && !referenced[tuple] {
+			v.identityError(ctx, node, fmt.Sprintf("keyref '%s' value '%s' does not match any '%s' key", constraint.Name, display, refer))
+		}
+	}
+}
+
+// identityTable evaluates a key or unique constraint within a scope,
+// reporting missing fields of keys and duplicate values, and returns the
+// set of values found
+func (v *XSDValidator) identityTable(scope *identityNode, constraint types.XSDIdentityConstraint, ctx *ValidationContext) map[string]bool {
+	table := make(map[string]bool)
+	nodes, fields, ok := v.identityPaths(scope, constraint, ctx)
+	if !ok {
+		return table
+	}
+	kind := constraint.XMLName.Local
+	for _, node := range nodes {
+		tuple, display, complete := v.identityTuple(node, constraint, fields, ctx)
+		if !complete {
+			if kind == "key" {
+				v.identityError(ctx, node, fmt.Sprintf("key '%s' requires a value for every field of element '%s'", constraint.Name, node.element.XMLName.Local))
+			}
+			continue
+		}
+		if table[tuple] {
+			v.identityError(ctx, node, fmt.Sprintf("duplicate %s '%s' value '%s'", kind, constraint.Name, display))
+			continue
+		}
+		table[tuple] = true
+	}
+	return table
+}
+
+// referencedKeyTable adds to table the values of the named key or unique
+// constraint wherever it is scoped on scope or one of its descendants.
+// Violations of the key itself are reported by its own scope.
+func (v *XSDValidator) referencedKeyTable(scope *identityNode, name string, table map[string]bool) {
+	for _, constraint := range scope.constraints() {
+		if constraint.Name == name && constraint.XMLName.Local != "keyref" {
+			for tuple := range v.identityTable(scope, constraint, &ValidationContext{}) {
+				table[tuple] = true
+			}
+		}
+	}
+	for _, child := range scope.children {
+		v.referencedKeyTable(child, name, table)
+	}
+}
+
+// keyDeclared reports whether a key or unique constraint has the given name
+func keyDeclared(constraints []types.XSDIdentityConstraint, name string) bool {
+	for _, constraint := range constraints {
+		if constraint.Name == name && constraint.XMLName.Local != "keyref" {
+			return true
+		}
+	}
+	return false
+}
+
+// identityPaths parses the XPaths of a constraint and returns the elements
+// its selector picks out of scope
+func (v *XSDValidator) identityPaths(scope *identityNode, constraint types.XSDIdentityConstraint, ctx *ValidationContext) ([]*identityNode, [][]types.IdentityPath, bool) {
+	selector, err := types.ParseIdentityXPath(constraint.Selector.XPath, false)
+	if err != nil {
+		v.identityError(ctx, scope, fmt.Sprintf("%s '%s' has an invalid selector: %v", constraint.XMLName.Local, constraint.Name, err))
+		return nil, nil, false
+	}
+	fields := make([][]types.IdentityPath, 0, len(constraint.Fields))
+	for _, field := range constraint.Fields {
+		paths, err := types.ParseIdentityXPath(field.XPath, true)
+		if err != nil {
+			v.identityError(ctx, scope, fmt.Sprintf("%s '%s' has an invalid field: %v", constraint.XMLName.Local, constraint.Name, err))
+			return nil, nil, false
+		}
+		fields = append(fields, paths)
+	}
+
+	var nodes []*identityNode
+	for _, path := range selector {
+		nodes = append(nodes, selectIdentityElements(scope, path)...)
+	}
+	return nodes, fields, true
+}
+
+// identityTuple evaluates the fields of a constraint on a selected element.
+// complete is false when a field has no value; a field selecting more than
+// one value is an error.
+func (v *XSDValidator) identityTuple(node *identityNode, constraint types.XSDIdentityConstraint, fields [][]types.IdentityPath, ctx *ValidationContext) (tuple, display string, complete bool) {
+	keys := make([]string, 0, len(fields))
+	texts := make([]string, 0, len(fields))
+	for i, paths := range fields {
+		var found []identityValue
+		for _, path := range paths {
+			found = append(found, v.identityFieldValues(node, path)...)
+		}
+		if len(found) > 1 {
+			v.identityError(ctx, node, fmt.Sprintf("field '%s' of %s '%s' selects more than one value", constraint.Fields[i].XPath, constraint.XMLName.Local, constraint.Name))
+			return "", "", false
+		}
+		if len(found) == 0 {
+			return "", "", false
+		}
+		keys = append(keys, found[0].key)
+		texts = append(texts, found[0].text)
+	}
+	return strings.Join(keys, "\x00"), strings.Join(texts, ", "), true
+}
+
+// identityError records an identity constraint violation
+func (v *XSDValidator) identityError(ctx *ValidationContext, node *identityNode, message string) {
+	ctx.errors = append(ctx.errors, ValidationError{
+		Message: message,
+		Line:    ctx.line,
+		Column:  ctx.column,
+		Element: node.element.XMLName.Local,
+	})
+}
+
+// selectIdentityElements returns the elements a path selects from node
+func selectIdentityElements(node *identityNode, path types.IdentityPath) []*identityNode {
+	current := []*identityNode{node}
+	if path.Descendant {
+		current = appendDescendants(current, node)
+	}
+	for _, step := range path.Steps {
+		var next []*identityNode
+		for _, element := range current {
+			for _, child := range element.children {
+				if step == "*" || child.element.XMLName.Local == step {
+					next = append(next, child)
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// appendDescendants appends every descendant of node in document order
+func appendDescendants(nodes []*identityNode, node *identityNode) []*identityNode {
+	for _, child := range node.children {
+		nodes = append(nodes, child)
+		nodes = appendDescendants(nodes, child)
+	}
+	return nodes
+}
+
+// identityValue is the value of a field: key identifies it within the
+// value space of its declared type, and text is the value as written
+// after whitespace normalization
+type identityValue struct {
+	key, text string
+}
+
+// identityFieldValues returns the values a field path selects from node:
+// attribute values, or the text of elements
+func (v *XSDValidator) identityFieldValues(node *identityNode, path types.IdentityPath) []identityValue {
+	var values []identityValue
+	for _, selected := range selectIdentityElements(node, path) {
+		if path.Attribute == "" {
+			typeName, simpleType := v.elementValueType(selected.decl)
+			values = append(values, v.identityValue(selected.element.Content, typeName, simpleType))
+			continue
+		}
+		_, attributes := v.typeContent(v.elementComplexType(selected.decl))
+		for _, attr := range selected.element.Attrs {
+			if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+				continue
+			}
+			if path.Attribute == "*" || attr.Name.Local == path.Attribute {
+				typeName, simpleType := attributeValueType(attributes, attr.Name.Local)
+				values = append(values, v.identityValue(attr.Value, typeName, simpleType))
+			}
+		}
+	}
+	return values
+}
+
+// elementValueType returns the simple type of the text of an element
+// declaration, named or anonymous; both are empty for untyped content
+func (v *XSDValidator) elementValueType(decl *types.XSDElement) (string, *types.XSDSimpleType) {
+	if decl == nil {
+		return "", nil
+	}
+	if decl.SimpleType != nil {
+		return "", decl.SimpleType
+	}
+	complexType := v.elementComplexType(decl)
+	if complexType == nil {
+		return decl.Type, nil
+	}
+	if content := complexType.SimpleContent; content != nil {
+		if content.Extension != nil {
+			return content.Extension.Base, nil
+		}
+		if content.Restriction != nil {
+			return "", &types.XSDSimpleType{Restriction: content.Restriction}
+		}
+	}
+	return "", nil
+}
+
+// attributeValueType returns the simple type of the named attribute
+func attributeValueType(attributes []types.XSDAttribute, name string) (string, *types.XSDSimpleType) {
+	for _, attr := range attributes {
+		if attr.Name == name {
+			return attr.Type, attr.SimpleType
+		}
+	}
+	return "", nil
+}
+
+// identityValue normalizes the whitespace of a value as its type does and
+// identifies it within the value space of the type, so that 1 and 01 are
+// the same xs:int and dateTimes in different timezones can be equal.
+// Values that are invalid for their type are compared as written.
+func (v *XSDValidator) identityValue(value, typeName string, simpleType *types.XSDSimpleType) identityValue {
+	chain := v.restrictionChain(v.simpleTypeRestriction(typeName, simpleType))
+	builtin := primitiveBase(chain)
+	if len(chain) == 0 && types.ParseQName(typeName).Space == types.XSDNamespace {
+		builtin = types.LocalName(typeName)
+	}
+	whiteSpace := defaultWhiteSpace(builtin)
+	for _, restriction := range chain {
+		if restriction.WhiteSpace != nil {
+			whiteSpace = restriction.WhiteSpace.Value
+			break
+		}
+	}
+	value = normalizeWhiteSpace(value, whiteSpace)
+
+	space, key := valueSpace(builtin), value
+	switch {
+	case space == "decimal":
+		if number, err := xsd.ParseDecimal(value); err == nil {
+			key = number.RatString()
+		}
+	case space == "float" || space == "double":
+		bits := 64
+		if space == "float" {
+			bits = 32
+		}
+		if number, err := strconv.ParseFloat(value, bits); err == nil {
+			if number == 0 {
+				number = 0 // -0 equals 0
+			}
+			key = strconv.FormatFloat(number, 'g', -1, bits)
+		}
+	case space == "boolean":
+		switch value {
+		case "1":
+			key = "true"
+		case "0":
+			key = "false"
+		}
+	case xsd.IsType(space):
+		if parsed, err := xsd.Parse(space, value); err == nil {
+			key = xsd.Key(parsed)
+		}
+	}
+	return identityValue{key: space + "\x01" + key, text: value}
+}
+
+// defaultWhiteSpace returns the whiteSpace facet value of a built-in type:
+// strings and untyped values are preserved, and all other types collapsed
+func defaultWhiteSpace(builtin string) string {
+	switch builtin {
+	case "", "string", "anySimpleType":
+		return "preserve"
+	case "normalizedString":
+		return "replace"
+	}
+	return "collapse"
+}
+
+// valueSpace returns the primitive type whose value space holds the values
+// of a built-in type: xs:decimal for the integer types, and xs:string for
+// the string types and untyped values
+func valueSpace(builtin string) string {
+	switch {
+	case builtin == "float" || builtin == "double" || builtin == "boolean" || xsd.IsType(builtin):
+		return builtin
+	case isNumericType(builtin):
+		return "decimal"
+	}
+	return "string"
+}
@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// isNilAttr reports whether an attribute is xsi:nil
+func isNilAttr(attr xml.Attr) bool {
+	return attr.Name.Space == types.XSINamespace && attr.Name.Local == "nil"
+}
+
+// isNil reports whether an element carries xsi:nil="true"
+func isNil(attrs []xml.Attr) bool {
+	for _, attr := range attrs {
+		if isNilAttr(attr) {
+			return attr.Value == "true" || attr.Value == "1"
+		}
+	}
+	return false
+}
+
+// validateNil checks an element marked xsi:nil against its declaration and
+// reports whether the element is nil. Nil is only allowed on nillable
+// elements, which must then be empty and may not declare a fixed value.
+func (v *XSDValidator) validateNil(element XMLElement, elementDef *types.XSDElement, ctx *ValidationContext) bool {
+	for _, attr := range element.Attrs {
+		if isNilAttr(attr) && attr.Value != "true" && attr.Value != "1" && attr.Value != "false" && attr.Value != "0" {
+			ctx.errors = append(ctx.errors, ValidationError{
+				Message: fmt.Sprintf("invalid xsi:nil value '%s' on element '%s'", attr.Value, element.XMLName.Local),
+				Line:    ctx.line,
+				Column:  ctx.column,
+				Element: element.XMLName.Local,
+			})
+		}
+	}
+	if !isNil(element.Attrs) {
+		return false
+	}
+
+	if elementDef.Nillable != "true" && elementDef.Nillable != "1" {
+		ctx.errors = append(ctx.errors, ValidationError{
+			Message: fmt.Sprintf("element '%s' is not nillable but has xsi:nil=\"true\"", element.XMLName.Local),
+			Line:    ctx.line,
+			Column:  ctx.column,
+			Element: element.XMLName.Local,
+		})
+	}
+	if len(element.Children) > 0 || element.Content != "" {
+		ctx.errors = append(ctx.errors, ValidationError{
+			Message: fmt.Sprintf("element '%s' has xsi:nil=\"true\" but is not empty", element.XMLName.Local),
+			Line:    ctx.line,
+			Column:  ctx.column,
+			Element: element.XMLName.Local,
+		})
+	}
+	if elementDef.Fixed != "" {
+		ctx.errors = append(ctx.errors, ValidationError{
+			Message: fmt.Sprintf("element '%s' has a fixed value and cannot be nil", element.XMLName.Local),
+			Line:    ctx.line,
+			Column:  ctx.column,
+			Element: element.XMLName.Local,
+		})
+	}
+	return true
+}
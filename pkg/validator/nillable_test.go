@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+const nillableSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:t" xmlns="urn:t" elementFormDefault="qualified">
+  <xs:element name="order">
+    <xs:complexType><xs:sequence>
+      <xs:element name="price" type="xs:decimal" nillable="true"/>
+      <xs:element name="name" type="xs:string"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+func TestNillableElements(t *testing.T) {
+	var schema types.XSDSchema
+	if err := xml.Unmarshal([]byte(nillableSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	v := NewXSDValidator(&schema)
+
+	const xsi = ` xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"`
+	tests := []struct {
+		document string
+		err      string
+	}{
+		{`<order xmlns="urn:t"` + xsi + `><price xsi:nil="true"/><name>a</name></order>`, ""},
+		{`<order xmlns="urn:t"` + xsi + `><price xsi:nil="1"></price><name>a</name></order>`, ""},
+		{`<order xmlns="urn:t"` + xsi + `><price xsi:nil="false">1.5</price><name>a</name></order>`, ""},
+		{`<order xmlns="urn:t"` + xsi + `><price>1.5</price><name xsi:nil="true"/></order>`, `element 'name' is not nillable but has xsi:nil="true"`},
+		{`<order xmlns="urn:t"` + xsi + `><price xsi:nil="true">1.5</price><name>a</name></order>`, `element 'price' has xsi:nil="true" but is not empty`},
+		{`<order xmlns="urn:t"` + xsi + `><price xsi:nil="yes"/><name>a</name></order>`, `invalid xsi:nil value 'yes' on element 'price'`},
+	}
+	for _, test := range tests {
+		err := v.ValidateXMLContent([]byte(test.document))
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: error = %v", test.document, err)
+		case test.err != "" && (err == nil || err.Error() != test.err):
+			t.Errorf("%s: error = %v, want %s", test.document, err, test.err)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/suifei/xsd2code/pkg/types"
+	"github.com/suifei/xsd2code/pkg/xsdregex"
 )
 
 // XMLDocument represents a parsed XML document for validation
@@ -90,9 +91,12 @@ func (v *XSDValidator) validateDocument(doc *XMLDocument, content []byte) error
 	}
 
 	// Validate root element
-	if err := v.validateElement(doc.XMLName, doc.Attrs, doc.Children, rootElement, ctx); err != nil {
-		return err
+	if !v.validateNil(XMLElement(*doc), rootElement, ctx) {
+		if err := v.validateElement(doc.XMLName, doc.Attrs, doc.Children, rootElement, ctx); err != nil {
+			return err
+		}
 	}
+	v.validateIdentityConstraints(XMLElement(*doc), rootElement, ctx)
 
 	// Return first error if any
 	if len(ctx.errors) > 0 {
@@ -134,11 +138,14 @@ func (v *XSDValidator) validateAttributes(xmlAttrs []xml.Attr, complexType *type
 	// Create a map of XML attributes for easy lookup
 	attrMap := make(map[string]string)
 	for _, attr := range xmlAttrs {
+		if attr.Name.Space == types.XSINamespace {
+			continue
+		}
 		attrMap[attr.Name.Local] = attr.Value
 	}
 
 	// Check all defined attributes
-	for _, attrDef := range complexType.Attributes {
+	for _, attrDef := range v.complexTypeAttributes(complexType) {
 		attrName := attrDef.Name
 
 		// Check if required attribute is present
@@ -164,13 +171,66 @@ func (v *XSDValidator) validateAttributes(xmlAttrs []xml.Attr, complexType *type
 	return nil
 }
 
+// complexTypeAttributes returns the attributes a complex type declares,
+// including those brought in by attribute groups and the groups they
+// reference
+func (v *XSDValidator) complexTypeAttributes(complexType *types.XSDComplexType) []types.XSDAttribute {
+	attributes := append([]types.XSDAttribute(nil), complexType.Attributes...)
+	visited := make(map[string]bool)
+	var expand func(refs []types.XSDAttributeGroupRef)
+	expand = func(refs []types.XSDAttributeGroupRef) {
+		for _, ref := range refs {
+			name := types.LocalName(ref.Ref)
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+			for _, group := range v.schema.AttributeGroups {
+				if group.Name == name {
+					attributes = append(attributes, group.Attributes...)
+					expand(group.AttributeGroups)
+					break
+				}
+			}
+		}
+	}
+	expand(complexType.AttributeGroups)
+	for i, attr := range attributes {
+		attributes[i] = v.resolveAttributeRef(attr)
+	}
+	return attributes
+}
+
+// resolveAttributeRef returns the global declaration an attribute reference
+// points to, with the use given at the reference
+func (v *XSDValidator) resolveAttributeRef(attrDef types.XSDAttribute) types.XSDAttribute {
+	if attrDef.Ref == "" {
+		return attrDef
+	}
+	name := types.LocalName(attrDef.Ref)
+	for _, attr := range v.schema.Attributes {
+		if attr.Name == name {
+			attr.Use = attrDef.Use
+			if attrDef.Default != "" {
+				attr.Default = attrDef.Default
+			}
+			return attr
+		}
+	}
+	attrDef.Name = name
+	return attrDef
+}
+
 // validateAttributeType validates an attribute value against its XSD type
 func (v *XSDValidator) validateAttributeType(value, xsdType, attrName string, ctx *ValidationContext) error {
-	// Remove namespace prefix from type
-	if colonIndex := strings.LastIndex(xsdType, ":"); colonIndex != -1 {
-		xsdType = xsdType[colonIndex+1:]
+	// Simple types of the schema are checked against their facets
+	if restriction := v.simpleTypeRestriction(xsdType, nil); restriction != nil {
+		return v.validateStringValue(value, restriction, attrName, ctx)
 	}
 
+	// Remove namespace prefix from type
+	xsdType = types.LocalName(xsdType)
+
 	switch xsdType {
 	case "string":
 		// String is always valid
@@ -202,16 +262,8 @@ func (v *XSDValidator) validateAttributeType(value, xsdType, attrName string, ct
 				Element: attrName,
 			})
 		}
-	case "dateTime":
-		// Simple date-time validation
-		if !v.isValidDateTime(value) {
-			ctx.errors = append(ctx.errors, ValidationError{
-				Message: fmt.Sprintf("attribute '%s' value '%s' is not a valid dateTime", attrName, value),
-				Line:    ctx.line,
-				Column:  ctx.column,
-				Element: attrName,
-			})
-		}
+	default:
+		v.validateTemporalValue(value, xsdType, nil, attrName, ctx)
 	}
 
 	return nil
@@ -235,13 +287,18 @@ func (v *XSDValidator) validateChildElements(children []XMLElement, complexType
 func (v *XSDValidator) validateSequence(children []XMLElement, sequence *types.XSDSequence, ctx *ValidationContext) error {
 	childIndex := 0
 
-	for _, elementDef := range sequence.Elements {
+	for position, elementDef := range sequence.Elements {
+		elementDef = v.resolveElementRef(elementDef)
+		childIndex = v.matchWildcards(children, childIndex, sequence.Any, position, elementDef.Name, ctx)
 		min, max := types.ParseOccurs(elementDef.MinOccurs, elementDef.MaxOccurs)
 		count := 0
 
 		// Count matching elements
 		for childIndex < len(children) {
 			if children[childIndex].XMLName.Local == elementDef.Name {
+				if !v.validateNil(children[childIndex], &elementDef, ctx) {
+					v.validateSimpleContent(children[childIndex], &elementDef, ctx)
+				}
 				count++
 				childIndex++
 				if max != -1 && count >= max {
@@ -280,6 +337,8 @@ func (v *XSDValidator) validateSequence(children []XMLElement, sequence *types.X
 		}
 	}
 
+	childIndex = v.matchWildcards(children, childIndex, sequence.Any, len(sequence.Elements), "", ctx)
+
 	// Check for unexpected elements
 	if childIndex < len(children) {
 		ctx.warnings = append(ctx.warnings, ValidationWarning{
@@ -308,14 +367,22 @@ func (v *XSDValidator) validateChoice(children []XMLElement, choice *types.XSDCh
 	for _, child := range children {
 		found := false
 		for _, choiceElement := range choice.Elements {
+			choiceElement = v.resolveElementRef(choiceElement)
 			if choiceElement.Name == child.XMLName.Local {
 				found = true
+				if v.validateNil(child, &choiceElement, ctx) {
+					break
+				}
+				v.validateSimpleContent(child, &choiceElement, ctx)
 				if err := v.validateElementStructure(child.XMLName, child.Attrs, child.Children, &choiceElement, ctx); err != nil {
 					return err
 				}
 				break
 			}
 		}
+		if !found && v.matchesWildcard(child.XMLName, choice.Any) {
+			found = true
+		}
 		if !found {
 			ctx.errors = append(ctx.errors, ValidationError{
 				Message: fmt.Sprintf("element '%s' is not allowed in choice", child.XMLName.Local),
@@ -329,16 +396,93 @@ func (v *XSDValidator) validateChoice(children []XMLElement, choice *types.XSDCh
 	return nil
 }
 
+// matchWildcards matches the children from childIndex on against the
+// wildcards standing at a position among the element particles of a
+// sequence, and returns the index of the first child left. A child named
+// like the element declared next is left to that element.
+func (v *XSDValidator) matchWildcards(children []XMLElement, childIndex int, wildcards []types.XSDAny, position int, next string, ctx *ValidationContext) int {
+	for _, wildcard := range wildcards {
+		if wildcard.Position != position {
+			continue
+		}
+		min, max := types.ParseOccurs(wildcard.MinOccurs, wildcard.MaxOccurs)
+		count := 0
+		for childIndex < len(children) && (max == -1 || count < max) {
+			name := children[childIndex].XMLName
+			if name.Local == next || !v.matchesWildcard(name, []types.XSDAny{wildcard}) {
+				break
+			}
+			count++
+			childIndex++
+		}
+		if count < min {
+			ctx.errors = append(ctx.errors, ValidationError{
+				Message: fmt.Sprintf("wildcard occurs %d times, minimum required is %d", count, min),
+				Line:    ctx.line,
+				Column:  ctx.column,
+			})
+		}
+	}
+	return childIndex
+}
+
+// matchesWildcard checks whether an element name satisfies the namespace
+// constraint of any of the given xs:any wildcards
+func (v *XSDValidator) matchesWildcard(name xml.Name, wildcards []types.XSDAny) bool {
+	for _, wildcard := range wildcards {
+		constraint := strings.Fields(wildcard.Namespace)
+		if len(constraint) == 0 {
+			return true // ##any
+		}
+		for _, ns := range constraint {
+			switch ns {
+			case "##any":
+				return true
+			case "##other":
+				if name.Space != "" && name.Space != v.schema.TargetNamespace {
+					return true
+				}
+			case "##local":
+				if name.Space == "" {
+					return true
+				}
+			case "##targetNamespace":
+				if name.Space == v.schema.TargetNamespace {
+					return true
+				}
+			default:
+				if name.Space == ns {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 // validateAll validates all elements (simplified implementation)
 func (v *XSDValidator) validateAll(children []XMLElement, all *types.XSDAll, ctx *ValidationContext) error {
 	// For all, each element can appear at most once
 	elementCounts := make(map[string]int)
 
+	elements := make([]types.XSDElement, len(all.Elements))
+	for i, elementDef := range all.Elements {
+		elements[i] = v.resolveElementRef(elementDef)
+	}
+
 	for _, child := range children {
 		elementCounts[child.XMLName.Local]++
+		for i := range elements {
+			if elements[i].Name == child.XMLName.Local {
+				if !v.validateNil(child, &elements[i], ctx) {
+					v.validateSimpleContent(child, &elements[i], ctx)
+				}
+				break
+			}
+		}
 	}
 
-	for _, elementDef := range all.Elements {
+	for _, elementDef := range elements {
 		count := elementCounts[elementDef.Name]
 		min, max := types.ParseOccurs(elementDef.MinOccurs, elementDef.MaxOccurs)
 
@@ -364,14 +508,6 @@ func (v *XSDValidator) validateAll(children []XMLElement, all *types.XSDAll, ctx
 	return nil
 }
 
-// isValidDateTime performs basic date-time validation
-func (v *XSDValidator) isValidDateTime(value string) bool {
-	// Simple regex for ISO 8601 date-time format
-	pattern := `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`
-	matched, _ := regexp.MatchString(pattern, value)
-	return matched
-}
-
 // validateBasicStructure performs comprehensive XSD validation
 func (v *XSDValidator) validateBasicStructure(content []byte) error {
 	if v.schema == nil {
@@ -401,6 +537,7 @@ func (v *XSDValidator) validateBasicStructure(content []byte) error {
 	if err := v.validateElementStructure(doc.XMLName, doc.Attrs, doc.Children, rootElement, ctx); err != nil {
 		return err
 	}
+	v.validateIdentityConstraints(XMLElement(doc), rootElement, ctx)
 
 	// Return validation errors
 	if len(ctx.errors) > 0 {
@@ -410,6 +547,23 @@ func (v *XSDValidator) validateBasicStructure(content []byte) error {
 	return nil
 }
 
+// resolveElementRef returns the global declaration an element reference
+// points to, with the occurrence given at the reference
+func (v *XSDValidator) resolveElementRef(elementDef types.XSDElement) types.XSDElement {
+	if elementDef.Ref == "" {
+		return elementDef
+	}
+	name := types.LocalName(elementDef.Ref)
+	for _, element := range v.schema.Elements {
+		if element.Name == name {
+			element.MinOccurs, element.MaxOccurs = elementDef.MinOccurs, elementDef.MaxOccurs
+			return element
+		}
+	}
+	elementDef.Name = name
+	return elementDef
+}
+
 // findElementDefinition finds an element definition in the schema
 func (v *XSDValidator) findElementDefinition(elementName string) *types.XSDElement {
 	// Check top-level elements
@@ -517,11 +671,15 @@ func (v *XSDValidator) validateAttributesComplete(xmlAttrs []xml.Attr, complexTy
 	// Create map for efficient lookup
 	attrMap := make(map[string]string)
 	for _, attr := range xmlAttrs {
+		if attr.Name.Space == types.XSINamespace {
+			continue
+		}
 		attrMap[attr.Name.Local] = attr.Value
 	}
 
 	// Validate all defined attributes
-	for _, attrDef := range complexType.Attributes {
+	attributes := v.complexTypeAttributes(complexType)
+	for _, attrDef := range attributes {
 		attrName := attrDef.Name
 		attrValue, exists := attrMap[attrName]
 
@@ -558,10 +716,10 @@ func (v *XSDValidator) validateAttributesComplete(xmlAttrs []xml.Attr, complexTy
 		}
 	}
 
-	// Check for unexpected attributes
+	// Check for unexpected attributes; xsi attributes belong to every element
 	for _, xmlAttr := range xmlAttrs {
-		found := false
-		for _, attrDef := range complexType.Attributes {
+		found := xmlAttr.Name.Space == types.XSINamespace
+		for _, attrDef := range attributes {
 			if attrDef.Name == xmlAttr.Name.Local {
 				found = true
 				break
@@ -607,10 +765,10 @@ func (v *XSDValidator) validateSimpleTypeContent(simpleType *types.XSDSimpleType
 // validateRestrictions validates XSD restrictions
 func (v *XSDValidator) validateRestrictions(restriction *types.XSDRestriction, ctx *ValidationContext) error {
 	// Validate pattern restriction
-	if restriction.Pattern != nil {
-		if _, err := regexp.Compile(restriction.Pattern.Value); err != nil {
+	for _, pattern := range restriction.Patterns {
+		if _, err := xsdregex.Compile(pattern.Value); err != nil {
 			ctx.errors = append(ctx.errors, ValidationError{
-				Message: fmt.Sprintf("invalid regex pattern '%s': %v", restriction.Pattern.Value, err),
+				Message: fmt.Sprintf("invalid regex pattern '%s': %v", pattern.Value, err),
 				Line:    ctx.line,
 				Column:  ctx.column,
 			})
@@ -770,48 +928,24 @@ func (v *XSDValidator) validateStringValue(value string, restriction *types.XSDR
 		return nil
 	}
 
-	// Validate against pattern restriction
-	if restriction.Pattern != nil {
-		matched, err := regexp.MatchString(restriction.Pattern.Value, value)
-		if err != nil {
-			ctx.errors = append(ctx.errors, ValidationError{
-				Message: fmt.Sprintf("invalid pattern '%s' for field '%s'", restriction.Pattern.Value, fieldName),
-				Line:    ctx.line,
-				Column:  ctx.column,
-				Element: fieldName,
-			})
-		} else if !matched {
-			ctx.errors = append(ctx.errors, ValidationError{
-				Message: fmt.Sprintf("value '%s' does not match pattern '%s' for field '%s'", value, restriction.Pattern.Value, fieldName),
-				Line:    ctx.line,
-				Column:  ctx.column,
-				Element: fieldName,
-			})
-		}
-	}
+	// Validate against the pattern and enumeration restrictions of the
+	// type and its base types
+	chain := v.restrictionChain(restriction)
+	v.validatePatterns(value, chain, fieldName, ctx)
+	v.validateEnumerations(value, chain, fieldName, ctx)
 
-	// Validate against enumeration restriction
-	if len(restriction.Enumerations) > 0 {
-		found := false
-		for _, enum := range restriction.Enumerations {
-			if enum.Value == value {
-				found = true
-				break
-			}
-		}
-		if !found {
-			validValues := make([]string, len(restriction.Enumerations))
-			for i, enum := range restriction.Enumerations {
-				validValues[i] = enum.Value
-			}
-			ctx.errors = append(ctx.errors, ValidationError{
-				Message: fmt.Sprintf("value '%s' is not in enumeration [%s] for field '%s'", value, strings.Join(validValues, ", "), fieldName),
-				Line:    ctx.line,
-				Column:  ctx.column,
-				Element: fieldName,
-			})
-		}
+	// Check the remaining facets as merged along the base types
+	facets, err := types.EffectiveFacets(chain)
+	if err != nil {
+		ctx.errors = append(ctx.errors, ValidationError{
+			Message: fmt.Sprintf("invalid restriction for field '%s': %v", fieldName, err),
+			Line:    ctx.line,
+			Column:  ctx.column,
+			Element: fieldName,
+		})
+		return nil
 	}
+	restriction = &facets
 
 	// Apply whiteSpace processing before length validation
 	processedValue := v.applyWhiteSpaceProcessing(value, restriction)
@@ -855,6 +989,12 @@ func (v *XSDValidator) validateStringValue(value string, restriction *types.XSDR
 		}
 	}
 
+	// Validate range and digit restrictions of numeric types
+	if isNumericType(primitiveBase(chain)) {
+		return v.validateNumericValue(strings.TrimSpace(value), restriction, fieldName, ctx)
+	}
+	v.validateTemporalValue(value, primitiveBase(chain), restriction, fieldName, ctx)
+
 	return nil
 }
 
@@ -978,8 +1118,12 @@ func (v *XSDValidator) applyWhiteSpaceProcessing(value string, restriction *type
 	if restriction == nil || restriction.WhiteSpace == nil {
 		return value
 	}
+	return normalizeWhiteSpace(value, restriction.WhiteSpace.Value)
+}
 
-	switch restriction.WhiteSpace.Value {
+// normalizeWhiteSpace applies a whiteSpace facet value to a value
+func normalizeWhiteSpace(value, whiteSpace string) string {
+	switch whiteSpace {
 	case "replace":
 		// Replace all occurrences of #x9 (tab), #xA (line feed) and #xD (carriage return) with a single space
 		value = strings.ReplaceAll(value, "\t", " ")
@@ -1068,7 +1212,10 @@ func (v *XSDValidator) GenerateValidationReport(xmlPath string) (*ValidationRepo
 	}
 
 	// Perform detailed validation
-	v.validateElement(doc.XMLName, doc.Attrs, doc.Children, rootElement, ctx)
+	if !v.validateNil(XMLElement(doc), rootElement, ctx) {
+		v.validateElement(doc.XMLName, doc.Attrs, doc.Children, rootElement, ctx)
+	}
+	v.validateIdentityConstraints(XMLElement(doc), rootElement, ctx)
 
 	// Copy validation results to report
 	report.Errors = ctx.errors
@@ -1179,21 +1326,29 @@ func (v *XSDValidator) isValidType(typeName string) bool {
 		"gYear", "gYearMonth", "gMonth", "gMonthDay", "gDay",
 	}
 
-	for _, builtin := range builtinTypes {
-		if typeName == builtin || typeName == "xs:"+builtin || typeName == "xsd:"+builtin {
-			return true
+	qname := types.ParseQName(typeName)
+	if qname.Space == "" || qname.Space == types.XSDNamespace {
+		for _, builtin := range builtinTypes {
+			if qname.Local == builtin {
+				return true
+			}
 		}
 	}
 
+	// Types from imported namespaces are not known to the validator
+	if qname.Space != "" && qname.Space != types.XSDNamespace && qname.Space != v.schema.TargetNamespace {
+		return true
+	}
+
 	// Check schema-defined types
 	for _, complexType := range v.schema.ComplexTypes {
-		if complexType.Name == typeName {
+		if complexType.Name == qname.Local {
 			return true
 		}
 	}
 
 	for _, simpleType := range v.schema.SimpleTypes {
-		if simpleType.Name == typeName {
+		if simpleType.Name == qname.Local {
 			return true
 		}
 	}
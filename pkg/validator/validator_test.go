@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+const wildcardSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:t" xmlns="urn:t" elementFormDefault="qualified">
+  <xs:element name="first">
+    <xs:complexType><xs:sequence>
+      <xs:any namespace="##other" processContents="lax"/>
+      <xs:element name="a" type="xs:string"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+  <xs:element name="between">
+    <xs:complexType><xs:sequence>
+      <xs:element name="a" type="xs:string"/>
+      <xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="2"/>
+      <xs:element name="b" type="xs:string"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+  <xs:element name="any">
+    <xs:complexType><xs:sequence>
+      <xs:any processContents="lax" minOccurs="0" maxOccurs="unbounded"/>
+      <xs:element name="b" type="xs:string"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+func TestSequenceWildcards(t *testing.T) {
+	var schema types.XSDSchema
+	if err := xml.Unmarshal([]byte(wildcardSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	v := NewXSDValidator(&schema)
+
+	tests := []struct {
+		document string
+		err      string
+	}{
+		{`<first xmlns="urn:t" xmlns:o="urn:o"><o:x/><a/></first>`, ""},
+		{`<first xmlns="urn:t"><a/></first>`, "wildcard occurs 0 times, minimum required is 1"},
+		{`<between xmlns="urn:t" xmlns:o="urn:o"><a/><o:x/><o:y/><b/></between>`, ""},
+		{`<between xmlns="urn:t"><a/><b/></between>`, ""},
+		{`<between xmlns="urn:t" xmlns:o="urn:o"><a/><o:x/><o:y/><o:z/><b/></between>`, "missing required element 'b'"},
+		{`<any xmlns="urn:t" xmlns:o="urn:o"><o:x/><c/><b/></any>`, ""},
+	}
+	for _, test := range tests {
+		err := v.ValidateXMLContent([]byte(test.document))
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: error = %v", test.document, err)
+		case test.err != "" && (err == nil || err.Error() != test.err):
+			t.Errorf("%s: error = %v, want %s", test.document, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package xsd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateTime is an xs:dateTime, such as 2024-05-01T13:20:00.250+02:00
+type DateTime struct {
+	Year, Month, Day     int
+	Hour, Minute, Second int
+	Fraction             string // digits of the fractional seconds as written
+	Zone                 Zone
+}
+
+// DateTimeOf returns the dateTime of a time.Time, with its timezone
+func DateTimeOf(t time.Time) DateTime {
+	return DateTime{
+		Year: t.Year(), Month: int(t.Month()), Day: t.Day(),
+		Hour: t.Hour(), Minute: t.Minute(), Second: t.Second(),
+		Fraction: fractionOf(t.Nanosecond()),
+		Zone:     ZoneOf(t),
+	}
+}
+
+// ParseDateTime parses the lexical form of an xs:dateTime
+func ParseDateTime(s string) (DateTime, error) {
+	l := newLexer("dateTime", s)
+	var d DateTime
+	var err error
+	if d.Year, d.Month, d.Day, err = l.date(); err != nil {
+		return DateTime{}, err
+	}
+	if err := l.expect("T"); err != nil {
+		return DateTime{}, err
+	}
+	if d.Hour, d.Minute, d.Second, d.Fraction, err = l.clock(); err != nil {
+		return DateTime{}, err
+	}
+	if d.Zone, err = l.zone(); err != nil {
+		return DateTime{}, err
+	}
+	return d, l.end()
+}
+
+// String returns the lexical form of the dateTime
+func (d DateTime) String() string {
+	return fmt.Sprintf("%s-%02d-%02dT%s%s", formatYear(d.Year), d.Month, d.Day,
+		formatClock(d.Hour, d.Minute, d.Second, d.Fraction), d.Zone)
+}
+
+// ToTime returns the instant of the dateTime. A dateTime without a
+// timezone is taken to be in loc.
+func (d DateTime) ToTime(loc *time.Location) time.Time {
+	return time.Date(d.Year, time.Month(d.Month), d.Day, d.Hour, d.Minute, d.Second,
+		nanoseconds(d.Fraction), d.Zone.Location(loc))
+}
+
+// Compare orders two dateTimes, returning -1, 0, +1 or Indeterminate
+func (d DateTime) Compare(other DateTime) int {
+	return Compare(d, other)
+}
+
+// IsZero reports whether the dateTime is the zero value
+func (d DateTime) IsZero() bool {
+	return d == DateTime{}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d DateTime) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *DateTime) UnmarshalText(text []byte) error {
+	value, err := ParseDateTime(string(text))
+	if err != nil {
+		return err
+	}
+	*d = value
+	return nil
+}
+
+func (d DateTime) order(loc *time.Location) time.Time {
+	return d.ToTime(loc)
+}
+
+func (d DateTime) zone() Zone {
+	return d.Zone
+}
+
+// Date is an xs:date, such as 2024-05-01 or 2024-05-01Z
+type Date struct {
+	Year, Month, Day int
+	Zone             Zone
+}
+
+// DateOf returns the date of a time.Time, with its timezone
+func DateOf(t time.Time) Date {
+	return Date{Year: t.Year(), Month: int(t.Month()), Day: t.Day(), Zone: ZoneOf(t)}
+}
+
+// ParseDate parses the lexical form of an xs:date
+func ParseDate(s string) (Date, error) {
+	l := newLexer("date", s)
+	var d Date
+	var err error
+	if d.Year, d.Month, d.Day, err = l.date(); err != nil {
+		return Date{}, err
+	}
+	if d.Zone, err = l.zone(); err != nil {
+		return Date{}, err
+	}
+	return d, l.end()
+}
+
+// String returns the lexical form of the date
+func (d Date) String() string {
+	return fmt.Sprintf("%s-%02d-%02d%s", formatYear(d.Year), d.Month, d.Day, d.Zone)
+}
+
+// ToTime returns the start of the date. A date without a timezone is taken
+// to be in loc.
+func (d Date) ToTime(loc *time.Location) time.Time {
+	return time.Date(d.Year, time.Month(d.Month), d.Day, 0, 0, 0, 0, d.Zone.Location(loc))
+}
+
+// Compare orders two dates, returning -1, 0, +1 or Indeterminate
+func (d Date) Compare(other Date) int {
+	return Compare(d, other)
+}
+
+// IsZero reports whether the date is the zero value
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Date) UnmarshalText(text []byte) error {
+	value, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = value
+	return nil
+}
+
+func (d Date) order(loc *time.Location) time.Time {
+	return d.ToTime(loc)
+}
+
+func (d Date) zone() Zone {
+	return d.Zone
+}
+
+// Time is an xs:time, such as 13:20:00 or 13:20:00.5-05:00
+type Time struct {
+	Hour, Minute, Second int
+	Fraction             string // digits of the fractional seconds as written
+	Zone                 Zone
+}
+
+// TimeOf returns the time of day of a time.Time, with its timezone
+func TimeOf(t time.Time) Time {
+	return Time{
+		Hour: t.Hour(), Minute: t.Minute(), Second: t.Second(),
+		Fraction: fractionOf(t.Nanosecond()),
+		Zone:     ZoneOf(t),
+	}
+}
+
+// ParseTime parses the lexical form of an xs:time
+func ParseTime(s string) (Time, error) {
+	l := newLexer("time", s)
+	var t Time
+	var err error
+	if t.Hour, t.Minute, t.Second, t.Fraction, err = l.clock(); err != nil {
+		return Time{}, err
+	}
+	if t.Zone, err = l.zone(); err != nil {
+		return Time{}, err
+	}
+	return t, l.end()
+}
+
+// String returns the lexical form of the time
+func (t Time) String() string {
+	return formatClock(t.Hour, t.Minute, t.Second, t.Fraction) + t.Zone.String()
+}
+
+// Nanosecond returns the fractional seconds in nanoseconds
+func (t Time) Nanosecond() int {
+	return nanoseconds(t.Fraction)
+}
+
+// Compare orders two times, returning -1, 0, +1 or Indeterminate
+func (t Time) Compare(other Time) int {
+	return Compare(t, other)
+}
+
+// IsZero reports whether the time is the zero value
+func (t Time) IsZero() bool {
+	return t == Time{}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (t *Time) UnmarshalText(text []byte) error {
+	value, err := ParseTime(string(text))
+	if err != nil {
+		return err
+	}
+	*t = value
+	return nil
+}
+
+func (t Time) order(loc *time.Location) time.Time {
+	return time.Date(2000, time.January, 1, t.Hour, t.Minute, t.Second, t.Nanosecond(), t.Zone.Location(loc))
+}
+
+func (t Time) zone() Zone {
+	return t.Zone
+}
+
+// date consumes the year, month and day of a date
+func (l *lexer) date() (year, month, day int, err error) {
+	if year, err = l.year(); err != nil {
+		return
+	}
+	if err = l.expect("-"); err != nil {
+		return
+	}
+	if month, err = l.field("month", 1, 12); err != nil {
+		return
+	}
+	if err = l.expect("-"); err != nil {
+		return
+	}
+	if day, err = l.field("day", 1, 31); err != nil {
+		return
+	}
+	if day > daysIn(year, month) {
+		err = l.errorf("day %02d is beyond the end of the month", day)
+	}
+	return
+}
+
+// clock consumes the hour, minute, second and fractional seconds of a
+// time of day. 24:00:00 is the end of the day.
+func (l *lexer) clock() (hour, minute, second int, fraction string, err error) {
+	if hour, err = l.field("hour", 0, 24); err != nil {
+		return
+	}
+	if err = l.expect(":"); err != nil {
+		return
+	}
+	if minute, err = l.field("minute", 0, 59); err != nil {
+		return
+	}
+	if err = l.expect(":"); err != nil {
+		return
+	}
+	if second, err = l.field("second", 0, 59); err != nil {
+		return
+	}
+	if fraction, err = l.fraction(); err != nil {
+		return
+	}
+	if hour == 24 && (minute != 0 || second != 0 || strings.Trim(fraction, "0") != "") {
+		err = l.errorf("hour 24 is only allowed as 24:00:00")
+	}
+	return
+}
+
+// formatClock writes a time of day
+func formatClock(hour, minute, second int, fraction string) string {
+	clock := fmt.Sprintf("%02d:%02d:%02d", hour, minute, second)
+	if fraction != "" {
+		clock += "." + fraction
+	}
+	return clock
+}
@@ -0,0 +1,31 @@
+package xsd
+
+import "math/big"
+
+// ParseDecimal parses the lexical form of an xs:decimal, or of a type
+// derived from it such as xs:integer, into its exact value: an optional
+// sign and digits with at most one decimal point, without an exponent
+func ParseDecimal(s string) (*big.Rat, error) {
+	l := newLexer("decimal", s)
+	negative := l.accept("-")
+	if !negative {
+		l.accept("+")
+	}
+	integral := l.digits()
+	var fraction string
+	if l.accept(".") {
+		fraction = l.digits()
+	}
+	if integral == "" && fraction == "" {
+		return nil, l.errorf("expected digits")
+	}
+	if err := l.end(); err != nil {
+		return nil, err
+	}
+	unscaled, _ := new(big.Int).SetString("0"+integral+fraction, 10)
+	if negative {
+		unscaled.Neg(unscaled)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(len(fraction))), nil)
+	return new(big.Rat).SetFrac(unscaled, scale), nil
+}
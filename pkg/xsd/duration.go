@@ -0,0 +1,218 @@
+package xsd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Duration is an xs:duration, such as P1Y2M or -PT1H30M0.5S. The
+// components are kept as written: PT36H stays 36 hours and is not turned
+// into P1DT12H.
+type Duration struct {
+	Negative                bool
+	Years, Months, Days     int
+	Hours, Minutes, Seconds int
+	Fraction                string // digits of the fractional seconds as written
+}
+
+// DurationOf returns the duration of a time.Duration in hours, minutes and
+// seconds
+func DurationOf(d time.Duration) Duration {
+	var result Duration
+	if d < 0 {
+		result.Negative, d = true, -d
+	}
+	result.Hours = int(d / time.Hour)
+	result.Minutes = int(d % time.Hour / time.Minute)
+	result.Seconds = int(d % time.Minute / time.Second)
+	result.Fraction = fractionOf(int(d % time.Second))
+	return result
+}
+
+// ParseDuration parses the lexical form of an xs:duration
+func ParseDuration(s string) (Duration, error) {
+	l := newLexer("duration", s)
+	var d Duration
+	d.Negative = l.accept("-")
+	if err := l.expect("P"); err != nil {
+		return Duration{}, err
+	}
+
+	// Each component is a number followed by its designator, in order
+	components := 0
+	component := func(target *int, designator string) error {
+		saved := l.rest
+		value, ok, err := l.number()
+		if err != nil || !ok {
+			return err
+		}
+		if !l.accept(designator) {
+			l.rest = saved
+			return nil
+		}
+		*target = value
+		components++
+		return nil
+	}
+	for _, c := range []struct {
+		target     *int
+		designator string
+	}{{&d.Years, "Y"}, {&d.Months, "M"}, {&d.Days, "D"}} {
+		if err := component(c.target, c.designator); err != nil {
+			return Duration{}, err
+		}
+	}
+	if l.accept("T") {
+		before := components
+		if err := component(&d.Hours, "H"); err != nil {
+			return Duration{}, err
+		}
+		if err := component(&d.Minutes, "M"); err != nil {
+			return Duration{}, err
+		}
+		saved := l.rest
+		if value, ok, err := l.number(); err != nil {
+			return Duration{}, err
+		} else if ok {
+			fraction, err := l.fraction()
+			if err != nil {
+				return Duration{}, err
+			}
+			if !l.accept("S") {
+				l.rest = saved
+			} else {
+				d.Seconds, d.Fraction = value, fraction
+				components++
+			}
+		}
+		if components == before {
+			return Duration{}, l.errorf("T must be followed by hours, minutes or seconds")
+		}
+	}
+	if err := l.end(); err != nil {
+		return Duration{}, err
+	}
+	if components == 0 {
+		return Duration{}, l.errorf("at least one component is required")
+	}
+	return d, nil
+}
+
+// String returns the lexical form of the duration. Zero components are
+// left out; a zero duration is PT0S.
+func (d Duration) String() string {
+	var b strings.Builder
+	if d.Negative {
+		b.WriteString("-")
+	}
+	b.WriteString("P")
+	for _, c := range []struct {
+		value      int
+		designator string
+	}{{d.Years, "Y"}, {d.Months, "M"}, {d.Days, "D"}} {
+		if c.value != 0 {
+			fmt.Fprintf(&b, "%d%s", c.value, c.designator)
+		}
+	}
+	hasSeconds := d.Seconds != 0 || d.Fraction != ""
+	if d.Hours != 0 || d.Minutes != 0 || hasSeconds {
+		b.WriteString("T")
+		if d.Hours != 0 {
+			fmt.Fprintf(&b, "%dH", d.Hours)
+		}
+		if d.Minutes != 0 {
+			fmt.Fprintf(&b, "%dM", d.Minutes)
+		}
+		if hasSeconds {
+			fmt.Fprintf(&b, "%d", d.Seconds)
+			if d.Fraction != "" {
+				b.WriteString("." + d.Fraction)
+			}
+			b.WriteString("S")
+		}
+	}
+	if b.Len() == len("P") || b.String() == "-P" {
+		return "PT0S"
+	}
+	return b.String()
+}
+
+// ToDuration returns the duration as a time.Duration. Years and months
+// have no fixed length, so ok is false for durations that have them.
+func (d Duration) ToDuration() (duration time.Duration, ok bool) {
+	if d.Years != 0 || d.Months != 0 {
+		return 0, false
+	}
+	duration = time.Duration(d.Days)*24*time.Hour +
+		time.Duration(d.Hours)*time.Hour +
+		time.Duration(d.Minutes)*time.Minute +
+		time.Duration(d.Seconds)*time.Second +
+		time.Duration(nanoseconds(d.Fraction))
+	if d.Negative {
+		duration = -duration
+	}
+	return duration, true
+}
+
+// Compare orders two durations, returning -1, 0, +1 or Indeterminate when
+// their order depends on the dateTime they are added to, as for P1M and
+// P30D
+func (d Duration) Compare(other Duration) int {
+	return Compare(d, other)
+}
+
+// IsZero reports whether the duration is the zero value
+func (d Duration) IsZero() bool {
+	return d == Duration{}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	value, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = value
+	return nil
+}
+
+func (d Duration) order(loc *time.Location) time.Time {
+	return d.from(durationStarts[0])
+}
+
+func (d Duration) zone() Zone {
+	return Zone{}
+}
+
+// from returns the instant the duration reaches from start. Months are
+// added before days, which is exact for starts on the first of a month.
+func (d Duration) from(start time.Time) time.Time {
+	sign := 1
+	if d.Negative {
+		sign = -1
+	}
+	return time.Date(start.Year()+sign*d.Years, start.Month()+time.Month(sign*d.Months), start.Day()+sign*d.Days,
+		start.Hour()+sign*d.Hours, start.Minute()+sign*d.Minutes, start.Second()+sign*d.Seconds,
+		start.Nanosecond()+sign*nanoseconds(d.Fraction), start.Location())
+}
+
+// key returns the months and seconds of the duration, which are its value
+func (d Duration) key() string {
+	months := int64(d.Years)*12 + int64(d.Months)
+	seconds := ((int64(d.Days)*24+int64(d.Hours))*60+int64(d.Minutes))*60 + int64(d.Seconds)
+	fraction := fractionOf(nanoseconds(d.Fraction))
+	if months == 0 && seconds == 0 && fraction == "" {
+		return "0"
+	}
+	sign := ""
+	if d.Negative {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%dM%d.%sS", sign, months, seconds, fraction)
+}
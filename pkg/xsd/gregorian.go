@@ -0,0 +1,318 @@
+package xsd
+
+import (
+	"fmt"
+	"time"
+)
+
+// GYearMonth is an xs:gYearMonth, a month of a year such as 2024-05
+type GYearMonth struct {
+	Year, Month int
+	Zone        Zone
+}
+
+// ParseGYearMonth parses the lexical form of an xs:gYearMonth
+func ParseGYearMonth(s string) (GYearMonth, error) {
+	l := newLexer("gYearMonth", s)
+	var g GYearMonth
+	var err error
+	if g.Year, err = l.year(); err != nil {
+		return GYearMonth{}, err
+	}
+	if err := l.expect("-"); err != nil {
+		return GYearMonth{}, err
+	}
+	if g.Month, err = l.field("month", 1, 12); err != nil {
+		return GYearMonth{}, err
+	}
+	if g.Zone, err = l.zone(); err != nil {
+		return GYearMonth{}, err
+	}
+	return g, l.end()
+}
+
+// String returns the lexical form of the gYearMonth
+func (g GYearMonth) String() string {
+	return fmt.Sprintf("%s-%02d%s", formatYear(g.Year), g.Month, g.Zone)
+}
+
+// Compare orders two gYearMonths, returning -1, 0, +1 or Indeterminate
+func (g GYearMonth) Compare(other GYearMonth) int {
+	return Compare(g, other)
+}
+
+// IsZero reports whether the gYearMonth is the zero value
+func (g GYearMonth) IsZero() bool {
+	return g == GYearMonth{}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (g GYearMonth) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (g *GYearMonth) UnmarshalText(text []byte) error {
+	value, err := ParseGYearMonth(string(text))
+	if err != nil {
+		return err
+	}
+	*g = value
+	return nil
+}
+
+func (g GYearMonth) order(loc *time.Location) time.Time {
+	return time.Date(g.Year, time.Month(g.Month), 1, 0, 0, 0, 0, g.Zone.Location(loc))
+}
+
+func (g GYearMonth) zone() Zone {
+	return g.Zone
+}
+
+// GYear is an xs:gYear, a year such as 2024
+type GYear struct {
+	Year int
+	Zone Zone
+}
+
+// ParseGYear parses the lexical form of an xs:gYear
+func ParseGYear(s string) (GYear, error) {
+	l := newLexer("gYear", s)
+	var g GYear
+	var err error
+	if g.Year, err = l.year(); err != nil {
+		return GYear{}, err
+	}
+	if g.Zone, err = l.zone(); err != nil {
+		return GYear{}, err
+	}
+	return g, l.end()
+}
+
+// String returns the lexical form of the gYear
+func (g GYear) String() string {
+	return formatYear(g.Year) + g.Zone.String()
+}
+
+// Compare orders two gYears, returning -1, 0, +1 or Indeterminate
+func (g GYear) Compare(other GYear) int {
+	return Compare(g, other)
+}
+
+// IsZero reports whether the gYear is the zero value
+func (g GYear) IsZero() bool {
+	return g == GYear{}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (g GYear) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (g *GYear) UnmarshalText(text []byte) error {
+	value, err := ParseGYear(string(text))
+	if err != nil {
+		return err
+	}
+	*g = value
+	return nil
+}
+
+func (g GYear) order(loc *time.Location) time.Time {
+	return time.Date(g.Year, time.January, 1, 0, 0, 0, 0, g.Zone.Location(loc))
+}
+
+func (g GYear) zone() Zone {
+	return g.Zone
+}
+
+// GMonthDay is an xs:gMonthDay, a day of every year such as --05-01
+type GMonthDay struct {
+	Month, Day int
+	Zone       Zone
+}
+
+// ParseGMonthDay parses the lexical form of an xs:gMonthDay. February 29
+// is allowed, as it exists in leap years.
+func ParseGMonthDay(s string) (GMonthDay, error) {
+	l := newLexer("gMonthDay", s)
+	var g GMonthDay
+	var err error
+	if err := l.expect("--"); err != nil {
+		return GMonthDay{}, err
+	}
+	if g.Month, err = l.field("month", 1, 12); err != nil {
+		return GMonthDay{}, err
+	}
+	if err := l.expect("-"); err != nil {
+		return GMonthDay{}, err
+	}
+	if g.Day, err = l.field("day", 1, daysIn(2000, g.Month)); err != nil {
+		return GMonthDay{}, err
+	}
+	if g.Zone, err = l.zone(); err != nil {
+		return GMonthDay{}, err
+	}
+	return g, l.end()
+}
+
+// String returns the lexical form of the gMonthDay
+func (g GMonthDay) String() string {
+	return fmt.Sprintf("--%02d-%02d%s", g.Month, g.Day, g.Zone)
+}
+
+// Compare orders two gMonthDays, returning -1, 0, +1 or Indeterminate
+func (g GMonthDay) Compare(other GMonthDay) int {
+	return Compare(g, other)
+}
+
+// IsZero reports whether the gMonthDay is the zero value
+func (g GMonthDay) IsZero() bool {
+	return g == GMonthDay{}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (g GMonthDay) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (g *GMonthDay) UnmarshalText(text []byte) error {
+	value, err := ParseGMonthDay(string(text))
+	if err != nil {
+		return err
+	}
+	*g = value
+	return nil
+}
+
+func (g GMonthDay) order(loc *time.Location) time.Time {
+	return time.Date(2000, time.Month(g.Month), g.Day, 0, 0, 0, 0, g.Zone.Location(loc))
+}
+
+func (g GMonthDay) zone() Zone {
+	return g.Zone
+}
+
+// GDay is an xs:gDay, a day of every month such as ---01
+type GDay struct {
+	Day  int
+	Zone Zone
+}
+
+// ParseGDay parses the lexical form of an xs:gDay
+func ParseGDay(s string) (GDay, error) {
+	l := newLexer("gDay", s)
+	var g GDay
+	var err error
+	if err := l.expect("---"); err != nil {
+		return GDay{}, err
+	}
+	if g.Day, err = l.field("day", 1, 31); err != nil {
+		return GDay{}, err
+	}
+	if g.Zone, err = l.zone(); err != nil {
+		return GDay{}, err
+	}
+	return g, l.end()
+}
+
+// String returns the lexical form of the gDay
+func (g GDay) String() string {
+	return fmt.Sprintf("---%02d%s", g.Day, g.Zone)
+}
+
+// Compare orders two gDays, returning -1, 0, +1 or Indeterminate
+func (g GDay) Compare(other GDay) int {
+	return Compare(g, other)
+}
+
+// IsZero reports whether the gDay is the zero value
+func (g GDay) IsZero() bool {
+	return g == GDay{}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (g GDay) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (g *GDay) UnmarshalText(text []byte) error {
+	value, err := ParseGDay(string(text))
+	if err != nil {
+		return err
+	}
+	*g = value
+	return nil
+}
+
+func (g GDay) order(loc *time.Location) time.Time {
+	return time.Date(2000, time.January, g.Day, 0, 0, 0, 0, g.Zone.Location(loc))
+}
+
+func (g GDay) zone() Zone {
+	return g.Zone
+}
+
+// GMonth is an xs:gMonth, a month of every year such as --05
+type GMonth struct {
+	Month int
+	Zone  Zone
+}
+
+// ParseGMonth parses the lexical form of an xs:gMonth
+func ParseGMonth(s string) (GMonth, error) {
+	l := newLexer("gMonth", s)
+	var g GMonth
+	var err error
+	if err := l.expect("--"); err != nil {
+		return GMonth{}, err
+	}
+	if g.Month, err = l.field("month", 1, 12); err != nil {
+		return GMonth{}, err
+	}
+	if g.Zone, err = l.zone(); err != nil {
+		return GMonth{}, err
+	}
+	return g, l.end()
+}
+
+// String returns the lexical form of the gMonth
+func (g GMonth) String() string {
+	return fmt.Sprintf("--%02d%s", g.Month, g.Zone)
+}
+
+// Compare orders two gMonths, returning -1, 0, +1 or Indeterminate
+func (g GMonth) Compare(other GMonth) int {
+	return Compare(g, other)
+}
+
+// IsZero reports whether the gMonth is the zero value
+func (g GMonth) IsZero() bool {
+	return g == GMonth{}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (g GMonth) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (g *GMonth) UnmarshalText(text []byte) error {
+	value, err := ParseGMonth(string(text))
+	if err != nil {
+		return err
+	}
+	*g = value
+	return nil
+}
+
+func (g GMonth) order(loc *time.Location) time.Time {
+	return time.Date(2000, time.Month(g.Month), 1, 0, 0, 0, 0, g.Zone.Location(loc))
+}
+
+func (g GMonth) zone() Zone {
+	return g.Zone
+}
@@ -0,0 +1,367 @@
+// Package xsd holds the values of the XML Schema date, time and duration
+// types: xs:dateTime, xs:date, xs:time, the partial Gregorian dates
+// xs:gYearMonth, xs:gYear, xs:gMonthDay, xs:gDay and xs:gMonth, and
+// xs:duration.
+//
+// Each type parses and formats the lexical form XSD defines, so a value
+// read from a document is written back the way it was read: a timezone is
+// kept only when the value has one, fractional seconds keep their digits
+// and partial dates stay partial. The types implement encoding.TextMarshaler
+// and encoding.TextUnmarshaler and can be used directly as fields of types
+// decoded with encoding/xml. Generated Go code uses them for the built-in
+// types, and the XML validator uses the same parsers, along with
+// ParseDecimal for exact xs:decimal values.
+package xsd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Value is a parsed date, time or duration value
+type Value interface {
+	String() string
+	// order returns the point in time the value is ordered by, taking a
+	// value without a timezone to be in loc
+	order(loc *time.Location) time.Time
+	// zone returns the timezone of the value
+	zone() Zone
+}
+
+// Indeterminate is the result of Compare for values that are neither equal
+// nor ordered, which fails every range check
+const Indeterminate = 2
+
+// parsers maps the local names of the built-in XSD date, time and duration
+// types to their parsers
+var parsers = map[string]func(string) (Value, error){
+	"dateTime":   func(s string) (Value, error) { return ParseDateTime(s) },
+	"date":       func(s string) (Value, error) { return ParseDate(s) },
+	"time":       func(s string) (Value, error) { return ParseTime(s) },
+	"gYearMonth": func(s string) (Value, error) { return ParseGYearMonth(s) },
+	"gYear":      func(s string) (Value, error) { return ParseGYear(s) },
+	"gMonthDay":  func(s string) (Value, error) { return ParseGMonthDay(s) },
+	"gDay":       func(s string) (Value, error) { return ParseGDay(s) },
+	"gMonth":     func(s string) (Value, error) { return ParseGMonth(s) },
+	"duration":   func(s string) (Value, error) { return ParseDuration(s) },
+}
+
+// IsType reports whether the built-in XSD type with the given local name
+// is one of the date, time and duration types of this package
+func IsType(typeName string) bool {
+	_, exists := parsers[typeName]
+	return exists
+}
+
+// Parse parses the lexical form of a value of the built-in XSD date, time
+// or duration type with the given local name
+func Parse(typeName, s string) (Value, error) {
+	parse, exists := parsers[typeName]
+	if !exists {
+		return nil, fmt.Errorf("xs:%s is not a date, time or duration type", typeName)
+	}
+	return parse(s)
+}
+
+// Compare orders two values of the same type, returning -1, 0, +1 or
+// Indeterminate. Values order as the XSD partial order defines: a value
+// with a timezone and one without are ordered only when they are more than
+// 14 hours apart, and never equal, and durations are ordered only when they
+// compare the same from each of the four reference dateTimes
+// 1696-09-01T00:00:00Z, 1697-02-01T00:00:00Z, 1903-03-01T00:00:00Z and
+// 1903-07-01T00:00:00Z.
+func Compare(a, b Value) int {
+	if x, ok := a.(Duration); ok {
+		if y, ok := b.(Duration); ok {
+			return compareDurations(x, y)
+		}
+	}
+	switch zoned := a.zone().Valid; {
+	case zoned == b.zone().Valid:
+		return a.order(time.UTC).Compare(b.order(time.UTC))
+	case !zoned:
+		if c := Compare(b, a); c != Indeterminate {
+			return -c
+		}
+		return Indeterminate
+	}
+	instant := a.order(time.UTC)
+	if instant.Before(b.order(earliestZone)) {
+		return -1
+	}
+	if instant.After(b.order(latestZone)) {
+		return +1
+	}
+	return Indeterminate
+}
+
+// Key returns a string identifying a value within the value space of its
+// type, for use as a map key: two values have the same key exactly when
+// Compare finds them equal
+func Key(v Value) string {
+	if d, ok := v.(Duration); ok {
+		return d.key()
+	}
+	key := v.order(time.UTC).UTC().Format("2006-01-02T15:04:05.999999999")
+	if v.zone().Valid {
+		key += "Z"
+	}
+	return key
+}
+
+// earliestZone and latestZone are the timezones that place a value without
+// one earliest and latest
+var (
+	earliestZone = time.FixedZone("+14:00", 14*60*60)
+	latestZone   = time.FixedZone("-14:00", -14*60*60)
+)
+
+// durationStarts are the dateTimes durations are added to to order them
+var durationStarts = []time.Time{
+	time.Date(1696, time.September, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1697, time.February, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1903, time.March, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1903, time.July, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// compareDurations orders two durations by the instants they reach from
+// each of durationStarts
+func compareDurations(a, b Duration) int {
+	result := a.from(durationStarts[0]).Compare(b.from(durationStarts[0]))
+	for _, start := range durationStarts[1:] {
+		if a.from(start).Compare(b.from(start)) != result {
+			return Indeterminate
+		}
+	}
+	return result
+}
+
+// Must returns the value of a successful parse and panics otherwise. It is
+// meant for values known to be valid, such as schema defaults.
+func Must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Zone is the optional timezone of a date or time value
+type Zone struct {
+	Offset int  // minutes east of UTC
+	Valid  bool // whether the value has a timezone
+}
+
+// UTC is the timezone written as Z
+var UTC = Zone{Valid: true}
+
+// ZoneOf returns the timezone of a time.Time
+func ZoneOf(t time.Time) Zone {
+	_, offset := t.Zone()
+	return Zone{Offset: offset / 60, Valid: true}
+}
+
+// String returns the lexical form of the timezone: empty, Z or ±hh:mm
+func (z Zone) String() string {
+	if !z.Valid {
+		return ""
+	}
+	if z.Offset == 0 {
+		return "Z"
+	}
+	sign, offset := '+', z.Offset
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/60, offset%60)
+}
+
+// Location returns the location of the timezone, or def when the value
+// has none
+func (z Zone) Location(def *time.Location) *time.Location {
+	if !z.Valid {
+		return def
+	}
+	if z.Offset == 0 {
+		return time.UTC
+	}
+	return time.FixedZone(z.String(), z.Offset*60)
+}
+
+// lexer reads the lexical form of a value
+type lexer struct {
+	typeName string // local name of the XSD type, for errors
+	input    string // the whole value, for errors
+	rest     string
+}
+
+// newLexer starts reading a value of an XSD type. Date, time and duration
+// values are whitespace collapsed, so surrounding whitespace is ignored.
+func newLexer(typeName, s string) *lexer {
+	return &lexer{typeName: typeName, input: s, rest: strings.TrimSpace(s)}
+}
+
+// errorf reports an invalid value
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid xs:%s %q: %s", l.typeName, l.input, fmt.Sprintf(format, args...))
+}
+
+// accept consumes the prefix if the remaining input starts with it
+func (l *lexer) accept(prefix string) bool {
+	if strings.HasPrefix(l.rest, prefix) {
+		l.rest = l.rest[len(prefix):]
+		return true
+	}
+	return false
+}
+
+// expect consumes a required prefix
+func (l *lexer) expect(prefix string) error {
+	if !l.accept(prefix) {
+		return l.errorf("expected %q", prefix)
+	}
+	return nil
+}
+
+// digits consumes a run of decimal digits and returns them as written
+func (l *lexer) digits() string {
+	end := 0
+	for end < len(l.rest) && l.rest[end] >= '0' && l.rest[end] <= '9' {
+		end++
+	}
+	digits := l.rest[:end]
+	l.rest = l.rest[end:]
+	return digits
+}
+
+// field consumes exactly two digits within [min, max]
+func (l *lexer) field(name string, min, max int) (int, error) {
+	digits := l.digits()
+	if len(digits) != 2 {
+		return 0, l.errorf("%s must have two digits", name)
+	}
+	value, _ := strconv.Atoi(digits)
+	if value < min || value > max {
+		return 0, l.errorf("%s %s out of range", name, digits)
+	}
+	return value, nil
+}
+
+// number consumes the digits of a duration component
+func (l *lexer) number() (int, bool, error) {
+	digits := l.digits()
+	if digits == "" {
+		return 0, false, nil
+	}
+	value, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false, l.errorf("%s is too large", digits)
+	}
+	return value, true, nil
+}
+
+// year consumes a year: an optional minus sign and at least four digits,
+// without leading zeros beyond four. XSD 1.0 has no year 0000.
+func (l *lexer) year() (int, error) {
+	negative := l.accept("-")
+	digits := l.digits()
+	if len(digits) < 4 {
+		return 0, l.errorf("year must have at least four digits")
+	}
+	if len(digits) > 4 && digits[0] == '0' {
+		return 0, l.errorf("year %s has leading zeros", digits)
+	}
+	value, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, l.errorf("year %s is too large", digits)
+	}
+	if value == 0 {
+		return 0, l.errorf("year 0000 is not allowed")
+	}
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+// fraction consumes optional fractional seconds and returns their digits
+func (l *lexer) fraction() (string, error) {
+	if !l.accept(".") {
+		return "", nil
+	}
+	digits := l.digits()
+	if digits == "" {
+		return "", l.errorf("fractional seconds need at least one digit")
+	}
+	return digits, nil
+}
+
+// zone consumes an optional timezone
+func (l *lexer) zone() (Zone, error) {
+	if l.accept("Z") {
+		return UTC, nil
+	}
+	sign := 1
+	switch {
+	case l.accept("+"):
+	case l.accept("-"):
+		sign = -1
+	default:
+		return Zone{}, nil
+	}
+	hours, err := l.field("timezone hour", 0, 14)
+	if err != nil {
+		return Zone{}, err
+	}
+	if err := l.expect(":"); err != nil {
+		return Zone{}, err
+	}
+	minutes, err := l.field("timezone minute", 0, 59)
+	if err != nil {
+		return Zone{}, err
+	}
+	if hours == 14 && minutes != 0 {
+		return Zone{}, l.errorf("timezone is beyond ±14:00")
+	}
+	return Zone{Offset: sign * (hours*60 + minutes), Valid: true}, nil
+}
+
+// end reports input left over after the value
+func (l *lexer) end() error {
+	if l.rest != "" {
+		return l.errorf("unexpected %q", l.rest)
+	}
+	return nil
+}
+
+// formatYear writes a year with at least four digits
+func formatYear(year int) string {
+	if year < 0 {
+		return fmt.Sprintf("-%04d", -year)
+	}
+	return fmt.Sprintf("%04d", year)
+}
+
+// daysIn returns the number of days of a month in a year
+func daysIn(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nanoseconds converts the digits of fractional seconds to nanoseconds,
+// dropping digits beyond the ninth
+func nanoseconds(fraction string) int {
+	if len(fraction) > 9 {
+		fraction = fraction[:9]
+	}
+	value, _ := strconv.Atoi(fraction + strings.Repeat("0", 9-len(fraction)))
+	return value
+}
+
+// fractionOf returns the digits of the fractional seconds of nanoseconds
+func fractionOf(nanosecond int) string {
+	if nanosecond == 0 {
+		return ""
+	}
+	return strings.TrimRight(fmt.Sprintf("%09d", nanosecond), "0")
+}
@@ -0,0 +1,161 @@
+package xsd
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		typeName, value string
+		valid           bool
+	}{
+		// Leap days
+		{"date", "2024-02-29", true},
+		{"date", "2000-02-29", true},
+		{"date", "2023-02-29", false},
+		{"date", "1900-02-29", false},
+		{"date", "2024-04-31", false},
+		{"gMonthDay", "--02-29", true},
+		{"gMonthDay", "--02-30", false},
+
+		// End of the day
+		{"time", "24:00:00", true},
+		{"time", "24:00:00.000", true},
+		{"time", "24:00:00.0000000001", false},
+		{"time", "24:00:01", false},
+		{"time", "24:30:00", false},
+		{"time", "25:00:00", false},
+		{"dateTime", "2024-12-31T24:00:00Z", true},
+
+		// Years
+		{"date", "0000-01-01", false},
+		{"gYear", "0000", false},
+		{"gYear", "-0001", true},
+		{"gYear", "12024", true},
+		{"gYear", "02024", false},
+		{"gYear", "024", false},
+
+		// Timezone bounds
+		{"dateTime", "2024-01-01T00:00:00+14:00", true},
+		{"dateTime", "2024-01-01T00:00:00-14:00", true},
+		{"dateTime", "2024-01-01T00:00:00+13:59", true},
+		{"dateTime", "2024-01-01T00:00:00+14:01", false},
+		{"dateTime", "2024-01-01T00:00:00-15:00", false},
+		{"dateTime", "2024-01-01T00:00:00+05:60", false},
+		{"dateTime", "2024-01-01T00:00:00+5:00", false},
+
+		// Durations need a component, and T needs a time component
+		{"duration", "P", false},
+		{"duration", "-P", false},
+		{"duration", "PT", false},
+		{"duration", "P1YT", false},
+		{"duration", "P1Y", true},
+		{"duration", "PT0S", true},
+		{"duration", "-P1DT0.5S", true},
+		{"duration", "P1.5Y", false},
+		{"duration", "PT1S1M", false},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.typeName, test.value)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("Parse(%q, %q) error = %v, want valid %v", test.typeName, test.value, err, test.valid)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		typeName, a, b string
+		want           int
+	}{
+		// Durations are ordered only when every reference dateTime agrees
+		{"duration", "P1Y", "P12M", 0},
+		{"duration", "P1D", "PT24H", 0},
+		{"duration", "P1M", "P27D", +1},
+		{"duration", "P1M", "P28D", Indeterminate},
+		{"duration", "P1M", "P30D", Indeterminate},
+		{"duration", "P1M", "P31D", Indeterminate},
+		{"duration", "P1M", "P32D", -1},
+		{"duration", "P5M", "P153D", Indeterminate},
+		{"duration", "-P1M", "-P32D", +1},
+		{"duration", "-PT0S", "P0D", 0},
+		{"duration", "PT90M", "PT1H30M", 0},
+
+		// Values with and without a timezone are 14 hours apart at most
+		{"dateTime", "2000-01-01T12:00:00Z", "2000-01-01T13:00:00+01:00", 0},
+		{"dateTime", "2000-01-15T12:00:00", "2000-01-16T12:00:00Z", -1},
+		{"dateTime", "2000-01-16T12:00:00Z", "2000-01-15T12:00:00", +1},
+		{"dateTime", "2000-01-01T12:00:00", "1999-12-31T23:00:00Z", Indeterminate},
+		{"dateTime", "2000-01-01T12:00:00", "2000-01-01T12:00:00Z", Indeterminate},
+		{"dateTime", "2000-01-01T12:00:00", "2000-01-02T02:00:01Z", -1},
+		{"dateTime", "2000-01-01T12:00:00", "2000-01-01T12:00:00", 0},
+		{"dateTime", "1999-12-31T24:00:00", "2000-01-01T00:00:00", 0},
+		{"date", "2000-01-01+14:00", "1999-12-31-10:00", 0},
+		{"date", "2000-01-01Z", "2000-01-01", Indeterminate},
+		{"time", "12:00:00-05:00", "17:00:00Z", 0},
+		{"time", "12:00:00", "23:00:00Z", Indeterminate},
+		{"gYear", "2000", "2000Z", Indeterminate},
+		{"gYear", "2000", "2001Z", -1},
+	}
+	for _, test := range tests {
+		a := Must(Parse(test.typeName, test.a))
+		b := Must(Parse(test.typeName, test.b))
+		if got := Compare(a, b); got != test.want {
+			t.Errorf("Compare(%s %q, %q) = %d, want %d", test.typeName, test.a, test.b, got, test.want)
+		}
+		if equal := Key(a) == Key(b); equal != (test.want == 0) {
+			t.Errorf("Key(%s %q) = %q and Key(%q) = %q, want equal %v", test.typeName, test.a, Key(a), test.b, Key(b), test.want == 0)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		typeName, value, want string
+	}{
+		{"dateTime", "2024-05-01T13:20:00.250+02:00", "2024-05-01T13:20:00.250+02:00"},
+		{"dateTime", "2024-12-31T24:00:00", "2024-12-31T24:00:00"},
+		{"date", " -0044-03-15Z ", "-0044-03-15Z"},
+		{"time", "00:00:00-14:00", "00:00:00-14:00"},
+		{"gMonthDay", "--02-29", "--02-29"},
+		{"duration", "PT36H", "PT36H"},
+		{"duration", "-P0D", "PT0S"},
+	}
+	for _, test := range tests {
+		value := Must(Parse(test.typeName, test.value))
+		if got := value.String(); got != test.want {
+			t.Errorf("Parse(%q, %q).String() = %q, want %q", test.typeName, test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		value, want string // want is the value as a fraction, "" if invalid
+	}{
+		{"1", "1"},
+		{"01.50", "3/2"},
+		{"1.", "1"},
+		{".5", "1/2"},
+		{"-0", "0"},
+		{"+0.000", "0"},
+		{" -12.25 ", "-49/4"},
+		{"", ""},
+		{".", ""},
+		{"-", ""},
+		{"1e5", ""},
+		{"1.2.3", ""},
+		{"NaN", ""},
+		{"INF", ""},
+		{"0x10", ""},
+	}
+	for _, test := range tests {
+		value, err := ParseDecimal(test.value)
+		switch {
+		case test.want == "" && err == nil:
+			t.Errorf("ParseDecimal(%q) = %s, want an error", test.value, value.RatString())
+		case test.want != "" && err != nil:
+			t.Errorf("ParseDecimal(%q) error = %v", test.value, err)
+		case test.want != "" && value.RatString() != test.want:
+			t.Errorf("ParseDecimal(%q) = %s, want %s", test.value, value.RatString(), test.want)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package xsdparser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// AnonymousNaming selects how the types generated for anonymous inline
+// complexType and simpleType declarations are named
+type AnonymousNaming string
+
+const (
+	// AnonymousNamingParent joins the names of all enclosing types and the
+	// element, e.g. OrderItemsItem
+	AnonymousNamingParent AnonymousNaming = "parent"
+	// AnonymousNamingShort uses the element name alone and adds enclosing
+	// names only as far as needed to tell colliding types apart
+	AnonymousNamingShort AnonymousNaming = "short"
+)
+
+// anonymousPathSeparator joins the segments of an anonymous type path. It
+// cannot occur in a Go name, so provisional names never clash with others.
+const anonymousPathSeparator = "/"
+
+// SetAnonymousNaming sets the naming strategy for anonymous types. Entries
+// of names override the strategy; they are keyed by the type's path, the
+// enclosing Go type names and the element joined by "/" (Order/Items/Item),
+// or by its parent-qualified name (OrderItemsItem).
+func (p *XSDParser) SetAnonymousNaming(strategy AnonymousNaming, names map[string]string) {
+	p.anonymousNaming = strategy
+	p.anonymousNames = names
+}
+
+// deferredClaim is a name claim made with a name derived from the
+// provisional name of an anonymous type
+type deferredClaim struct {
+	kind      types.NameKind
+	key, name string
+}
+
+// anonymousTypeName returns the provisional name of the anonymous type
+// declared at a path, the path itself, until all anonymous types are known
+func (p *XSDParser) anonymousTypeName(path []string) string {
+	key := strings.Join(path, anonymousPathSeparator)
+	if _, exists := p.anonymousPaths[key]; !exists {
+		p.anonymousPaths[key] = append([]string(nil), path...)
+	}
+	return key
+}
+
+// typePath returns the context path for declarations inside a type. The
+// path of an anonymous type is the one it was declared at.
+func (p *XSDParser) typePath(name string) []string {
+	if path, exists := p.anonymousPaths[name]; exists {
+		return path
+	}
+	return []string{name}
+}
+
+// collectAnonymousTypes runs the conversion, which records every anonymous
+// type under its path, then assigns the final names. Identical anonymous
+// types share one name and so one generated type.
+func (p *XSDParser) collectAnonymousTypes(convert func() error) error {
+	p.anonymousPaths = make(map[string][]string)
+	p.deferredClaims = nil
+	if err := convert(); err != nil {
+		return err
+	}
+
+	provisional := make(map[string]types.GoType, len(p.anonymousPaths))
+	for _, goType := range p.goTypes {
+		if _, anonymous := p.anonymousPaths[goType.Name]; anonymous {
+			provisional[goType.Name] = goType
+		}
+	}
+
+	representatives := p.mergeAnonymousTypes(provisional)
+	p.renameAnonymousTypes(provisional, representatives, p.nameAnonymousTypes(representatives))
+	return nil
+}
+
+// mergeAnonymousTypes groups structurally identical anonymous types and
+// returns the representative path of every anonymous type, the first path
+// of its group in sorted order. Types referring to merged types are compared
+// again until no more groups form.
+func (p *XSDParser) mergeAnonymousTypes(provisional map[string]types.GoType) map[string]string {
+	keys := make([]string, 0, len(p.anonymousPaths))
+	for key := range p.anonymousPaths {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	representatives := make(map[string]string, len(keys))
+	index := make(map[string]int, len(keys))
+	for i, key := range keys {
+		representatives[key] = key
+		index[key] = i
+	}
+	for changed := true; changed; {
+		changed = false
+		signatures := make(map[string]string)
+		for _, key := range keys {
+			goType, exists := provisional[key]
+			if !exists || representatives[key] != key {
+				continue
+			}
+			signature := anonymousSignature(goType, func(name string) string {
+				if representative, exists := representatives[name]; exists {
+					return fmt.Sprintf("\x01%d", index[representative])
+				}
+				return name
+			})
+			if first, exists := signatures[signature]; exists {
+				for other, representative := range representatives {
+					if representative == key {
+						representatives[other] = first
+					}
+				}
+				changed = true
+				continue
+			}
+			signatures[signature] = key
+		}
+	}
+	return representatives
+}
+
+// anonymousSignature describes the structure of an anonymous type. Field
+// types are passed through token, and the type's own name, which also
+// prefixes its constants and derived types, is blanked out, as are the
+// element name and the comment, which belong to the declaring element.
+func anonymousSignature(goType types.GoType, token func(string) string) string {
+	self := goType.Name
+	goType.Name, goType.XMLName, goType.Comment, goType.SourceFile = "", "", "", ""
+	fields := goType.Fields
+	goType.Fields = nil
+	signature := strings.ReplaceAll(fmt.Sprintf("%#v", goType), self, "\x00")
+	for _, field := range fields {
+		typeRef := types.MapTypeRef(field.Type, token)
+		field.Type = ""
+		signature += strings.ReplaceAll(fmt.Sprintf("%#v", field), self, "\x00") + typeRef
+	}
+	return signature
+}
+
+// nameAnonymousTypes assigns a Go name to every group of anonymous types,
+// avoiding the names already in the naming registry, and returns the names
+// keyed by path. A group of merged types is named after the elements it was
+// declared for rather than after one of their owners.
+func (p *XSDParser) nameAnonymousTypes(representatives map[string]string) map[string]string {
+	groups := make(map[string][]string)
+	for key, representative := range representatives {
+		groups[representative] = append(groups[representative], key)
+	}
+	heads := make([]string, 0, len(groups))
+	for representative, members := range groups {
+		sort.Strings(members)
+		heads = append(heads, representative)
+	}
+	sort.Strings(heads)
+
+	names := make(map[string]string, len(heads))
+	pending := make([]string, 0, len(heads))
+	for _, head := range heads {
+		if name, exists := p.userAnonymousName(groups[head]); exists {
+			names[head] = p.names.Claim(types.NameType, "anonymous "+head, name)
+			continue
+		}
+		pending = append(pending, head)
+	}
+
+	single := make([]string, 0, len(pending))
+	for _, head := range pending {
+		if len(groups[head]) > 1 {
+			names[head] = p.sharedAnonymousName(groups[head])
+		} else {
+			single = append(single, head)
+		}
+	}
+	if p.anonymousNaming == AnonymousNamingShort {
+		p.nameShortest(single, names)
+	} else {
+		for _, head := range single {
+			names[head] = strings.Join(p.anonymousPaths[head], "")
+		}
+	}
+
+	// Remaining clashes are settled by the registry in path order
+	for _, head := range pending {
+		names[head] = p.names.Claim(types.NameType, "anonymous "+head, names[head])
+	}
+
+	final := make(map[string]string, len(representatives))
+	for key, representative := range representatives {
+		final[key] = names[representative]
+	}
+	return final
+}
+
+// sharedAnonymousName names a group of merged types after the local names
+// of their elements: the common one (Range), two different ones joined
+// (RangeOrSpan), or else the one most of the elements have
+func (p *XSDParser) sharedAnonymousName(keys []string) string {
+	counts := make(map[string]int, len(keys))
+	locals := make([]string, 0, len(keys))
+	for _, key := range keys {
+		path := p.anonymousPaths[key]
+		local := path[len(path)-1]
+		if counts[local] == 0 {
+			locals = append(locals, local)
+		}
+		counts[local]++
+	}
+	sort.Strings(locals)
+	if len(locals) <= 2 {
+		return strings.Join(locals, "Or")
+	}
+	sort.SliceStable(locals, func(i, j int) bool { return counts[locals[i]] > counts[locals[j]] })
+	return locals[0]
+}
+
+// renameAnonymousTypes replaces the provisional names in the converted types
+// by the final ones and drops the types merged into others. The names
+// derived from provisional names are claimed in the order they were made,
+// each after the renaming of the names it was derived from. A merged type
+// whose elements differ in name leaves the element name to its fields.
+func (p *XSDParser) renameAnonymousTypes(provisional map[string]types.GoType, representatives, names map[string]string) {
+	renames := make(map[string]string, len(names)+len(p.deferredClaims))
+	for key, name := range names {
+		renames[key] = name
+	}
+	for _, claim := range p.deferredClaims {
+		replacer := anonymousReplacer(renames)
+		renames[claim.name] = p.names.Claim(claim.kind, replacer.Replace(claim.key), replacer.Replace(claim.name))
+	}
+	p.deferredClaims = nil
+
+	shared := make(map[string]bool)
+	for key, representative := range representatives {
+		if provisional[key].XMLName != provisional[representative].XMLName {
+			shared[representative] = true
+		}
+	}
+
+	replacer := anonymousReplacer(renames)
+	converted := p.goTypes
+	p.goTypes = make([]types.GoType, 0, len(converted))
+	seen := make(map[string]bool, len(converted))
+	for _, goType := range converted {
+		if representative, anonymous := representatives[goType.Name]; anonymous {
+			if representative != goType.Name {
+				continue
+			}
+			if shared[representative] {
+				goType.XMLName = ""
+			}
+		}
+		renameGoType(&goType, replacer)
+		if seen[goType.Name] {
+			continue
+		}
+		seen[goType.Name] = true
+		p.goTypes = append(p.goTypes, goType)
+	}
+}
+
+// anonymousReplacer replaces provisional names, longest first so that a
+// name is not replaced inside a longer one derived from it
+func anonymousReplacer(renames map[string]string) *strings.Replacer {
+	keys := make([]string, 0, len(renames))
+	for key := range renames {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	pairs := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key, renames[key])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// renameGoType applies a replacer to every type name and name derived from
+// one in a converted type
+func renameGoType(goType *types.GoType, replacer *strings.Replacer) {
+	goType.Name = replacer.Replace(goType.Name)
+	goType.Comment = replacer.Replace(goType.Comment)
+	goType.BaseType = replacer.Replace(goType.BaseType)
+	goType.ListItemType = replacer.Replace(goType.ListItemType)
+	goType.MemberInterface = replacer.Replace(goType.MemberInterface)
+	goType.Fields = append([]types.GoField(nil), goType.Fields...)
+	for i := range goType.Fields {
+		goType.Fields[i].Type = replacer.Replace(goType.Fields[i].Type)
+		goType.Fields[i].Comment = replacer.Replace(goType.Fields[i].Comment)
+	}
+	goType.Constants = append([]types.GoConstant(nil), goType.Constants...)
+	for i := range goType.Constants {
+		goType.Constants[i].Name = replacer.Replace(goType.Constants[i].Name)
+		goType.Constants[i].Comment = replacer.Replace(goType.Constants[i].Comment)
+	}
+	goType.UnionMembers = append([]types.GoUnionMember(nil), goType.UnionMembers...)
+	for i := range goType.UnionMembers {
+		goType.UnionMembers[i].TypeName = replacer.Replace(goType.UnionMembers[i].TypeName)
+	}
+	goType.Members = append([]types.GoSubstitutionMember(nil), goType.Members...)
+	for i := range goType.Members {
+		goType.Members[i].TypeName = replacer.Replace(goType.Members[i].TypeName)
+	}
+	goType.Implements = append([]string(nil), goType.Implements...)
+	for i := range goType.Implements {
+		goType.Implements[i] = replacer.Replace(goType.Implements[i])
+	}
+}
+
+// userAnonymousName looks up a user supplied name for any path of a group
+func (p *XSDParser) userAnonymousName(keys []string) (string, bool) {
+	for _, key := range keys {
+		if name, exists := p.anonymousNames[key]; exists {
+			return name, true
+		}
+		if name, exists := p.anonymousNames[strings.Join(p.anonymousPaths[key], "")]; exists {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// nameShortest names each type after the shortest tail of its path that no
+// other type and no registered name uses
+func (p *XSDParser) nameShortest(heads []string, names map[string]string) {
+	depth := make(map[string]int, len(heads))
+	for _, head := range heads {
+		depth[head] = 1
+	}
+	tail := func(head string) string {
+		path := p.anonymousPaths[head]
+		return strings.Join(path[len(path)-depth[head]:], "")
+	}
+
+	for {
+		users := make(map[string][]string)
+		for _, head := range heads {
+			users[tail(head)] = append(users[tail(head)], head)
+		}
+		grown := false
+		for name, clashing := range users {
+			if len(clashing) == 1 && !p.names.Used(name) {
+				continue
+			}
+			for _, head := range clashing {
+				if depth[head] < len(p.anonymousPaths[head]) {
+					depth[head]++
+					grown = true
+				}
+			}
+		}
+		if !grown {
+			break
+		}
+	}
+	for _, head := range heads {
+		names[head] = tail(head)
+	}
+}
+
+// inlineSimpleType returns the Go type for an anonymous simpleType declared
+// at a path. Restrictions without facets use their base type directly.
+func (p *XSDParser) inlineSimpleType(simpleType types.XSDSimpleType, path []string) (string, error) {
+	if restriction := simpleType.Restriction; simpleType.Union == nil && simpleType.List == nil {
+		if restriction == nil {
+			return "string", nil
+		}
+		if restriction.SimpleType == nil && !hasFacets(restriction) {
+			return p.mapXSDTypeToGo(restriction.Base), nil
+		}
+	}
+
+	name := p.anonymousTypeName(path)
+	if existing := p.findExistingType(name); existing != nil {
+		return existing.Name, nil
+	}
+	simpleType.Name = name
+	goType, err := p.convertSimpleType(simpleType)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert inline simple type %s: %v", name, err)
+	}
+	if goType == nil {
+		return "string", nil
+	}
+	p.goTypes = append(p.goTypes, *goType)
+	return goType.Name, nil
+}
@@ -0,0 +1,176 @@
+package xsdparser_test
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/xsdparser"
+)
+
+// anonymousSchema wraps declarations in a schema document
+func anonymousSchema(declarations string) string {
+	return `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:a" elementFormDefault="qualified">
+` + declarations + `
+</xs:schema>
+`
+}
+
+const anonymousRange = `<xs:complexType><xs:attribute name="low" type="xs:int"/><xs:attribute name="high" type="xs:int"/></xs:complexType>`
+
+// TestAnonymousMerging names identical anonymous types after the elements
+// declaring them rather than after one of their owners
+func TestAnonymousMerging(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		fields   map[string]string // Type.Field to field type
+		xmlNames map[string]string // type name to element name
+	}{
+		{
+			name: "same element name",
+			schema: anonymousSchema(`
+  <xs:element name="order"><xs:complexType><xs:sequence><xs:element name="range">` + anonymousRange + `</xs:element></xs:sequence></xs:complexType></xs:element>
+  <xs:element name="invoice"><xs:complexType><xs:sequence><xs:element name="range">` + anonymousRange + `</xs:element></xs:sequence></xs:complexType></xs:element>`),
+			fields:   map[string]string{"Order.Range": "Range", "Invoice.Range": "Range"},
+			xmlNames: map[string]string{"Range": "range"},
+		},
+		{
+			name: "different element names",
+			schema: anonymousSchema(`
+  <xs:element name="order"><xs:complexType><xs:sequence><xs:element name="range">` + anonymousRange + `</xs:element></xs:sequence></xs:complexType></xs:element>
+  <xs:element name="invoice"><xs:complexType><xs:sequence><xs:element name="span">` + anonymousRange + `</xs:element></xs:sequence></xs:complexType></xs:element>`),
+			fields:   map[string]string{"Order.Range": "RangeOrSpan", "Invoice.Span": "RangeOrSpan"},
+			xmlNames: map[string]string{"RangeOrSpan": ""},
+		},
+		{
+			name: "different structures",
+			schema: anonymousSchema(`
+  <xs:element name="order"><xs:complexType><xs:sequence><xs:element name="range">` + anonymousRange + `</xs:element></xs:sequence></xs:complexType></xs:element>
+  <xs:element name="invoice"><xs:complexType><xs:sequence><xs:element name="range"><xs:complexType><xs:attribute name="low" type="xs:int"/></xs:complexType></xs:element></xs:sequence></xs:complexType></xs:element>`),
+			fields:   map[string]string{"Order.Range": "OrderRange", "Invoice.Range": "InvoiceRange"},
+			xmlNames: map[string]string{"OrderRange": "range", "InvoiceRange": "range"},
+		},
+		{
+			name: "shared name taken",
+			schema: anonymousSchema(`
+  <xs:complexType name="Range"><xs:sequence><xs:element name="from" type="xs:date"/></xs:sequence></xs:complexType>
+  <xs:element name="order"><xs:complexType><xs:sequence><xs:element name="range">` + anonymousRange + `</xs:element></xs:sequence></xs:complexType></xs:element>
+  <xs:element name="invoice"><xs:complexType><xs:sequence><xs:element name="range">` + anonymousRange + `</xs:element></xs:sequence></xs:complexType></xs:element>`),
+			fields:   map[string]string{"Order.Range": "RangeType", "Invoice.Range": "RangeType"},
+			xmlNames: map[string]string{"Range": "Range", "RangeType": "range"},
+		},
+		{
+			name: "nested types and constants",
+			schema: anonymousSchema(`
+  <xs:element name="order"><xs:complexType><xs:sequence><xs:element name="state"><xs:complexType><xs:sequence>
+    <xs:element name="code"><xs:simpleType><xs:restriction base="xs:string"><xs:enumeration value="open"/><xs:enumeration value="closed"/></xs:restriction></xs:simpleType></xs:element>
+  </xs:sequence></xs:complexType></xs:element></xs:sequence></xs:complexType></xs:element>
+  <xs:element name="invoice"><xs:complexType><xs:sequence><xs:element name="state"><xs:complexType><xs:sequence>
+    <xs:element name="code"><xs:simpleType><xs:restriction base="xs:string"><xs:enumeration value="open"/><xs:enumeration value="closed"/></xs:restriction></xs:simpleType></xs:element>
+  </xs:sequence></xs:complexType></xs:element></xs:sequence></xs:complexType></xs:element>`),
+			fields:   map[string]string{"Order.State": "State", "Invoice.State": "State", "State.Code": "Code"},
+			xmlNames: map[string]string{"State": "state"},
+		},
+	}
+
+	for _, test := range tests {
+		parser, _ := parseFiles(t, map[string]string{"main.xsd": test.schema})
+		fields := make(map[string]string)
+		xmlNames := make(map[string]string)
+		constants := make(map[string]bool)
+		for _, goType := range parser.GetGoTypes() {
+			if _, exists := xmlNames[goType.Name]; exists {
+				t.Errorf("%s: type %s generated twice", test.name, goType.Name)
+			}
+			xmlNames[goType.Name] = goType.XMLName
+			for _, field := range goType.Fields {
+				fields[goType.Name+"."+field.Name] = field.Type
+			}
+			for _, constant := range goType.Constants {
+				constants[constant.Name] = true
+			}
+		}
+		for field, want := range test.fields {
+			if got := fields[field]; got != want {
+				t.Errorf("%s: field %s has type %q, want %q", test.name, field, got, want)
+			}
+		}
+		for name, want := range test.xmlNames {
+			if got, exists := xmlNames[name]; !exists || got != want {
+				t.Errorf("%s: type %s has element name %q (generated: %v), want %q", test.name, name, got, exists, want)
+			}
+		}
+		if _, exists := xmlNames["Code"]; exists && (!constants["CodeOpen"] || !constants["CodeClosed"]) {
+			t.Errorf("%s: constants %v, want CodeOpen and CodeClosed", test.name, constants)
+		}
+	}
+}
+
+// anonymousOrder and anonymousInvoice declare nested anonymous types with
+// the same element names but different content
+const (
+	anonymousOrder = `
+  <xs:element name="order"><xs:complexType><xs:sequence>
+    <xs:element name="items"><xs:complexType><xs:sequence>
+      <xs:element name="item" maxOccurs="unbounded"><xs:complexType><xs:attribute name="sku" type="xs:string"/></xs:complexType></xs:element>
+    </xs:sequence></xs:complexType></xs:element>
+    <xs:element name="note"><xs:complexType><xs:attribute name="text" type="xs:string"/></xs:complexType></xs:element>
+  </xs:sequence></xs:complexType></xs:element>`
+	anonymousInvoice = `
+  <xs:element name="invoice"><xs:complexType><xs:sequence>
+    <xs:element name="items"><xs:complexType><xs:sequence>
+      <xs:element name="item" maxOccurs="unbounded"><xs:complexType><xs:attribute name="amount" type="xs:decimal"/></xs:complexType></xs:element>
+    </xs:sequence></xs:complexType></xs:element>
+  </xs:sequence></xs:complexType></xs:element>`
+)
+
+// TestAnonymousNaming names anonymous types by the selected strategy or the
+// user supplied names, independently of the order of declaration
+func TestAnonymousNaming(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy xsdparser.AnonymousNaming
+		names    map[string]string
+		want     []string
+	}{
+		{
+			name:     "parent",
+			strategy: xsdparser.AnonymousNamingParent,
+			want:     []string{"Invoice", "InvoiceItems", "InvoiceItemsItem", "Order", "OrderItems", "OrderItemsItem", "OrderNote"},
+		},
+		{
+			name:     "short",
+			strategy: xsdparser.AnonymousNamingShort,
+			want:     []string{"Invoice", "InvoiceItems", "InvoiceItemsItem", "Note", "Order", "OrderItems", "OrderItemsItem"},
+		},
+		{
+			name:     "user names by path and by parent-qualified name",
+			strategy: xsdparser.AnonymousNamingShort,
+			names:    map[string]string{"Order/Items/Item": "LineItem", "InvoiceItemsItem": "InvoiceLine"},
+			want:     []string{"Invoice", "InvoiceItems", "InvoiceLine", "LineItem", "Note", "Order", "OrderItems"},
+		},
+	}
+
+	for _, test := range tests {
+		for _, declarations := range []string{anonymousOrder + anonymousInvoice, anonymousInvoice + anonymousOrder} {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "main.xsd"), anonymousSchema(declarations))
+			parser := xsdparser.NewUnifiedXSDParser(filepath.Join(dir, "main.xsd"), filepath.Join(dir, "out.go"), "gen")
+			parser.SetAnonymousNaming(test.strategy, test.names)
+			if err := parser.Parse(); err != nil {
+				t.Fatalf("%s: parse: %v", test.name, err)
+			}
+			got := make([]string, 0)
+			for _, goType := range parser.GetGoTypes() {
+				got = append(got, goType.Name)
+			}
+			sort.Strings(got)
+			if strings.Join(got, " ") != strings.Join(test.want, " ") {
+				t.Errorf("%s: types %v, want %v", test.name, got, test.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package xsdparser_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/generator"
+	"github.com/suifei/xsd2code/pkg/xsdparser"
+)
+
+const amountSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="payment">
+    <xs:complexType><xs:attribute name="amount" type="xs:decimal"/></xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+// decimalProgram prints, for each value, the result of ParseDecimal, its
+// String and Digits, or the error
+const decimalProgram = `package main
+
+import "fmt"
+
+func main() {
+	for _, s := range []string{"0.05", "1.", "-0", "-0.00", "+12.50", "123.456", "100", "0.0", ".5", "", ".", "-", "1e5", "1.2.3", "NaN"} {
+		d, err := ParseDecimal(s)
+		if err != nil {
+			fmt.Printf("%q error\n", s)
+			continue
+		}
+		total, fraction := d.Digits()
+		fmt.Printf("%q %s %d %d\n", s, d, total, fraction)
+	}
+	a, _ := ParseDecimal("1.50")
+	b, _ := ParseDecimal("1.5")
+	fmt.Println(a.Cmp(b), a.Rat().RatString())
+}
+`
+
+// TestDecimal runs the generated Decimal: String keeps the scale the value
+// was written with, and Digits counts as the totalDigits and fractionDigits
+// facets do
+func TestDecimal(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "amount.xsd")
+	outputPath := filepath.Join(dir, "amount.go")
+	writeFile(t, schemaPath, amountSchema)
+	writeFile(t, filepath.Join(dir, "go.mod"), "module amount\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(dir, "main.go"), decimalProgram)
+
+	parser := xsdparser.NewUnifiedXSDParser(schemaPath, outputPath, "main")
+	parser.SetArbitraryPrecision(true)
+	if err := parser.Parse(); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	config := generator.NewGeneratorConfig().SetPackage("main").SetOutput(outputPath).EnableArbitraryPrecision()
+	if err := generator.NewCodeGeneratorFactory(config).GenerateCode(parser.GetGoTypes()); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run: %v\n%s", err, output)
+	}
+	want := `"0.05" 0.05 2 2
+"1." 1 1 0
+"-0" 0 1 0
+"-0.00" 0.00 1 0
+"+12.50" 12.50 3 1
+"123.456" 123.456 6 3
+"100" 100 3 0
+"0.0" 0.0 1 0
+".5" 0.5 1 1
+"" error
+"." error
+"-" error
+"1e5" error
+"1.2.3" error
+"NaN" error
+0 3/2
+`
+	if got := string(output); got != want {
+		t.Errorf("decimals:\n%s\nwant:\n%s", got, want)
+	}
+}
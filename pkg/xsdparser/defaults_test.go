@@ -0,0 +1,102 @@
+package xsdparser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/generator"
+)
+
+const defaultsSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:t" elementFormDefault="qualified">
+  <xs:element name="config">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="retries" type="xs:int" default="3" minOccurs="0"/>
+        <xs:element name="mode" type="xs:string" default="fast" minOccurs="0"/>
+      </xs:sequence>
+      <xs:attribute name="enabled" type="xs:boolean" default="true"/>
+      <xs:attribute name="ratio" type="xs:double" default="0.5"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+// TestDefaultsUnmarshal applies the schema defaults to absent elements and
+// attributes, and only to those
+func TestDefaultsUnmarshal(t *testing.T) {
+	tests := []struct {
+		document string
+		want     string
+	}{
+		{`<config xmlns="urn:t"/>`, "3 fast true 0.5"},
+		{`<config xmlns="urn:t" enabled="false"><retries>0</retries></config>`, "0 fast false 0.5"},
+		{`<config xmlns="urn:t" ratio="2"><mode></mode></config>`, "3  true 2"},
+	}
+
+	documents := make([]string, len(tests))
+	for i, test := range tests {
+		documents[i] = "\t\t`" + test.document + "`,\n"
+	}
+	parser, dir := parseFiles(t, map[string]string{"main.xsd": defaultsSchema})
+	output := runGo(t, parser, dir, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	for _, doc := range []string{
+`+strings.Join(documents, "")+`	} {
+		var config Config
+		if err := xml.Unmarshal([]byte(doc), &config); err != nil {
+			panic(err)
+		}
+		fmt.Println(*config.Retries, *config.Mode, *config.Enabled, *config.Ratio)
+	}
+}
+`)
+
+	results := strings.Split(strings.TrimSpace(output), "\n")
+	if len(results) != len(tests) {
+		t.Fatalf("output:\n%s", output)
+	}
+	for i, test := range tests {
+		if results[i] != test.want {
+			t.Errorf("%s: got %q, want %q", test.document, results[i], test.want)
+		}
+	}
+}
+
+// TestDefaultsInitializers gives Java and C# fields their schema defaults
+// as initializers
+func TestDefaultsInitializers(t *testing.T) {
+	tests := []struct {
+		language generator.TargetLanguage
+		want     []string
+	}{
+		{generator.LanguageJava, []string{
+			"private Integer retries = 3;",
+			`private String mode = "fast";`,
+			"private Boolean enabled = true;",
+			"private Double ratio = 0.5d;",
+		}},
+		{generator.LanguageCSharp, []string{
+			"public int Retries { get; set; } = 3;",
+			`public string Mode { get; set; } = "fast";`,
+			"public bool Enabled { get; set; } = true;",
+			"public double Ratio { get; set; } = 0.5d;",
+		}},
+	}
+
+	parser, dir := parseFiles(t, map[string]string{"main.xsd": defaultsSchema})
+	for _, test := range tests {
+		source := generateSource(t, parser, dir, test.language)
+		for _, want := range test.want {
+			if !strings.Contains(source, want) {
+				t.Errorf("%s: missing %s", test.language, want)
+			}
+		}
+	}
+}
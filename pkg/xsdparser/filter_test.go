@@ -0,0 +1,147 @@
+package xsdparser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/generator"
+)
+
+const filterSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:a="urn:a" xmlns:b="urn:b" targetNamespace="urn:a" elementFormDefault="qualified">
+  <xs:import namespace="urn:b" schemaLocation="b.xsd"/>
+  <xs:complexType name="Order">
+    <xs:sequence>
+      <xs:element name="item" type="a:Item" maxOccurs="unbounded"/>
+      <xs:element name="part" type="b:Item"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Item"><xs:sequence><xs:element name="price" type="a:Price"/></xs:sequence></xs:complexType>
+  <xs:simpleType name="Price"><xs:restriction base="xs:decimal"/></xs:simpleType>
+  <xs:element name="order" type="a:Order"/>
+  <xs:element name="note" type="xs:string"/>
+  <xs:element name="memo"><xs:complexType><xs:sequence><xs:element name="text" type="xs:string"/></xs:sequence></xs:complexType></xs:element>
+</xs:schema>
+`
+
+const filterImportedSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:b="urn:b" targetNamespace="urn:b" elementFormDefault="qualified">
+  <xs:complexType name="Item"><xs:sequence><xs:element name="code" type="xs:string"/></xs:sequence></xs:complexType>
+  <xs:element name="item" type="b:Item"/>
+  <xs:element name="memo" type="b:Item"/>
+</xs:schema>
+`
+
+// TestSelectTypes checks which types include and exclude patterns select,
+// the dependencies they pull in and the excluded types still referenced
+func TestSelectTypes(t *testing.T) {
+	parser, _ := parseFiles(t, map[string]string{"main.xsd": filterSchema, "b.xsd": filterImportedSchema})
+	goTypes, elements := parser.GetGoTypes(), parser.GetElementTypes()
+
+	tests := []struct {
+		name             string
+		include, exclude []string
+		want             []string
+		references       map[string][]string // excluded type to the types referring to it
+		err              string
+	}{
+		{
+			name:    "Go name with its closure",
+			include: []string{"Item"},
+			want:    []string{"Item", "Price"},
+		},
+		{
+			name:    "global element with a named type",
+			include: []string{"order"},
+			want:    []string{"Order", "Item", "Price", "BItem"},
+		},
+		{
+			name:    "global element with an anonymous type",
+			include: []string{"{urn:a}memo"},
+			want:    []string{"Memo"},
+		},
+		{
+			name:    "qualified XML name",
+			include: []string{"{urn:b}Item"},
+			want:    []string{"BItem"},
+		},
+		{
+			name:    "XML name of one namespace",
+			include: []string{"item"},
+			want:    []string{"BItem"},
+		},
+		{
+			name:    "wildcard over Go names",
+			include: []string{"*Item"},
+			want:    []string{"Item", "Price", "BItem"},
+		},
+		{
+			name:       "excluded but referenced",
+			include:    []string{"Order"},
+			exclude:    []string{"{urn:b}Item", "Price"},
+			want:       []string{"Order", "Item"},
+			references: map[string][]string{"BItem": {"Order"}, "Price": {"Item"}},
+		},
+		{
+			name:    "exclude only",
+			exclude: []string{"Memo"},
+			want:    []string{"Order", "Item", "Price", "BItem"},
+		},
+		{
+			name:    "XML name of several namespaces",
+			include: []string{"memo"},
+			err:     `type pattern "memo" matches names of several namespaces ("urn:a", "urn:b"); qualify it as {namespace}memo`,
+		},
+		{
+			name:    "element of a built-in type",
+			include: []string{"note"},
+			err:     `include pattern "note" matches no type`,
+		},
+		{
+			name:    "unknown namespace",
+			exclude: []string{"{urn:c}Item"},
+			err:     `exclude pattern "{urn:c}Item" matches no type`,
+		},
+		{
+			name:    "unterminated namespace",
+			include: []string{"{urn:a"},
+			err:     `invalid type pattern "{urn:a": missing }`,
+		},
+		{
+			name:    "invalid pattern",
+			include: []string{"[a"},
+			err:     `invalid type pattern "[a": syntax error in pattern`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selected, references, err := generator.SelectTypes(goTypes, elements, test.include, test.exclude)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("error = %v, want %s", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			got := make([]string, 0, len(selected))
+			for _, goType := range selected {
+				got = append(got, goType.Name)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("selected %v, want %v", got, test.want)
+			}
+			gotReferences := make(map[string][]string)
+			for _, reference := range references {
+				gotReferences[reference.Type] = reference.ReferencedBy
+			}
+			if len(gotReferences) != 0 || len(test.references) != 0 {
+				if !reflect.DeepEqual(gotReferences, test.references) {
+					t.Errorf("references %v, want %v", gotReferences, test.references)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package xsdparser
+
+import (
+	"fmt"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// expandAttributes returns the attributes a type declares directly followed
+// by those its attribute group references bring in, and the attribute
+// wildcard that applies. Groups may reference other groups; a group reached
+// twice, including through a circular reference, contributes only once.
+func (p *XSDParser) expandAttributes(attributes []types.XSDAttribute, groups []types.XSDAttributeGroupRef, wildcard *types.XSDAnyAttribute) ([]types.XSDAttribute, *types.XSDAnyAttribute, error) {
+	expanded := append([]types.XSDAttribute(nil), attributes...)
+	declared := make(map[string]bool, len(attributes))
+	for _, attr := range attributes {
+		declared[attributeKey(attr)] = true
+	}
+
+	visited := make(map[types.QName]bool)
+	var expand func(refs []types.XSDAttributeGroupRef) error
+	expand = func(refs []types.XSDAttributeGroupRef) error {
+		for _, ref := range refs {
+			group, namespace := p.findAttributeGroup(types.ParseQName(ref.Ref))
+			if group == nil {
+				if p.strictMode {
+					return fmt.Errorf("attribute group %s not found", ref.Ref)
+				}
+				if p.debugMode {
+					fmt.Printf("Warning: attribute group '%s' not found\n", ref.Ref)
+				}
+				continue
+			}
+			key := types.QName{Space: namespace, Local: group.Name}
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+
+			for _, attr := range group.Attributes {
+				if declared[attributeKey(attr)] {
+					continue
+				}
+				declared[attributeKey(attr)] = true
+				expanded = append(expanded, attr)
+			}
+			if wildcard == nil {
+				wildcard = group.AnyAttribute
+			}
+			if err := p.inSchemaOf(namespace, func() error { return expand(group.AttributeGroups) }); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := expand(groups); err != nil {
+		return nil, nil, err
+	}
+	return expanded, wildcard, nil
+}
+
+// attributeKey identifies an attribute declaration or reference by name
+func attributeKey(attr types.XSDAttribute) string {
+	if attr.Name != "" {
+		return attr.Name
+	}
+	return types.LocalName(attr.Ref)
+}
+
+// findAttributeGroup looks up a named attribute group by qualified name and
+// returns it with its namespace. References without a namespace search the
+// current schema first.
+func (p *XSDParser) findAttributeGroup(qname types.QName) (*types.XSDAttributeGroup, string) {
+	namespaces := []string{qname.Space}
+	if qname.Space == "" {
+		namespaces = append([]string{p.currentNS, p.targetNamespace}, p.importedNamespaces()...)
+	}
+	for _, namespace := range namespaces {
+		schema := p.schemaForNamespace(namespace)
+		if schema == nil {
+			continue
+		}
+		for i := range schema.AttributeGroups {
+			if schema.AttributeGroups[i].Name == qname.Local {
+				return &schema.AttributeGroups[i], namespace
+			}
+		}
+	}
+	return nil, ""
+}
+
+// inSchemaOf runs fn in the context of the schema declaring a namespace, so
+// that unqualified references inside a group resolve where it was declared
+func (p *XSDParser) inSchemaOf(namespace string, fn func() error) error {
+	schema := p.schemaForNamespace(namespace)
+	if schema == nil {
+		return fn()
+	}
+	currentSchema, currentNS := p.currentSchema, p.currentNS
+	p.currentSchema, p.currentNS = schema, namespace
+	defer func() { p.currentSchema, p.currentNS = currentSchema, currentNS }()
+	return fn()
+}
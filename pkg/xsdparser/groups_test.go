@@ -0,0 +1,79 @@
+package xsdparser_test
+
+import (
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+const groupsSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:a="urn:a" xmlns:c="urn:c" targetNamespace="urn:a" elementFormDefault="qualified">
+  <xs:import namespace="urn:c" schemaLocation="c.xsd"/>
+  <xs:attributeGroup name="Identified"><xs:attribute name="id" type="xs:ID" use="required"/></xs:attributeGroup>
+  <xs:attributeGroup name="Tracked">
+    <xs:attributeGroup ref="a:Identified"/>
+    <xs:attribute name="version" type="xs:int"/>
+  </xs:attributeGroup>
+  <xs:group name="Names"><xs:sequence><xs:element name="first" type="xs:string"/><xs:group ref="a:Last"/></xs:sequence></xs:group>
+  <xs:group name="Last"><xs:sequence><xs:element name="last" type="xs:string"/></xs:sequence></xs:group>
+  <xs:complexType name="Person">
+    <xs:group ref="a:Names"/>
+    <xs:attributeGroup ref="a:Tracked"/>
+    <xs:attributeGroup ref="c:Audited"/>
+  </xs:complexType>
+  <xs:complexType name="Employee">
+    <xs:complexContent>
+      <xs:extension base="a:Person">
+        <xs:sequence><xs:group ref="c:Contact"/></xs:sequence>
+        <xs:attributeGroup ref="c:Reviewed"/>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+</xs:schema>
+`
+
+const groupsImportedSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:c" elementFormDefault="qualified">
+  <xs:attributeGroup name="Audited"><xs:attribute name="changed" type="xs:dateTime"/></xs:attributeGroup>
+  <xs:attributeGroup name="Reviewed"><xs:attribute name="reviewer" type="xs:string"/></xs:attributeGroup>
+  <xs:group name="Contact"><xs:sequence><xs:element name="email" type="xs:string" minOccurs="0"/></xs:sequence></xs:group>
+</xs:schema>
+`
+
+// TestGroupReferences expands attribute group and model group references,
+// nested and from imported namespaces, on types and their extensions
+func TestGroupReferences(t *testing.T) {
+	parser, _ := parseFiles(t, map[string]string{"main.xsd": groupsSchema, "c.xsd": groupsImportedSchema})
+	goTypes := make(map[string]types.GoType)
+	for _, goType := range parser.GetGoTypes() {
+		goTypes[goType.Name] = goType
+	}
+
+	tests := []struct {
+		typeName string
+		fields   []string // names of the fields declared by the type, @ marking attributes
+	}{
+		{"Person", []string{"First", "Last", "@Id", "@Version", "@Changed"}},
+		{"Employee", []string{"Email", "@Reviewer"}},
+	}
+	for _, test := range tests {
+		goType, exists := goTypes[test.typeName]
+		if !exists {
+			t.Errorf("type %s not generated", test.typeName)
+			continue
+		}
+		fields := make(map[string]bool, len(goType.Fields))
+		for _, field := range goType.Fields {
+			if field.IsAttribute {
+				fields["@"+field.Name] = true
+			} else {
+				fields[field.Name] = true
+			}
+		}
+		for _, want := range test.fields {
+			if !fields[want] {
+				t.Errorf("%s: field %s missing", test.typeName, want)
+			}
+		}
+	}
+}
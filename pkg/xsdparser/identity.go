@@ -0,0 +1,29 @@
+package xsdparser
+
+import (
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// identityConstraints converts the key, keyref and unique constraints
+// declared on an element
+func identityConstraints(element types.XSDElement) []types.GoIdentityConstraint {
+	declared := element.IdentityConstraints()
+	if len(declared) == 0 {
+		return nil
+	}
+	constraints := make([]types.GoIdentityConstraint, 0, len(declared))
+	for _, constraint := range declared {
+		fields := make([]string, 0, len(constraint.Fields))
+		for _, field := range constraint.Fields {
+			fields = append(fields, field.XPath)
+		}
+		constraints = append(constraints, types.GoIdentityConstraint{
+			Kind:     constraint.XMLName.Local,
+			Name:     constraint.Name,
+			Refer:    types.LocalName(constraint.Refer),
+			Selector: constraint.Selector.XPath,
+			Fields:   fields,
+		})
+	}
+	return constraints
+}
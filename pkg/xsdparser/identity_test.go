@@ -0,0 +1,101 @@
+package xsdparser_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/generator"
+	"github.com/suifei/xsd2code/pkg/xsdparser"
+)
+
+const shopSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:t" targetNamespace="urn:t" elementFormDefault="qualified">
+  <xs:element name="shop">
+    <xs:complexType><xs:sequence>
+      <xs:element name="product" maxOccurs="unbounded">
+        <xs:complexType>
+          <xs:sequence><xs:element name="released" type="xs:date" nillable="true" minOccurs="0"/></xs:sequence>
+          <xs:attribute name="price" type="xs:decimal"/>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="order" minOccurs="0" maxOccurs="unbounded">
+        <xs:complexType><xs:attribute name="price" type="xs:decimal"/></xs:complexType>
+      </xs:element>
+    </xs:sequence></xs:complexType>
+    <xs:key name="priceKey"><xs:selector xpath="t:product"/><xs:field xpath="@price"/></xs:key>
+    <xs:keyref name="priceRef" refer="t:priceKey"><xs:selector xpath="t:order"/><xs:field xpath="@price"/></xs:keyref>
+    <xs:unique name="releasedUnique"><xs:selector xpath="t:product"/><xs:field xpath="t:released"/></xs:unique>
+  </xs:element>
+</xs:schema>
+`
+
+// TestValidateReferences runs the generated ValidateReferences, which
+// compares field values in the value space of their types, as the
+// -validation option generates it
+func TestValidateReferences(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`<product price="1.0"/><order price="1"/>`, "ok"},
+		{`<product price="1.0"/><product price="1.00"/>`, `duplicate key priceKey value "1.00"`},
+		{`<product price="2"/><order price="2.5"/>`, `keyref priceRef value "2.5" does not match any priceKey key`},
+		{`<product price="1"><released>2024-01-01Z</released></product><product price="2"><released>2024-01-01+00:00</released></product>`, `duplicate unique releasedUnique value "2024-01-01Z"`},
+		{`<product price="1"><released xsi:nil="true"/></product><product price="2"><released xsi:nil="true"/></product>`, "ok"},
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.xsd"), shopSchema)
+	parser := xsdparser.NewUnifiedXSDParser(filepath.Join(dir, "main.xsd"), filepath.Join(dir, "out.go"), "main")
+	parser.SetArbitraryPrecision(true)
+	if err := parser.Parse(); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	documents := make([]string, len(tests))
+	for i, test := range tests {
+		documents[i] = "\t\t`<shop xmlns=\"urn:t\" xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\">" + test.content + "</shop>`,\n"
+	}
+	writeGoProgram(t, dir, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	for _, doc := range []string{
+`+strings.Join(documents, "")+`	} {
+		var shop Shop
+		if err := xml.Unmarshal([]byte(doc), &shop); err != nil {
+			panic(err)
+		}
+		if err := shop.ValidateReferences(); err != nil {
+			fmt.Println(err)
+		} else {
+			fmt.Println("ok")
+		}
+	}
+}
+`)
+	config := generator.NewGeneratorConfig().SetPackage("main").SetOutput(filepath.Join(dir, "out.go")).EnableArbitraryPrecision()
+	if err := generator.NewCodeGeneratorFactory(config).GenerateCode(parser.GetGoTypes()); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	validation := generator.NewCodeGenerator("main", filepath.Join(dir, "out.go"))
+	validation.SetGoTypes(parser.GetGoTypes())
+	validation.SetArbitraryPrecision(true)
+	writeFile(t, filepath.Join(dir, "validation.go"), "package main\n\n"+validation.GenerateValidationCode())
+	output := goRun(t, dir)
+
+	results := strings.Split(strings.TrimSpace(output), "\n")
+	if len(results) != len(tests) {
+		t.Fatalf("output:\n%s", output)
+	}
+	for i, test := range tests {
+		if results[i] != test.want {
+			t.Errorf("%s: ValidateReferences() = %s, want %s", test.content, results[i], test.want)
+		}
+	}
+}
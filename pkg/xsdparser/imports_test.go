@@ -0,0 +1,77 @@
+package xsdparser_test
+
+import (
+	"testing"
+)
+
+const importsPartSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:b="urn:b" xmlns:c="urn:c" targetNamespace="urn:b" elementFormDefault="qualified">
+  <xs:import namespace="urn:c" schemaLocation="c.xsd"/>
+  <xs:complexType name="Part">
+    <xs:sequence><xs:element name="sku" type="xs:string"/><xs:element name="unit" type="c:Unit"/></xs:sequence>
+    <xs:attribute name="state" type="b:State"/>
+  </xs:complexType>
+  <xs:simpleType name="State">
+    <xs:restriction base="xs:string"><xs:enumeration value="new"/><xs:enumeration value="used"/></xs:restriction>
+  </xs:simpleType>
+</xs:schema>
+`
+
+const importsUnitSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:c">
+  <xs:simpleType name="Unit"><xs:restriction base="xs:string"><xs:maxLength value="3"/></xs:restriction></xs:simpleType>
+</xs:schema>
+`
+
+// TestImportedTypes converts the types of imported namespaces, directly or
+// through other imports, and places them in their namespace
+func TestImportedTypes(t *testing.T) {
+	parser, _ := parseFiles(t, map[string]string{
+		"main.xsd": `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:a="urn:a" xmlns:b="urn:b" targetNamespace="urn:a" elementFormDefault="qualified">
+  <xs:import namespace="urn:b" schemaLocation="b.xsd"/>
+  <xs:element name="order">
+    <xs:complexType><xs:sequence><xs:element name="part" type="b:Part" maxOccurs="unbounded"/></xs:sequence></xs:complexType>
+  </xs:element>
+</xs:schema>
+`,
+		"b.xsd": importsPartSchema,
+		"c.xsd": importsUnitSchema,
+	})
+
+	tests := []struct {
+		typeName  string
+		namespace string
+		fields    map[string]string // field name to Go type
+	}{
+		{"Order", "urn:a", map[string]string{"Part": "[]Part"}},
+		{"Part", "urn:b", map[string]string{"Sku": "string", "Unit": "Unit", "State": "*State"}},
+		{"State", "urn:b", nil},
+		{"Unit", "urn:c", nil},
+	}
+	goTypes := parser.GetGoTypes()
+	for _, test := range tests {
+		found := false
+		for _, goType := range goTypes {
+			if goType.Name != test.typeName {
+				continue
+			}
+			found = true
+			if goType.Namespace != test.namespace {
+				t.Errorf("%s: namespace %q, want %q", test.typeName, goType.Namespace, test.namespace)
+			}
+			fields := make(map[string]string)
+			for _, field := range goType.Fields {
+				fields[field.Name] = field.Type
+			}
+			for name, want := range test.fields {
+				if fields[name] != want {
+					t.Errorf("%s.%s: type %q, want %q", test.typeName, name, fields[name], want)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("type %s not generated", test.typeName)
+		}
+	}
+}
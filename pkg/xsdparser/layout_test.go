@@ -0,0 +1,194 @@
+package xsdparser_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/generator"
+)
+
+const layoutSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:a="urn:a" targetNamespace="urn:a" elementFormDefault="qualified">
+  <xs:simpleType name="Status"><xs:restriction base="xs:string"><xs:enumeration value="open"/><xs:enumeration value="closed"/></xs:restriction></xs:simpleType>
+  <xs:simpleType name="Code"><xs:restriction base="xs:string"><xs:pattern value="[A-Z]{3}"/></xs:restriction></xs:simpleType>
+  <xs:complexType name="OrderLine"><xs:sequence><xs:element name="code" type="a:Code"/><xs:element name="due" type="xs:date"/></xs:sequence></xs:complexType>
+  <xs:element name="order">
+    <xs:complexType>
+      <xs:sequence><xs:element name="line" type="a:OrderLine" maxOccurs="unbounded"/></xs:sequence>
+      <xs:attribute name="status" type="a:Status"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+// TestOutputLayouts distributes the types over the files of each layout and
+// gives every file the imports of the code and generated types it uses
+func TestOutputLayouts(t *testing.T) {
+	tests := []struct {
+		language generator.TargetLanguage
+		layout   generator.OutputLayout
+		files    []string
+		imports  map[string][]string // file to import statements it contains
+		unused   map[string][]string // file to import statements it leaves out
+	}{
+		{
+			language: generator.LanguageGo,
+			layout:   generator.LayoutSingleFile,
+			files:    []string{"out.go"},
+		},
+		{
+			language: generator.LanguageGo,
+			layout:   generator.LayoutFilePerType,
+			files:    []string{"code.go", "order.go", "orderline.go", "out.go", "status.go"},
+			imports:  map[string][]string{"code.go": {`"regexp"`}, "orderline.go": {`"github.com/suifei/xsd2code/pkg/xsd"`}},
+			unused:   map[string][]string{"order.go": {`"regexp"`, `"github.com/suifei/xsd2code/pkg/xsd"`}, "status.go": {`"encoding/xml"`}},
+		},
+		{
+			language: generator.LanguageGo,
+			layout:   generator.LayoutGroupByFeature,
+			files:    []string{"out.go", "out_enums.go", "out_simple.go", "out_types.go"},
+			imports:  map[string][]string{"out_simple.go": {`"regexp"`}, "out_types.go": {`"encoding/xml"`}},
+			unused:   map[string][]string{"out_types.go": {`"regexp"`}},
+		},
+		{
+			language: generator.LanguageJava,
+			layout:   generator.LayoutFilePerType,
+			files:    []string{"Code.java", "Order.java", "OrderLine.java", "Status.java"},
+			imports:  map[string][]string{"Order.java": {"package gen;", "import java.util.*;"}},
+			unused:   map[string][]string{"OrderLine.java": {"import java.util.*;"}},
+		},
+		{
+			language: generator.LanguageJava,
+			layout:   generator.LayoutGroupByFeature,
+			files:    []string{"enums/Status.java", "simple/Code.java", "types/Order.java", "types/OrderLine.java"},
+			imports: map[string][]string{
+				"types/Order.java":     {"package gen.types;", "import gen.enums.Status;"},
+				"types/OrderLine.java": {"import gen.simple.Code;"},
+				"enums/Status.java":    {"package gen.enums;"},
+			},
+			unused: map[string][]string{"types/OrderLine.java": {"import gen.enums.Status;"}},
+		},
+		{
+			language: generator.LanguageCSharp,
+			layout:   generator.LayoutFilePerType,
+			files:    []string{"Code.cs", "Order.cs", "OrderLine.cs", "Status.cs"},
+			imports:  map[string][]string{"Order.cs": {"using System.Collections.Generic;"}},
+			unused:   map[string][]string{"Status.cs": {"using System.Xml.Serialization;"}},
+		},
+		{
+			language: generator.LanguageCSharp,
+			layout:   generator.LayoutGroupByFeature,
+			files:    []string{"out_enums.cs", "out_simple.cs", "out_types.cs"},
+			imports:  map[string][]string{"out_types.cs": {"using System.Collections.Generic;", "using System.Xml.Serialization;"}},
+			unused:   map[string][]string{"out_enums.cs": {"using System.Collections.Generic;"}},
+		},
+		{
+			language: generator.LanguagePython,
+			layout:   generator.LayoutFilePerType,
+			files:    []string{"__init__.py", "code.py", "order.py", "order_line.py", "status.py"},
+			imports: map[string][]string{
+				"order.py":      {"from .order_line import OrderLine", "from .status import Status"},
+				"order_line.py": {"from .code import Code"},
+				"__init__.py":   {"from .order import Order"},
+			},
+			unused: map[string][]string{"status.py": {"from dataclasses import dataclass"}},
+		},
+		{
+			language: generator.LanguagePython,
+			layout:   generator.LayoutGroupByFeature,
+			files:    []string{"__init__.py", "out_enums.py", "out_simple.py", "out_types.py"},
+			imports: map[string][]string{
+				"out_types.py": {"from .out_enums import Status", "from .out_simple import Code"},
+				"out_enums.py": {"from enum import Enum"},
+			},
+			unused: map[string][]string{"out_enums.py": {"from dataclasses import dataclass"}},
+		},
+	}
+
+	extensions := map[generator.TargetLanguage]string{
+		generator.LanguageGo: ".go", generator.LanguageJava: ".java", generator.LanguageCSharp: ".cs", generator.LanguagePython: ".py",
+	}
+	parser, _ := parseFiles(t, map[string]string{"main.xsd": layoutSchema})
+	for _, test := range tests {
+		name := string(test.language) + " " + string(test.layout)
+		dir := t.TempDir()
+		config := generator.NewGeneratorConfig().SetLanguage(test.language).SetPackage("gen").
+			SetOutput(filepath.Join(dir, "out"+extensions[test.language])).SetOutputLayout(test.layout)
+		if err := generator.NewCodeGeneratorFactory(config).GenerateCode(parser.GetGoTypes()); err != nil {
+			t.Fatalf("%s: generate: %v", name, err)
+		}
+
+		files := make([]string, 0)
+		filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				relative, _ := filepath.Rel(dir, path)
+				files = append(files, filepath.ToSlash(relative))
+			}
+			return err
+		})
+		sort.Strings(files)
+		if strings.Join(files, " ") != strings.Join(test.files, " ") {
+			t.Errorf("%s: files %v, want %v", name, files, test.files)
+			continue
+		}
+
+		for file, statements := range test.imports {
+			source := readGenerated(t, dir, file)
+			for _, statement := range statements {
+				if !strings.Contains(source, statement) {
+					t.Errorf("%s: %s does not contain %s", name, file, statement)
+				}
+			}
+		}
+		for file, statements := range test.unused {
+			source := readGenerated(t, dir, file)
+			for _, statement := range statements {
+				if strings.Contains(source, statement) {
+					t.Errorf("%s: %s contains unused %s", name, file, statement)
+				}
+			}
+		}
+	}
+}
+
+// TestOutputLayoutsBuild builds the split Go layouts as one package
+func TestOutputLayoutsBuild(t *testing.T) {
+	program := `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	status := StatusOpen
+	data, err := xml.Marshal(Order{Status: &status, Line: []OrderLine{{Code: "ABC"}}})
+	fmt.Println(string(data), err, Code("ABC").Validate(), Code("abc").Validate())
+}
+`
+	for _, layout := range []generator.OutputLayout{generator.LayoutFilePerType, generator.LayoutGroupByFeature} {
+		parser, dir := parseFiles(t, map[string]string{"main.xsd": layoutSchema})
+		writeGoProgram(t, dir, program)
+		config := generator.NewGeneratorConfig().SetPackage("main").SetOutput(filepath.Join(dir, "out.go")).SetOutputLayout(layout)
+		if err := generator.NewCodeGeneratorFactory(config).GenerateCode(parser.GetGoTypes()); err != nil {
+			t.Fatalf("%s: generate: %v", layout, err)
+		}
+		got := goRun(t, dir)
+		if !strings.Contains(got, `status="open"`) || !strings.Contains(got, "<code>ABC</code>") || !strings.HasSuffix(strings.TrimSpace(got), "<nil> true false") {
+			t.Errorf("%s: output %q", layout, got)
+		}
+	}
+}
+
+// readGenerated returns the content of a generated file
+func readGenerated(t *testing.T, dir, file string) string {
+	t.Helper()
+	source, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(source)
+}
@@ -0,0 +1,83 @@
+package xsdparser_test
+
+import (
+	"strings"
+	"testing"
+)
+
+const listSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:t" targetNamespace="urn:t" elementFormDefault="qualified">
+  <xs:simpleType name="PointList"><xs:list itemType="xs:double"/></xs:simpleType>
+  <xs:simpleType name="Color">
+    <xs:restriction base="xs:string"><xs:enumeration value="red"/><xs:enumeration value="blue"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Palette">
+    <xs:restriction>
+      <xs:simpleType><xs:list itemType="t:Color"/></xs:simpleType>
+      <xs:minLength value="1"/><xs:maxLength value="2"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Pair"><xs:restriction base="t:PointList"><xs:length value="2"/></xs:restriction></xs:simpleType>
+  <xs:element name="root">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="points" type="t:PointList"/>
+        <xs:element name="palette" type="t:Palette"/>
+      </xs:sequence>
+      <xs:attribute name="tags"><xs:simpleType><xs:list itemType="xs:token"/></xs:simpleType></xs:attribute>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+// TestListValidate checks the list length facets and the item values
+func TestListValidate(t *testing.T) {
+	tests := []validateCall{
+		{`PointList{}`, true},
+		{`Palette{ColorRed}`, true},
+		{`Palette{ColorRed, ColorBlue}`, true},
+		{`Palette{}`, false},
+		{`Palette{ColorRed, ColorBlue, ColorRed}`, false},
+		{`Palette{"green"}`, false},
+		{`Pair{1, 2}`, true},
+		{`Pair{1}`, false},
+	}
+	checkValidate(t, listSchema, tests)
+}
+
+// TestListText splits list values on XML whitespace when unmarshaling and
+// joins them with single spaces when marshaling
+func TestListText(t *testing.T) {
+	parser, dir := parseFiles(t, map[string]string{"main.xsd": listSchema})
+	output := runGo(t, parser, dir, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	var root Root
+	doc := "<root xmlns=\"urn:t\" tags=\" a  b \"><points> 1.5\n\t2 -3e2 </points><palette>red\tblue</palette></root>"
+	if err := xml.Unmarshal([]byte(doc), &root); err != nil {
+		panic(err)
+	}
+	fmt.Println(len(root.Points), root.Points[0], root.Points[2], len(root.Palette), root.Palette[1] == ColorBlue, len(*root.Tags))
+	var invalid Root
+	fmt.Println(xml.Unmarshal([]byte("<root xmlns=\"urn:t\"><points>1 x</points></root>"), &invalid) != nil)
+	out, err := xml.Marshal(root)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+}
+`)
+	want := []string{
+		"3 1.5 -300 2 true 2",
+		"true",
+		`<root xmlns="urn:t" tags="a b"><points>1.5 2 -300</points><palette>red blue</palette></root>`,
+	}
+	if got := strings.Split(strings.TrimSpace(output), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output:\n%s\nwant:\n%s", output, strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,99 @@
+package xsdparser_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/generator"
+)
+
+const mixedSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:t" targetNamespace="urn:t" elementFormDefault="qualified">
+  <xs:element name="para">
+    <xs:complexType mixed="true">
+      <xs:choice minOccurs="0" maxOccurs="unbounded">
+        <xs:element name="b" type="xs:string"/>
+        <xs:element name="link">
+          <xs:complexType><xs:simpleContent>
+            <xs:extension base="xs:string"><xs:attribute name="href" type="xs:string"/></xs:extension>
+          </xs:simpleContent></xs:complexType>
+        </xs:element>
+      </xs:choice>
+      <xs:attribute name="lang" type="xs:string"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+// mixedDocument interleaves text with child elements
+const mixedDocument = `<para xmlns="urn:t" lang="en">Read <b>this</b> and <link href="x">that</link>.</para>`
+
+// TestMixedContent keeps character data and child elements in document
+// order in both Go representations of mixed content
+func TestMixedContent(t *testing.T) {
+	tests := []struct {
+		mode      generator.MixedContentMode
+		print     string // statements printing the decoded para
+		want      string
+		marshaled string
+	}{
+		{
+			mode: generator.MixedContentOrdered,
+			print: `	for _, item := range para.Content {
+		switch element := item.Element.(type) {
+		case *string:
+			fmt.Printf("[%s:%s]", item.XMLName.Local, *element)
+		case *ParaLink:
+			fmt.Printf("[%s:%s:%s]", item.XMLName.Local, *element.Href, element.Value)
+		default:
+			fmt.Printf("%q", item.Text)
+		}
+	}
+	fmt.Println()`,
+			want:      `"Read "[b:this]" and "[link:x:that]"."`,
+			marshaled: `<para xmlns="urn:t" lang="en">Read <b xmlns="urn:t">this</b> and <link xmlns="urn:t" href="x">that</link>.</para>`,
+		},
+		{
+			mode:      generator.MixedContentInnerXML,
+			print:     `	fmt.Println(para.InnerXML)`,
+			want:      `Read <b>this</b> and <link href="x">that</link>.`,
+			marshaled: mixedDocument,
+		},
+	}
+
+	for _, test := range tests {
+		parser, dir := parseFiles(t, map[string]string{"main.xsd": mixedSchema})
+		writeGoProgram(t, dir, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	var para Para
+	if err := xml.Unmarshal([]byte(`+"`"+mixedDocument+"`"+`), &para); err != nil {
+		panic(err)
+	}
+	fmt.Println(*para.Lang)
+`+test.print+`
+	out, err := xml.Marshal(para)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+}
+`)
+		config := generator.NewGeneratorConfig().SetPackage("main").SetOutput(filepath.Join(dir, "out.go")).SetMixedContentMode(test.mode)
+		if err := generator.NewCodeGeneratorFactory(config).GenerateCode(parser.GetGoTypes()); err != nil {
+			t.Fatalf("%s: generate: %v", test.mode, err)
+		}
+		output := goRun(t, dir)
+
+		want := []string{"en", test.want, test.marshaled}
+		if got := strings.Split(strings.TrimSpace(output), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: output:\n%s\nwant:\n%s", test.mode, output, strings.Join(want, "\n"))
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package xsdparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// qnameAttributes are the schema attributes whose values are QNames
+var qnameAttributes = map[string]bool{
+	"type":              true,
+	"ref":               true,
+	"base":              true,
+	"itemType":          true,
+	"substitutionGroup": true,
+	"refer":             true,
+}
+
+// qnameListAttributes are the schema attributes holding lists of QNames
+var qnameListAttributes = map[string]bool{
+	"memberTypes": true,
+}
+
+// unmarshalSchema decodes a schema document, rewriting every QName-valued
+// attribute to "{namespace}local" form using the prefixes in scope where it
+// appears, and collecting all namespace declarations into schema.Xmlns
+func unmarshalSchema(content []byte) (*types.XSDSchema, error) {
+	resolver := &qnameResolver{
+		decoder:  xml.NewDecoder(bytes.NewReader(content)),
+		scopes:   []map[string]string{{"xml": types.XMLNamespace}},
+		declared: make(map[string]string),
+	}
+
+	var schema types.XSDSchema
+	if err := xml.NewTokenDecoder(resolver).Decode(&schema); err != nil {
+		return nil, err
+	}
+	schema.Xmlns = resolver.declared
+	return &schema, nil
+}
+
+// qnameResolver is an xml.TokenReader that tracks namespace scopes and
+// resolves QName attribute values as tokens pass through
+type qnameResolver struct {
+	decoder  *xml.Decoder
+	scopes   []map[string]string
+	declared map[string]string
+}
+
+// Token returns the next token with QName attribute values resolved
+func (r *qnameResolver) Token() (xml.Token, error) {
+	token, err := r.decoder.Token()
+	if err != nil {
+		return token, err
+	}
+
+	switch t := token.(type) {
+	case xml.StartElement:
+		scope := r.pushScope(t.Attr)
+		if t.Name.Space != types.XSDNamespace {
+			return t, nil
+		}
+		attrs := make([]xml.Attr, len(t.Attr))
+		copy(attrs, t.Attr)
+		for i, attr := range attrs {
+			if attr.Name.Space != "" {
+				continue
+			}
+			if qnameAttributes[attr.Name.Local] {
+				attrs[i].Value = resolveQName(attr.Value, scope)
+			} else if qnameListAttributes[attr.Name.Local] {
+				values := strings.Fields(attr.Value)
+				for j, value := range values {
+					values[j] = resolveQName(value, scope)
+				}
+				attrs[i].Value = strings.Join(values, " ")
+			}
+		}
+		t.Attr = attrs
+		return t, nil
+	case xml.EndElement:
+		if len(r.scopes) > 1 {
+			r.scopes = r.scopes[:len(r.scopes)-1]
+		}
+	}
+
+	return token, nil
+}
+
+// pushScope opens a namespace scope for an element and returns it
+func (r *qnameResolver) pushScope(attrs []xml.Attr) map[string]string {
+	parent := r.scopes[len(r.scopes)-1]
+	scope, copied := parent, false
+	for _, attr := range attrs {
+		prefix, isDecl := "", false
+		if attr.Name.Space == "xmlns" {
+			prefix, isDecl = attr.Name.Local, true
+		} else if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+			isDecl = true
+		}
+		if !isDecl {
+			continue
+		}
+		if !copied {
+			scope = make(map[string]string, len(parent)+1)
+			for k, v := range parent {
+				scope[k] = v
+			}
+			copied = true
+		}
+		scope[prefix] = attr.Value
+		// The first declaration of a prefix (normally on xs:schema) wins
+		if _, exists := r.declared[prefix]; !exists {
+			r.declared[prefix] = attr.Value
+		}
+	}
+	r.scopes = append(r.scopes, scope)
+	return scope
+}
+
+// resolveQName resolves a "prefix:local" or unprefixed QName against the
+// in-scope namespaces. Values with an unknown prefix are returned unchanged.
+func resolveQName(value string, scope map[string]string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "{") {
+		return value
+	}
+
+	prefix, local := "", value
+	if colonIndex := strings.Index(value, ":"); colonIndex != -1 {
+		prefix, local = value[:colonIndex], value[colonIndex+1:]
+	}
+
+	namespace, ok := scope[prefix]
+	if !ok {
+		return value
+	}
+	return types.QName{Space: namespace, Local: local}.String()
+}
@@ -0,0 +1,108 @@
+package xsdparser
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/generator"
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// SetNameSuffixes sets the suffixes appended to generated names that collide
+// with other names or with Go keywords
+func (p *XSDParser) SetNameSuffixes(suffixes types.NameSuffixes) {
+	p.nameSuffixes = suffixes
+}
+
+// GetNameChanges returns every generated name that had to differ from the
+// name derived from the schema
+func (p *XSDParser) GetNameChanges() []types.NameChange {
+	if p.names == nil {
+		return nil
+	}
+	return p.names.Changes()
+}
+
+// claimName registers a package-level name with the naming registry. Names
+// derived from the provisional name of an anonymous type are claimed once
+// that type is named, and are left as they are until then.
+func (p *XSDParser) claimName(kind types.NameKind, key, name string) string {
+	if strings.Contains(name, anonymousPathSeparator) {
+		p.deferredClaims = append(p.deferredClaims, deferredClaim{kind: kind, key: key, name: name})
+		return name
+	}
+	return p.names.Claim(kind, key, name)
+}
+
+// elementTypeName returns the Go name of the type of a global element with
+// an inline complex type in the namespace being converted
+func (p *XSDParser) elementTypeName(name string) string {
+	if goName, exists := p.elementTypeNames[types.QName{Space: p.currentNS, Local: name}]; exists {
+		return goName
+	}
+	return types.ToGoTypeName(name)
+}
+
+// resolveFieldNames makes the field names of every struct valid and unique.
+// Fields keep their order of declaration, so a later field gives way to an
+// earlier one, and no field may take the name of a generated method or,
+// as C# forbids it, of its own type.
+func (p *XSDParser) resolveFieldNames() {
+	for i := range p.goTypes {
+		goType := &p.goTypes[i]
+		if len(goType.Fields) == 0 {
+			continue
+		}
+		scope := p.names.Scope(goType.Name)
+		scope.Reserve(generator.ReservedFieldNames...)
+		scope.Reserve(goType.Name)
+		if goType.IsMixed {
+			scope.Reserve(generator.ReservedMixedFieldNames...)
+		}
+		for j := range goType.Fields {
+			field := &goType.Fields[j]
+			kind := types.NameField
+			if field.IsAttribute {
+				kind = types.NameAttribute
+			}
+			field.Name = scope.Claim(kind, "", field.Name)
+		}
+	}
+}
+
+// NamespacePackages maps every namespace of the converted types to a Go
+// package name. Names given in custom win; the target namespace otherwise
+// uses defaultPackage and other namespaces are named after their prefix.
+func (p *XSDParser) NamespacePackages(defaultPackage string, custom map[string]string) map[string]string {
+	packages := make(map[string]string)
+	used := make(map[string]bool)
+	for namespace, name := range custom {
+		packages[namespace] = name
+		used[name] = true
+	}
+
+	namespaces := append([]string{p.targetNamespace}, p.importedNamespaces()...)
+	for _, goType := range p.goTypes {
+		namespaces = append(namespaces, goType.Namespace)
+	}
+	for _, namespace := range namespaces {
+		if _, exists := packages[namespace]; exists {
+			continue
+		}
+		name := defaultPackage
+		if namespace != p.targetNamespace {
+			name = strings.ToLower(types.SafeIdentifier(p.namespacePrefix(namespace)))
+			if token.IsKeyword(name) {
+				name += "ns"
+			}
+			base := name
+			for i := 2; used[name]; i++ {
+				name = fmt.Sprintf("%s%d", base, i)
+			}
+		}
+		packages[namespace] = name
+		used[name] = true
+	}
+	return packages
+}
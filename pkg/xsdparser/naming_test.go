@@ -0,0 +1,71 @@
+package xsdparser_test
+
+import (
+	"strings"
+	"testing"
+)
+
+const namingSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:t" targetNamespace="urn:t" elementFormDefault="qualified">
+  <xs:complexType name="type">
+    <xs:sequence><xs:element name="id" type="xs:string"/></xs:sequence>
+    <xs:attribute name="id" type="xs:string"/>
+  </xs:complexType>
+  <xs:element name="Type">
+    <xs:complexType><xs:sequence>
+      <xs:element name="map" type="xs:string"/>
+      <xs:element name="XMLName" type="xs:string"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string"><xs:enumeration value="a-b"/><xs:enumeration value="a_b"/><xs:enumeration value="ab"/></xs:restriction>
+  </xs:simpleType>
+</xs:schema>
+`
+
+// TestNameCollisions gives colliding types, fields and constants unique
+// names, reports every rename and generates code that compiles
+func TestNameCollisions(t *testing.T) {
+	parser, dir := parseFiles(t, map[string]string{"main.xsd": namingSchema})
+
+	names := make(map[string]string)
+	for _, goType := range parser.GetGoTypes() {
+		members := make([]string, 0)
+		for _, field := range goType.Fields {
+			members = append(members, field.Name)
+		}
+		for _, constant := range goType.Constants {
+			members = append(members, constant.Name)
+		}
+		names[goType.Name] = strings.Join(members, " ")
+	}
+	tests := []struct {
+		typeName string
+		members  string
+	}{
+		{"Type", "Id IdAttr"},
+		{"TypeElement", "Map XMLNameField"},
+		{"Code", "CodeAb CodeA_b CodeAbValue"},
+	}
+	for _, test := range tests {
+		if members, exists := names[test.typeName]; !exists || members != test.members {
+			t.Errorf("type %s: members %q (generated: %v), want %q", test.typeName, members, exists, test.members)
+		}
+	}
+
+	changes := make([]string, 0)
+	for _, change := range parser.GetNameChanges() {
+		changes = append(changes, change.String())
+	}
+	want := []string{
+		"element Type -> TypeElement (collides with an existing name)",
+		"constant CodeAb -> CodeAbValue (collides with an existing name)",
+		"attribute Type.Id -> IdAttr (collides with an existing name)",
+		"field TypeElement.XMLName -> XMLNameField (collides with an existing name)",
+	}
+	if strings.Join(changes, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes:\n%s\nwant:\n%s", strings.Join(changes, "\n"), strings.Join(want, "\n"))
+	}
+
+	runGo(t, parser, dir, "package main\n\nfunc main() {}\n")
+}
@@ -0,0 +1,88 @@
+package xsdparser_test
+
+import (
+	"strings"
+	"testing"
+)
+
+const nillableSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:t" elementFormDefault="qualified">
+  <xs:element name="order">
+    <xs:complexType><xs:sequence>
+      <xs:element name="price" type="xs:decimal" nillable="true"/>
+      <xs:element name="note" type="xs:string" nillable="true" minOccurs="0"/>
+      <xs:element name="name" type="xs:string"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+// TestNillableRoundTrip keeps xsi:nil apart from empty and zero values when
+// unmarshaling and writes it back when marshaling
+func TestNillableRoundTrip(t *testing.T) {
+	tests := []struct {
+		document string
+		want     string // price, note and the marshaled document
+	}{
+		{
+			`<order xmlns="urn:t" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><price xsi:nil="true"/><note xsi:nil="true"/><name/></order>`,
+			`nil nil <order xmlns="urn:t"><price xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"></price><note xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"></note><name></name></order>`,
+		},
+		{
+			`<order xmlns="urn:t"><price>0</price><note></note><name/></order>`,
+			`0 "" <order xmlns="urn:t"><price>0</price><note></note><name></name></order>`,
+		},
+		{
+			`<order xmlns="urn:t"><price>2.5</price><name/></order>`,
+			`2.5 absent <order xmlns="urn:t"><price>2.5</price><name></name></order>`,
+		},
+	}
+
+	documents := make([]string, len(tests))
+	for i, test := range tests {
+		documents[i] = "\t\t`" + test.document + "`,\n"
+	}
+	parser, dir := parseFiles(t, map[string]string{"main.xsd": nillableSchema})
+	output := runGo(t, parser, dir, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	for _, doc := range []string{
+`+strings.Join(documents, "")+`	} {
+		var order Order
+		if err := xml.Unmarshal([]byte(doc), &order); err != nil {
+			panic(err)
+		}
+		price, note := "nil", "absent"
+		if !order.Price.Nil {
+			price = fmt.Sprint(order.Price.Value)
+		}
+		if order.Note != nil {
+			note = "nil"
+			if !order.Note.Nil {
+				note = fmt.Sprintf("%q", order.Note.Value)
+			}
+		}
+		out, err := xml.Marshal(order)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(price, note, string(out))
+	}
+}
+`)
+
+	results := strings.Split(strings.TrimSpace(output), "\n")
+	if len(results) != len(tests) {
+		t.Fatalf("output:\n%s", output)
+	}
+	for i, test := range tests {
+		if results[i] != test.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", test.document, results[i], test.want)
+		}
+	}
+}
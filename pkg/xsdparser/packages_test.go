@@ -0,0 +1,114 @@
+package xsdparser_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/generator"
+	"github.com/suifei/xsd2code/pkg/xsdparser"
+)
+
+// parseFiles writes schema files to a temporary directory and parses the
+// one named main.xsd
+func parseFiles(t *testing.T, files map[string]string) (*xsdparser.UnifiedXSDParser, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+	parser := xsdparser.NewUnifiedXSDParser(filepath.Join(dir, "main.xsd"), filepath.Join(dir, "out.go"), "gen")
+	if err := parser.Parse(); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return parser, dir
+}
+
+const packagesItemSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:b="urn:b" targetNamespace="urn:b" elementFormDefault="qualified">
+  <xs:complexType name="Part"><xs:sequence><xs:element name="sku" type="xs:string"/></xs:sequence></xs:complexType>
+</xs:schema>
+`
+
+// TestNamespacePackages places each type, including anonymous types nested
+// in other types, in the package of the namespace declaring it, and builds
+// the packages
+func TestNamespacePackages(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  map[string]string // type name to package
+	}{
+		{
+			name: "anonymous type referencing a named type",
+			files: map[string]string{"main.xsd": `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:a="urn:a" targetNamespace="urn:a" elementFormDefault="qualified">
+  <xs:complexType name="Item"><xs:sequence><xs:element name="name" type="xs:string"/></xs:sequence></xs:complexType>
+  <xs:element name="order">
+    <xs:complexType><xs:sequence>
+      <xs:element name="lines"><xs:complexType><xs:sequence><xs:element name="item" type="a:Item"/></xs:sequence></xs:complexType></xs:element>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+</xs:schema>
+`},
+			want: map[string]string{"Item": "gen", "Order": "gen", "OrderLines": "gen"},
+		},
+		{
+			name: "anonymous type referencing named types of two namespaces",
+			files: map[string]string{"main.xsd": `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:a="urn:a" xmlns:b="urn:b" targetNamespace="urn:a" elementFormDefault="qualified">
+  <xs:import namespace="urn:b" schemaLocation="b.xsd"/>
+  <xs:complexType name="Item"><xs:sequence><xs:element name="name" type="xs:string"/></xs:sequence></xs:complexType>
+  <xs:complexType name="Box">
+    <xs:sequence>
+      <xs:element name="inner"><xs:complexType><xs:sequence>
+        <xs:element name="item" type="a:Item"/>
+        <xs:element name="part" type="b:Part"/>
+      </xs:sequence></xs:complexType></xs:element>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>
+`, "b.xsd": packagesItemSchema},
+			want: map[string]string{"Item": "gen", "Box": "gen", "BoxInner": "gen", "Part": "b"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser, dir := parseFiles(t, test.files)
+			writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/out\n\ngo 1.22\n")
+			config := generator.NewGeneratorConfig().SetPackage("gen").SetOutput(filepath.Join(dir, "out.go")).
+				SetNamespacePackages(parser.NamespacePackages("gen", nil), "example.com/out")
+			factory := generator.NewCodeGeneratorFactory(config)
+
+			packages, err := factory.Packages(parser.GetGoTypes())
+			if err != nil {
+				t.Fatalf("split: %v", err)
+			}
+			got := make(map[string]string)
+			for _, pkg := range packages {
+				for _, goType := range pkg.Types {
+					got[goType.Name] = pkg.Name
+				}
+			}
+			for name, want := range test.want {
+				if got[name] != want {
+					t.Errorf("type %s is in package %q, want %q", name, got[name], want)
+				}
+			}
+
+			if err := factory.GenerateCode(parser.GetGoTypes()); err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			cmd := exec.Command(goTool, "build", "./...")
+			cmd.Dir = dir
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("go build: %v\n%s", err, output)
+			}
+		})
+	}
+}
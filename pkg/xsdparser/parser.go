@@ -1,14 +1,14 @@
 package xsdparser
 
 import (
-	"encoding/xml"
 	"fmt"
-	"io/ioutil"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/suifei/xsd2code/pkg/generator"
 	"github.com/suifei/xsd2code/pkg/types"
+	"github.com/suifei/xsd2code/pkg/xsd"
 )
 
 // XSDParser is the main parser for XSD files
@@ -19,11 +19,30 @@ type XSDParser struct {
 	packageName     string
 	targetNamespace string
 	imports         map[string]*types.XSDSchema
-	goTypes         []types.GoType
-	debugMode       bool
-	strictMode      bool
-	jsonCompatible  bool
-	includeComments bool
+	currentSchema   *types.XSDSchema
+	currentNS       string
+	schemaSet       *SchemaSet
+	rootPath        string
+	typeNames       map[types.QName]string
+	names           *types.NamingRegistry
+	nameSuffixes    types.NameSuffixes
+
+	elementTypeNames       map[types.QName]string
+	substitutionMembers    map[types.QName][]types.QName
+	substitutionGroups     map[types.QName]string
+	substitutionInterfaces map[types.QName]string
+	deriving               map[types.QName]bool
+	expandingGroups        map[types.QName]bool
+	anonymousNaming        AnonymousNaming
+	anonymousNames         map[string]string
+	anonymousPaths         map[string][]string
+	deferredClaims         []deferredClaim
+	goTypes                []types.GoType
+	debugMode              bool
+	strictMode             bool
+	jsonCompatible         bool
+	includeComments        bool
+	arbitraryPrecision     bool
 }
 
 // NewXSDParser creates a new XSD parser instance
@@ -33,6 +52,11 @@ func NewXSDParser(xsdPath, outputPath, packageName string) *XSDParser {
 		outputPath:      outputPath,
 		packageName:     packageName,
 		imports:         make(map[string]*types.XSDSchema),
+		typeNames:       make(map[types.QName]string),
+		nameSuffixes:    types.DefaultNameSuffixes(),
+		deriving:        make(map[types.QName]bool),
+		expandingGroups: make(map[types.QName]bool),
+		anonymousNaming: AnonymousNamingParent,
 		goTypes:         make([]types.GoType, 0),
 		debugMode:       false,
 		strictMode:      false,
@@ -61,6 +85,12 @@ func (p *XSDParser) SetIncludeComments(comments bool) {
 	p.includeComments = comments
 }
 
+// SetArbitraryPrecision maps xs:decimal and the integer types without a
+// fixed size to the arbitrary-precision Decimal and Integer Go types
+func (p *XSDParser) SetArbitraryPrecision(enable bool) {
+	p.arbitraryPrecision = enable
+}
+
 // Parse parses the XSD file and builds the internal representation
 func (p *XSDParser) Parse() error {
 	if p.debugMode {
@@ -85,26 +115,21 @@ func (p *XSDParser) Parse() error {
 	return nil
 }
 
-// parseFile parses a single XSD file
+// parseFile loads the root XSD file together with every schema it
+// includes or imports, directly or indirectly
 func (p *XSDParser) parseFile(filePath string) error {
-	// Read file content
-	content, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file %s: %v", filePath, err)
-	}
+	p.schemaSet = NewSchemaSet()
+	p.schemaSet.SetDebugMode(p.debugMode)
 
-	// Parse XML
-	var schema types.XSDSchema
-	if err := xml.Unmarshal(content, &schema); err != nil {
-		return fmt.Errorf("failed to parse XML in %s: %v", filePath, err)
-	}
-
-	// Parse namespaces from XML content
-	if err := p.parseNamespaces(content, &schema); err != nil {
-		return fmt.Errorf("failed to parse namespaces: %v", err)
+	root, err := p.schemaSet.Load(filePath)
+	if err != nil {
+		return err
 	}
 
+	// Work on a copy so merging includes leaves the loaded document untouched
+	schema := *root.Schema
 	p.schema = &schema
+	p.rootPath = root.Path
 	p.targetNamespace = schema.TargetNamespace
 
 	if p.debugMode {
@@ -116,210 +141,332 @@ func (p *XSDParser) parseFile(filePath string) error {
 	return nil
 }
 
-// parseNamespaces extracts namespace declarations from XML content
-func (p *XSDParser) parseNamespaces(content []byte, schema *types.XSDSchema) error {
-	// Simple namespace parsing - could be improved with proper XML parsing
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "xmlns") {
-			// Extract namespace declarations
-			// This is a simplified approach
-			break
-		}
-	}
-	return nil
-}
-
-// processImports processes import and include statements
+// processImports folds the documents of the schema set into the main schema
+// (same target namespace) or into p.imports (other namespaces)
 func (p *XSDParser) processImports() error {
 	if p.schema == nil {
 		return fmt.Errorf("schema not parsed")
 	}
 
-	// Process imports
-	for _, imp := range p.schema.Imports {
-		if err := p.processImport(imp); err != nil {
-			if p.strictMode {
-				return err
-			}
-			if p.debugMode {
-				fmt.Printf("Warning: failed to process import %s: %v\n", imp.SchemaLocation, err)
-			}
+	for _, loadErr := range p.schemaSet.Errors() {
+		if p.strictMode {
+			return loadErr
+		}
+		if p.debugMode {
+			fmt.Printf("Warning: %v\n", loadErr)
 		}
 	}
 
-	// Process includes
-	for _, inc := range p.schema.Includes {
-		if err := p.processInclude(inc); err != nil {
-			if p.strictMode {
-				return err
-			}
+	for _, doc := range p.schemaSet.Documents() {
+		if doc.Path == p.rootPath && doc.TargetNamespace == p.targetNamespace {
+			continue
+		}
+
+		if doc.TargetNamespace == p.targetNamespace {
 			if p.debugMode {
-				fmt.Printf("Warning: failed to process include %s: %v\n", inc.SchemaLocation, err)
+				fmt.Printf("Processing include: %s\n", doc.Path)
 			}
+			p.mergeSchema(p.schema, doc.Schema)
+			continue
+		}
+
+		if p.debugMode {
+			fmt.Printf("Processing import: %s -> %s\n", doc.TargetNamespace, doc.Path)
+		}
+		if existing, exists := p.imports[doc.TargetNamespace]; exists {
+			p.mergeSchema(existing, doc.Schema)
+		} else {
+			imported := *doc.Schema
+			p.imports[doc.TargetNamespace] = &imported
 		}
 	}
 
 	return nil
 }
 
-// processImport processes a single import
-func (p *XSDParser) processImport(imp types.XSDImport) error {
-	if imp.SchemaLocation == "" {
-		return nil // Skip imports without schema location
+// mergeSchema merges the global components of an included schema into target
+func (p *XSDParser) mergeSchema(target, included *types.XSDSchema) {
+	if target == nil {
+		return
 	}
 
-	// Resolve relative path
-	importPath := imp.SchemaLocation
-	if !filepath.IsAbs(importPath) {
-		baseDir := filepath.Dir(p.filePath)
-		importPath = filepath.Join(baseDir, importPath)
-	}
+	// Merge elements
+	target.Elements = append(target.Elements, included.Elements...)
 
-	// Check if already imported
-	if _, exists := p.imports[imp.Namespace]; exists {
-		return nil
-	}
+	// Merge attributes
+	target.Attributes = append(target.Attributes, included.Attributes...)
+
+	// Merge complex types
+	target.ComplexTypes = append(target.ComplexTypes, included.ComplexTypes...)
+
+	// Merge simple types
+	target.SimpleTypes = append(target.SimpleTypes, included.SimpleTypes...)
+
+	// Merge groups
+	target.Groups = append(target.Groups, included.Groups...)
+
+	// Merge attribute groups
+	target.AttributeGroups = append(target.AttributeGroups, included.AttributeGroups...)
+}
 
+// convertToGoTypes converts XSD types to Go types
+func (p *XSDParser) convertToGoTypes() error {
+	if p.schema == nil {
+		return fmt.Errorf("schema not parsed")
+	}
 	if p.debugMode {
-		fmt.Printf("Processing import: %s -> %s\n", imp.Namespace, importPath)
+		fmt.Printf("Processing schema with %d groups\n", len(p.schema.Groups))
+		for _, group := range p.schema.Groups {
+			fmt.Printf("Found group: %s\n", group.Name)
+		}
 	}
 
-	// Parse imported schema
-	content, err := ioutil.ReadFile(importPath)
-	if err != nil {
-		return fmt.Errorf("failed to read import file %s: %v", importPath, err)
-	}
+	namespaces := p.importedNamespaces()
+	p.assignTypeNames(namespaces)
+	p.computeSubstitutionGroups()
 
-	var importedSchema types.XSDSchema
-	if err := xml.Unmarshal(content, &importedSchema); err != nil {
-		return fmt.Errorf("failed to parse imported XML %s: %v", importPath, err)
-	}
+	convert := func() error {
+		// Convert the main schema first so its names take precedence
+		if err := p.convertSchemaTypes(p.schema, p.targetNamespace, false); err != nil {
+			return err
+		}
 
-	p.imports[imp.Namespace] = &importedSchema
-	return nil
-}
+		// Convert imported schemas in a stable order
+		for _, namespace := range namespaces {
+			if p.debugMode {
+				fmt.Printf("Converting imported schema: %s\n", namespace)
+			}
+			if err := p.convertSchemaTypes(p.imports[namespace], namespace, true); err != nil {
+				return fmt.Errorf("imported namespace %s: %v", namespace, err)
+			}
+		}
+		p.currentSchema = p.schema
+		p.currentNS = p.targetNamespace
 
-// processInclude processes a single include
-func (p *XSDParser) processInclude(inc types.XSDInclude) error {
-	if inc.SchemaLocation == "" {
+		// Holder types for substitution groups need all member types converted
+		p.convertSubstitutionGroups()
 		return nil
 	}
 
-	// Resolve relative path
-	includePath := inc.SchemaLocation
-	if !filepath.IsAbs(includePath) {
-		baseDir := filepath.Dir(p.filePath)
-		includePath = filepath.Join(baseDir, includePath)
+	// Anonymous types are named once all of them are known
+	if err := p.collectAnonymousTypes(convert); err != nil {
+		return err
 	}
+	p.resolveFieldNames()
 
 	if p.debugMode {
-		fmt.Printf("Processing include: %s\n", includePath)
+		fmt.Printf("Converted %d Go types\n", len(p.goTypes))
 	}
 
-	// Parse included schema
-	content, err := ioutil.ReadFile(includePath)
-	if err != nil {
-		return fmt.Errorf("failed to read include file %s: %v", includePath, err)
-	}
+	return nil
+}
 
-	var includedSchema types.XSDSchema
-	if err := xml.Unmarshal(content, &includedSchema); err != nil {
-		return fmt.Errorf("failed to parse included XML %s: %v", includePath, err)
+// importedNamespaces returns the imported namespaces in a stable order
+func (p *XSDParser) importedNamespaces() []string {
+	namespaces := make([]string, 0, len(p.imports))
+	for namespace := range p.imports {
+		namespaces = append(namespaces, namespace)
 	}
-
-	// Merge included schema into main schema
-	p.mergeSchema(&includedSchema)
-	return nil
+	sort.Strings(namespaces)
+	return namespaces
 }
 
-// mergeSchema merges an included schema into the main schema
-func (p *XSDParser) mergeSchema(included *types.XSDSchema) {
-	if p.schema == nil {
-		return
+// assignTypeNames gives every named global type, and every global element
+// with an inline type, a Go name keyed by its qualified name. When two
+// namespaces define the same local name, the type from the later namespace
+// is prefixed so the names stay distinct; other collisions are settled by
+// the naming registry.
+func (p *XSDParser) assignTypeNames(namespaces []string) {
+	p.names = types.NewNamingRegistry(p.nameSuffixes)
+	p.names.Reserve(generator.ReservedTypeNames...)
+	if p.arbitraryPrecision {
+		p.names.Reserve(generator.ReservedBigNumberNames...)
+	}
+	p.elementTypeNames = make(map[types.QName]string)
+
+	owners := make(map[string]string)
+	assign := func(kind types.NameKind, namespace, name string) string {
+		goName := types.ToGoTypeName(name)
+		if owner, used := owners[goName]; used && owner != namespace {
+			goName = p.namespacePrefix(namespace) + goName
+		}
+		owners[goName] = namespace
+		qname := types.QName{Space: namespace, Local: name}
+		return p.names.Claim(kind, string(kind)+" "+qname.String(), goName)
+	}
+	assignType := func(namespace, name string) {
+		qname := types.QName{Space: namespace, Local: name}
+		if _, exists := p.typeNames[qname]; !exists && name != "" {
+			p.typeNames[qname] = assign(types.NameType, namespace, name)
+		}
 	}
 
-	// Merge elements
-	p.schema.Elements = append(p.schema.Elements, included.Elements...)
+	schemas := []*types.XSDSchema{p.schema}
+	schemaNamespaces := []string{p.targetNamespace}
+	for _, namespace := range namespaces {
+		schemas = append(schemas, p.imports[namespace])
+		schemaNamespaces = append(schemaNamespaces, namespace)
+	}
+	for i, schema := range schemas {
+		namespace := schemaNamespaces[i]
+		for _, complexType := range schema.ComplexTypes {
+			assignType(namespace, complexType.Name)
+		}
+		for _, simpleType := range schema.SimpleTypes {
+			assignType(namespace, simpleType.Name)
+		}
+	}
 
-	// Merge complex types
-	p.schema.ComplexTypes = append(p.schema.ComplexTypes, included.ComplexTypes...)
+	// Element types come second so that named types keep their names
+	for i, schema := range schemas {
+		for _, element := range schema.Elements {
+			qname := types.QName{Space: schemaNamespaces[i], Local: element.Name}
+			if _, exists := p.elementTypeNames[qname]; exists || element.ComplexType == nil || element.Name == "" {
+				continue
+			}
+			p.elementTypeNames[qname] = assign(types.NameElement, qname.Space, qname.Local)
+		}
+	}
+}
 
-	// Merge simple types
-	p.schema.SimpleTypes = append(p.schema.SimpleTypes, included.SimpleTypes...)
+// namespacePrefix derives a Go identifier prefix for a namespace, preferring
+// the prefix the main schema declares for it
+func (p *XSDParser) namespacePrefix(namespace string) string {
+	prefixes := make([]string, 0, len(p.schema.Xmlns))
+	for prefix, uri := range p.schema.Xmlns {
+		if uri == namespace && prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) > 0 {
+		sort.Strings(prefixes)
+		return types.ToPascalCase(prefixes[0])
+	}
 
-	// Merge groups
-	p.schema.Groups = append(p.schema.Groups, included.Groups...)
+	segment := strings.TrimRight(namespace, "/")
+	if slash := strings.LastIndexAny(segment, "/:"); slash != -1 {
+		segment = segment[slash+1:]
+	}
+	var builder strings.Builder
+	for _, r := range segment {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9' && builder.Len() > 0) {
+			builder.WriteRune(r)
+		}
+	}
+	if builder.Len() == 0 {
+		return "Ns"
+	}
+	return types.ToPascalCase(builder.String())
+}
 
-	// Merge attribute groups
-	p.schema.AttributeGroups = append(p.schema.AttributeGroups, included.AttributeGroups...)
+// typeName returns the Go name of a global type in the namespace being converted
+func (p *XSDParser) typeName(name string) string {
+	if goName, exists := p.typeNames[types.QName{Space: p.currentNS, Local: name}]; exists {
+		return goName
+	}
+	return types.ToGoTypeName(name)
 }
 
-// convertToGoTypes converts XSD types to Go types
-func (p *XSDParser) convertToGoTypes() error {
-	if p.debugMode {
-		fmt.Printf("Processing schema with %d groups\n", len(p.schema.Groups))
-		for _, group := range p.schema.Groups {
-			fmt.Printf("Found group: %s\n", group.Name)
+// lookupTypeName finds the Go name of a referenced global type. References
+// without a namespace fall back to the schema being converted and the main schema.
+func (p *XSDParser) lookupTypeName(qname types.QName) (string, bool) {
+	if goName, exists := p.typeNames[qname]; exists {
+		return goName, true
+	}
+	if qname.Space == "" {
+		for _, namespace := range []string{p.currentNS, p.targetNamespace} {
+			if goName, exists := p.typeNames[types.QName{Space: namespace, Local: qname.Local}]; exists {
+				return goName, true
+			}
 		}
 	}
-	if p.schema == nil {
-		return fmt.Errorf("schema not parsed")
+	return "", false
+}
+
+// convertSchemaTypes converts the global types of one schema document.
+// Types from imported schemas never replace a type that already exists.
+func (p *XSDParser) convertSchemaTypes(schema *types.XSDSchema, namespace string, imported bool) error {
+	p.currentSchema = schema
+	p.currentNS = namespace
+
+	addType := func(goType *types.GoType) {
+		if imported && p.findExistingType(goType.Name) != nil {
+			if p.debugMode {
+				fmt.Printf("Warning: type %s from namespace %s already defined, skipping\n", goType.Name, namespace)
+			}
+			return
+		}
+		p.goTypes = append(p.goTypes, *goType)
 	}
 
 	// Convert complex types
-	for _, complexType := range p.schema.ComplexTypes {
+	for _, complexType := range schema.ComplexTypes {
+		source := p.sourceFile(ComponentComplexType, complexType.Name)
 		goType, err := p.convertComplexType(complexType)
 		if err != nil {
-			return fmt.Errorf("failed to convert complex type %s: %v", complexType.Name, err)
+			return fmt.Errorf("failed to convert complex type %s%s: %v", complexType.Name, sourceSuffix(source), err)
 		}
-		p.goTypes = append(p.goTypes, *goType)
+		goType.SourceFile = source
+		addType(goType)
 	}
+
 	// Convert simple types (enums, etc.)
-	for _, simpleType := range p.schema.SimpleTypes {
-		fmt.Printf("Converting simple type: %s\n", simpleType.Name)
-		if simpleType.Restriction != nil && simpleType.Restriction.Pattern != nil {
-			fmt.Printf("  Has pattern: %s\n", simpleType.Restriction.Pattern.Value)
+	for _, simpleType := range schema.SimpleTypes {
+		if p.debugMode {
+			fmt.Printf("Converting simple type: %s\n", simpleType.Name)
 		}
+		source := p.sourceFile(ComponentSimpleType, simpleType.Name)
 		goType, err := p.convertSimpleType(simpleType)
 		if err != nil {
-			return fmt.Errorf("failed to convert simple type %s: %v", simpleType.Name, err)
+			return fmt.Errorf("failed to convert simple type %s%s: %v", simpleType.Name, sourceSuffix(source), err)
 		}
 		if goType != nil {
-			fmt.Printf("  Added type: %s (pattern: %v)\n", goType.Name, goType.HasPattern)
-			p.goTypes = append(p.goTypes, *goType)
-		} else {
-			fmt.Printf("  Type was nil\n")
+			goType.SourceFile = source
+			addType(goType)
 		}
 	}
 
 	// Convert root elements
-	for _, element := range p.schema.Elements {
+	for _, element := range schema.Elements {
 		if element.ComplexType != nil {
+			source := p.sourceFile(ComponentElement, element.Name)
 			goType, err := p.convertComplexTypeFromElement(element)
 			if err != nil {
-				return fmt.Errorf("failed to convert element %s: %v", element.Name, err)
+				return fmt.Errorf("failed to convert element %s%s: %v", element.Name, sourceSuffix(source), err)
+			}
+			goType.SourceFile = source
+			goType.IdentityConstraints = identityConstraints(element)
+			addType(goType)
+		} else if constraints := identityConstraints(element); constraints != nil {
+			// Constraints of an element with a named type go on that type
+			if goType := p.findExistingType(p.mapXSDTypeToGo(element.Type)); goType != nil {
+				goType.IdentityConstraints = append(goType.IdentityConstraints, constraints...)
 			}
-			p.goTypes = append(p.goTypes, *goType)
 		}
 	}
 
-	if p.debugMode {
-		fmt.Printf("Converted %d Go types\n", len(p.goTypes))
-	}
-
 	return nil
 }
 
+// sourceSuffix formats a source file for error messages
+func sourceSuffix(source string) string {
+	if source == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (in %s)", source)
+}
+
 // convertComplexType converts an XSD complex type to a Go type
 func (p *XSDParser) convertComplexType(xsdType types.XSDComplexType) (*types.GoType, error) {
 	goType := &types.GoType{
-		Name:      types.ToGoTypeName(xsdType.Name),
-		Package:   p.packageName,
-		XMLName:   xsdType.Name,
-		Namespace: p.targetNamespace,
-		Fields:    make([]types.GoField, 0),
-		Comment:   types.GetDocumentation(xsdType.Annotation),
+		Name:       p.typeName(xsdType.Name),
+		Package:    p.packageName,
+		XMLName:    xsdType.Name,
+		Namespace:  p.currentNS,
+		Fields:     make([]types.GoField, 0),
+		Comment:    types.GetDocumentation(xsdType.Annotation),
+		IsAbstract: xsdType.Abstract == "true",
+		IsMixed:    isMixed(xsdType),
 	}
 
 	// Handle different content models
@@ -341,14 +488,25 @@ func (p *XSDParser) convertComplexType(xsdType types.XSDComplexType) (*types.GoT
 		}
 	}
 
-	// Handle attributes
-	for _, attr := range xsdType.Attributes {
-		field, err := p.convertAttribute(attr)
+	if xsdType.Group != nil {
+		if err := p.processGroupRef(*xsdType.Group, goType, p.typePath(goType.Name)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Handle attributes, including those of attribute groups
+	attributes, wildcard, err := p.expandAttributes(xsdType.Attributes, xsdType.AttributeGroups, xsdType.AnyAttribute)
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range attributes {
+		field, err := p.convertAttribute(attr, p.typePath(goType.Name))
 		if err != nil {
 			return nil, err
 		}
 		goType.Fields = append(goType.Fields, *field)
 	}
+	p.processAnyAttribute(wildcard, goType)
 
 	// Handle extensions
 	if xsdType.ComplexContent != nil && xsdType.ComplexContent.Extension != nil {
@@ -357,8 +515,14 @@ func (p *XSDParser) convertComplexType(xsdType types.XSDComplexType) (*types.GoT
 		}
 	}
 
-	if xsdType.SimpleContent != nil && xsdType.SimpleContent.Extension != nil {
-		if err := p.processExtension(xsdType.SimpleContent.Extension, goType); err != nil {
+	if xsdType.ComplexContent != nil && xsdType.ComplexContent.Restriction != nil {
+		if err := p.processRestriction(xsdType.ComplexContent.Restriction, goType); err != nil {
+			return nil, err
+		}
+	}
+
+	if xsdType.SimpleContent != nil {
+		if err := p.processSimpleContent(xsdType.SimpleContent, goType); err != nil {
 			return nil, err
 		}
 	}
@@ -366,91 +530,122 @@ func (p *XSDParser) convertComplexType(xsdType types.XSDComplexType) (*types.GoT
 	return goType, nil
 }
 
+// isMixed reports whether a complex type allows character data between its
+// child elements
+func isMixed(xsdType types.XSDComplexType) bool {
+	mixed := xsdType.Mixed
+	if xsdType.ComplexContent != nil && xsdType.ComplexContent.Mixed != "" {
+		mixed = xsdType.ComplexContent.Mixed
+	}
+	return mixed == "true" || mixed == "1"
+}
+
 // convertSimpleType converts an XSD simple type to a Go type
 func (p *XSDParser) convertSimpleType(xsdType types.XSDSimpleType) (*types.GoType, error) {
+	if xsdType.Union != nil {
+		return p.convertUnionType(xsdType)
+	}
+	if xsdType.List != nil {
+		return p.convertListType(xsdType, xsdType.List, xsdType.Name)
+	}
 	if xsdType.Restriction == nil {
 		return nil, nil // Skip non-restriction simple types
 	}
+	if list, owner := p.restrictedList(xsdType); list != nil {
+		return p.convertListType(xsdType, list, owner)
+	}
 	var goType *types.GoType
-	// Handle enumerations, which have precedence over other restrictions
-	if len(xsdType.Restriction.Enumerations) > 0 {
+	chain := p.restrictionChain(xsdType)
+	// Handle enumerations, including those of the base types, which have
+	// precedence over other restrictions
+	if len(effectiveEnumerations(chain)) > 0 {
 		return p.convertEnumType(xsdType)
 	}
 
+	// Merge the facets of the base types, which the derived type may only narrow
+	facets, err := types.EffectiveFacets(chain)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create a base type for restrictions
 	baseType := p.mapXSDTypeToGo(xsdType.Restriction.Base)
 	goType = &types.GoType{
-		Name:            types.ToGoTypeName(xsdType.Name),
+		Name:            p.typeName(xsdType.Name),
 		Package:         p.packageName,
+		Namespace:       p.currentNS,
 		BaseType:        baseType,
 		IsEnum:          false,
 		Comment:         types.GetDocumentation(xsdType.Annotation),
 		NeedsValidation: false, // Will be set to true if any restrictions are found
 	}
+	if builtin := types.ParseQName(chain[len(chain)-1].Base); builtin.Space == types.XSDNamespace {
+		goType.BuiltinBase = builtin.Local
+	}
 
-	// Handle pattern restriction
-	if xsdType.Restriction.Pattern != nil {
+	// Handle pattern restrictions, including those of the base types
+	if patterns := effectivePatterns(chain); len(patterns) > 0 {
 		goType.HasPattern = true
-		goType.PatternValue = xsdType.Restriction.Pattern.Value
+		goType.Patterns = patterns
 		goType.NeedsValidation = true
 	}
 
 	// Handle length restrictions
-	if xsdType.Restriction.MinLength != nil {
+	if facets.MinLength != nil {
 		goType.HasMinLength = true
-		goType.MinLength = xsdType.Restriction.MinLength.Value
+		goType.MinLength = facets.MinLength.Value
 		goType.NeedsValidation = true
 	}
-	if xsdType.Restriction.MaxLength != nil {
+	if facets.MaxLength != nil {
 		goType.HasMaxLength = true
-		goType.MaxLength = xsdType.Restriction.MaxLength.Value
+		goType.MaxLength = facets.MaxLength.Value
 		goType.NeedsValidation = true
 	}
 
 	// Handle numeric restrictions
-	if xsdType.Restriction.MinInclusive != nil {
+	if facets.MinInclusive != nil {
 		goType.HasMinInclusive = true
-		goType.MinInclusive = xsdType.Restriction.MinInclusive.Value
+		goType.MinInclusive = facets.MinInclusive.Value
 		goType.NeedsValidation = true
 	}
-	if xsdType.Restriction.MaxInclusive != nil {
+	if facets.MaxInclusive != nil {
 		goType.HasMaxInclusive = true
-		goType.MaxInclusive = xsdType.Restriction.MaxInclusive.Value
+		goType.MaxInclusive = facets.MaxInclusive.Value
 		goType.NeedsValidation = true
 	}
-	if xsdType.Restriction.MinExclusive != nil {
+	if facets.MinExclusive != nil {
 		goType.HasMinExclusive = true
-		goType.MinExclusive = xsdType.Restriction.MinExclusive.Value
+		goType.MinExclusive = facets.MinExclusive.Value
 		goType.NeedsValidation = true
 	}
-	if xsdType.Restriction.MaxExclusive != nil {
+	if facets.MaxExclusive != nil {
 		goType.HasMaxExclusive = true
-		goType.MaxExclusive = xsdType.Restriction.MaxExclusive.Value
+		goType.MaxExclusive = facets.MaxExclusive.Value
 		goType.NeedsValidation = true
 	}
 	// Handle digit restrictions
-	if xsdType.Restriction.TotalDigits != nil {
+	if facets.TotalDigits != nil {
 		goType.HasTotalDigits = true
-		goType.TotalDigits = xsdType.Restriction.TotalDigits.Value
+		goType.TotalDigits = facets.TotalDigits.Value
 		goType.NeedsValidation = true
 	}
-	if xsdType.Restriction.FractionDigits != nil {
+	if facets.FractionDigits != nil {
 		goType.HasFractionDigits = true
-		goType.FractionDigits = xsdType.Restriction.FractionDigits.Value
+		goType.FractionDigits = facets.FractionDigits.Value
 		goType.NeedsValidation = true
 	}
 
 	// Handle whiteSpace restriction
-	if xsdType.Restriction.WhiteSpace != nil {
+	if facets.WhiteSpace != nil {
 		goType.HasWhiteSpace = true
-		goType.WhiteSpace = xsdType.Restriction.WhiteSpace.Value
+		goType.WhiteSpace = facets.WhiteSpace.Value
 		goType.NeedsValidation = true
 	}
 
 	// Handle exact length restriction
-	if xsdType.Restriction.Length != nil {
+	if facets.Length != nil {
 		goType.HasLength = true
-		goType.Length = xsdType.Restriction.Length.Value
+		goType.Length = facets.Length.Value
 		goType.NeedsValidation = true
 	}
 
@@ -463,18 +658,25 @@ func (p *XSDParser) convertSimpleType(xsdType types.XSDSimpleType) (*types.GoTyp
 func (p *XSDParser) convertEnumType(xsdType types.XSDSimpleType) (*types.GoType, error) {
 	baseType := p.mapXSDTypeToGo(xsdType.Restriction.Base)
 
+	// Enumerated dates, times and durations keep the text of the values
+	chain := p.restrictionChain(xsdType)
+	if primitive := types.ParseQName(chain[len(chain)-1].Base); primitive.Space == types.XSDNamespace && xsd.IsType(primitive.Local) {
+		baseType = "string"
+	}
+
 	goType := &types.GoType{
-		Name:      types.ToGoTypeName(xsdType.Name),
+		Name:      p.typeName(xsdType.Name),
 		Package:   p.packageName,
+		Namespace: p.currentNS,
 		BaseType:  baseType,
 		IsEnum:    true,
 		Constants: make([]types.GoConstant, 0),
 		Comment:   types.GetDocumentation(xsdType.Annotation),
 	}
 
-	// Convert enumerations to constants
-	for _, enum := range xsdType.Restriction.Enumerations {
-		constName := p.generateConstantName(goType.Name, enum.Value)
+	// Convert enumerations to constants; base types may rule out values
+	for _, enum := range effectiveEnumerations(p.restrictionChain(xsdType)) {
+		constName := p.claimName(types.NameConstant, goType.Name+"\x00"+enum.Value, p.generateConstantName(goType.Name, enum.Value))
 		constant := types.GoConstant{
 			Name:    constName,
 			Value:   fmt.Sprintf("\"%s\"", enum.Value),
@@ -491,17 +693,20 @@ func (p *XSDParser) convertComplexTypeFromElement(element types.XSDElement) (*ty
 		return nil, fmt.Errorf("element has no complex type")
 	}
 
-	// Use element name as type name
-	typeName := types.ToGoTypeName(element.Name)
 	complexType := *element.ComplexType
-	complexType.Name = typeName
+	complexType.Name = p.elementTypeName(element.Name)
 
-	return p.convertComplexType(complexType)
+	goType, err := p.convertComplexType(complexType)
+	if err != nil {
+		return nil, err
+	}
+	goType.XMLName = element.Name
+	return goType, nil
 }
 
 // processSequence processes an XSD sequence
 func (p *XSDParser) processSequence(sequence *types.XSDSequence, goType *types.GoType) error {
-	return p.processSequenceWithContext(sequence, goType, []string{goType.Name})
+	return p.processSequenceWithContext(sequence, goType, p.typePath(goType.Name))
 }
 
 // processSequenceWithContext processes an XSD sequence with context path
@@ -534,12 +739,15 @@ func (p *XSDParser) processSequenceWithContext(sequence *types.XSDSequence, goTy
 		}
 	}
 
+	// Process element wildcards
+	p.processAnyElements(sequence.Any, goType)
+
 	return nil
 }
 
 // processChoice processes an XSD choice
 func (p *XSDParser) processChoice(choice *types.XSDChoice, goType *types.GoType) error {
-	return p.processChoiceWithContext(choice, goType, []string{goType.Name})
+	return p.processChoiceWithContext(choice, goType, p.typePath(goType.Name))
 }
 
 // isGoBasicType checks if a type is a Go basic type that doesn't need pointer wrapping in choices
@@ -599,8 +807,9 @@ func (p *XSDParser) processChoiceWithContext(choice *types.XSDChoice, goType *ty
 			}
 		}
 
-		// Ensure omitempty is in both XML and JSON tags
-		if field.XMLTag != "" && !strings.Contains(field.XMLTag, "omitempty") {
+		// Ensure omitempty is in both XML and JSON tags; catch-all tags such
+		// as ",any" cannot take omitempty
+		if field.XMLTag != "" && !strings.HasPrefix(field.XMLTag, ",") && !strings.Contains(field.XMLTag, "omitempty") {
 			if strings.Contains(field.XMLTag, ",attr") {
 				field.XMLTag = strings.Replace(field.XMLTag, ",attr", ",omitempty", 1)
 			} else {
@@ -635,12 +844,15 @@ func (p *XSDParser) processChoiceWithContext(choice *types.XSDChoice, goType *ty
 		}
 	}
 
+	// Process element wildcards
+	p.processAnyElements(choice.Any, goType)
+
 	return nil
 }
 
 // processAll processes an XSD all
 func (p *XSDParser) processAll(all *types.XSDAll, goType *types.GoType) error {
-	return p.processAllWithContext(all, goType, []string{goType.Name})
+	return p.processAllWithContext(all, goType, p.typePath(goType.Name))
 }
 
 // processAllWithContext processes an XSD all with context path
@@ -659,7 +871,7 @@ func (p *XSDParser) processAllWithContext(all *types.XSDAll, goType *types.GoTyp
 func (p *XSDParser) processExtension(extension *types.XSDExtension, goType *types.GoType) error {
 	// Add base type comment
 	if extension.Base != "" {
-		baseComment := fmt.Sprintf("extends %s", extension.Base)
+		baseComment := fmt.Sprintf("extends %s", p.mapXSDTypeToGo(extension.Base))
 		if goType.Comment != "" {
 			goType.Comment += "; " + baseComment
 		} else {
@@ -688,14 +900,26 @@ func (p *XSDParser) processExtension(extension *types.XSDExtension, goType *type
 		}
 	}
 
-	// Process attributes
-	for _, attr := range extension.Attributes {
-		field, err := p.convertAttribute(attr)
+	// Process group reference
+	if extension.Group != nil {
+		if err := p.processGroupRef(*extension.Group, goType, p.typePath(goType.Name)); err != nil {
+			return err
+		}
+	}
+
+	// Process attributes, including those of attribute groups
+	attributes, wildcard, err := p.expandAttributes(extension.Attributes, extension.AttributeGroups, extension.AnyAttribute)
+	if err != nil {
+		return err
+	}
+	for _, attr := range attributes {
+		field, err := p.convertAttribute(attr, p.typePath(goType.Name))
 		if err != nil {
 			return err
 		}
 		goType.Fields = append(goType.Fields, *field)
 	}
+	p.processAnyAttribute(wildcard, goType)
 
 	return nil
 }
@@ -707,26 +931,36 @@ func (p *XSDParser) convertElement(element types.XSDElement) (*types.GoField, er
 
 // convertElementWithContext converts an XSD element to a Go field with context path
 func (p *XSDParser) convertElementWithContext(element types.XSDElement, contextPath []string) (*types.GoField, error) {
-	fieldName := types.ToGoFieldName(element.Name)
+	// References to a substitution group head accept any member element;
+	// other references take their declaration from the global element
+	if element.Ref != "" {
+		if field, ok := p.substitutionGroupField(element); ok {
+			return field, nil
+		}
+		return p.convertElementRef(element)
+	}
+
 	fieldType := p.mapXSDTypeToGo(element.Type)
 
-	min, max := types.ParseOccurs(element.MinOccurs, element.MaxOccurs)
-	isOptional := min == 0
-	isArray := max > 1 || max == -1 // Handle inline complex type
+	// Handle inline complex type
 	if element.ComplexType != nil {
 		// Create context-aware inline type name
-		fullContextPath := append(contextPath, types.ToGoTypeName(element.Name))
-		fieldType = strings.Join(fullContextPath, "")
+		fullContextPath := append(append([]string(nil), contextPath...), types.ToGoTypeName(element.Name))
+		fieldType = p.anonymousTypeName(fullContextPath)
 
 		// Check if this type already exists to avoid duplicates
 		existingType := p.findExistingType(fieldType)
 		if existingType == nil {
 			// Create and add inline type to goTypes list
 			inlineType := types.GoType{
-				Name:    fieldType,
-				Comment: fmt.Sprintf("%s represents the inline complex type for element %s", fieldType, element.Name),
-				Fields:  make([]types.GoField, 0),
-				XMLName: element.Name,
+				Name:      fieldType,
+				Comment:   fmt.Sprintf("%s represents the inline complex type for element %s", fieldType, element.Name),
+				Fields:    make([]types.GoField, 0),
+				XMLName:   element.Name,
+				Namespace: p.currentNS, // placed in the package of the type declaring it
+				IsMixed:   isMixed(*element.ComplexType),
+
+				IdentityConstraints: identityConstraints(element),
 			} // Process content model using proper context-aware methods that handle group references
 			if element.ComplexType.Sequence != nil {
 				if err := p.processSequenceWithContext(element.ComplexType.Sequence, &inlineType, fullContextPath); err != nil {
@@ -740,16 +974,30 @@ func (p *XSDParser) convertElementWithContext(element types.XSDElement, contextP
 				if err := p.processAllWithContext(element.ComplexType.All, &inlineType, fullContextPath); err != nil {
 					return nil, fmt.Errorf("failed to process all in inline type %s: %v", fieldType, err)
 				}
+			} else if element.ComplexType.Group != nil {
+				if err := p.processGroupRef(*element.ComplexType.Group, &inlineType, fullContextPath); err != nil {
+					return nil, fmt.Errorf("failed to process group in inline type %s: %v", fieldType, err)
+				}
 			}
 
-			// Convert attributes to fields
-			for _, attr := range element.ComplexType.Attributes {
-				field, err := p.convertAttribute(attr)
+			// Convert attributes, including those of attribute groups, to fields
+			attributes, wildcard, err := p.expandAttributes(element.ComplexType.Attributes, element.ComplexType.AttributeGroups, element.ComplexType.AnyAttribute)
+			if err != nil {
+				return nil, fmt.Errorf("failed to expand attribute groups in inline type %s: %v", fieldType, err)
+			}
+			for _, attr := range attributes {
+				field, err := p.convertAttribute(attr, fullContextPath)
 				if err != nil {
 					return nil, fmt.Errorf("failed to convert inline attribute %s: %v", attr.Name, err)
 				}
 				inlineType.Fields = append(inlineType.Fields, *field)
 			}
+			p.processAnyAttribute(wildcard, &inlineType)
+			if element.ComplexType.SimpleContent != nil {
+				if err := p.processSimpleContent(element.ComplexType.SimpleContent, &inlineType); err != nil {
+					return nil, fmt.Errorf("failed to process simple content in inline type %s: %v", fieldType, err)
+				}
+			}
 
 			// Add to types list
 			p.goTypes = append(p.goTypes, inlineType)
@@ -758,7 +1006,29 @@ func (p *XSDParser) convertElementWithContext(element types.XSDElement, contextP
 
 	// Handle inline simple type
 	if element.SimpleType != nil {
-		fieldType = p.mapXSDTypeToGo("string") // Default to string for inline simple types
+		path := append(append([]string(nil), contextPath...), types.ToGoTypeName(element.Name))
+		simpleType, err := p.inlineSimpleType(*element.SimpleType, path)
+		if err != nil {
+			return nil, err
+		}
+		fieldType = simpleType
+	}
+
+	return p.newElementField(element, fieldType), nil
+}
+
+// newElementField builds the field for an element whose value has the given
+// Go type, applying its occurrence, nillable, fixed and default settings
+func (p *XSDParser) newElementField(element types.XSDElement, fieldType string) *types.GoField {
+	fieldName := types.ToGoFieldName(element.Name)
+	min, max := types.ParseOccurs(element.MinOccurs, element.MaxOccurs)
+	isOptional := min == 0
+	isArray := max > 1 || max == -1
+
+	// Nillable elements record xsi:nil separately from the value
+	isNillable := element.Nillable == "true" || element.Nillable == "1"
+	if isNillable {
+		fieldType = fmt.Sprintf("Nillable[%s]", fieldType)
 	}
 
 	// Make pointer type if optional
@@ -797,6 +1067,7 @@ func (p *XSDParser) convertElementWithContext(element types.XSDElement, contextP
 		IsArray:    isArray,
 		MinOccurs:  min,
 		MaxOccurs:  max,
+		IsNillable: isNillable,
 	}
 
 	// Handle fixed value for elements
@@ -807,14 +1078,31 @@ func (p *XSDParser) convertElementWithContext(element types.XSDElement, contextP
 			field.Comment = fmt.Sprintf("Fixed value: %s", element.Fixed)
 		}
 	}
+	if element.Default != "" {
+		field.HasDefault = true
+		field.DefaultValue = element.Default
+	}
 
-	return field, nil
+	return field
 }
 
-// convertAttribute converts an XSD attribute to a Go field
-func (p *XSDParser) convertAttribute(attr types.XSDAttribute) (*types.GoField, error) {
+// convertAttribute converts an XSD attribute to a Go field. An anonymous
+// simple type is named after the attribute within contextPath.
+func (p *XSDParser) convertAttribute(attr types.XSDAttribute, contextPath []string) (*types.GoField, error) {
+	if attr.Ref != "" {
+		return p.convertAttributeRef(attr)
+	}
+
 	fieldName := types.ToGoFieldName(attr.Name)
 	fieldType := p.mapXSDTypeToGo(attr.Type)
+	if attr.SimpleType != nil {
+		path := append(append([]string(nil), contextPath...), fieldName)
+		simpleType, err := p.inlineSimpleType(*attr.SimpleType, path)
+		if err != nil {
+			return nil, err
+		}
+		fieldType = simpleType
+	}
 
 	isOptional := attr.Use != "required"
 
@@ -823,7 +1111,12 @@ func (p *XSDParser) convertAttribute(attr types.XSDAttribute) (*types.GoField, e
 		fieldType = "*" + fieldType
 	}
 
+	// Absent optional attributes are left out; encoding/xml would otherwise
+	// call the text methods of a nil value
 	xmlTag := fmt.Sprintf("%s,attr", attr.Name)
+	if isOptional {
+		xmlTag += ",omitempty"
+	}
 	jsonTag := ""
 	if p.jsonCompatible {
 		jsonTag = types.ToSnakeCase(attr.Name)
@@ -849,6 +1142,10 @@ func (p *XSDParser) convertAttribute(attr types.XSDAttribute) (*types.GoField, e
 			field.Comment = fmt.Sprintf("Fixed value: %s", attr.Fixed)
 		}
 	}
+	if attr.Default != "" {
+		field.HasDefault = true
+		field.DefaultValue = attr.Default
+	}
 
 	return field, nil
 }
@@ -859,22 +1156,33 @@ func (p *XSDParser) mapXSDTypeToGo(xsdType string) string {
 		return "string"
 	}
 
-	// Remove namespace prefix
-	if colonIndex := strings.LastIndex(xsdType, ":"); colonIndex != -1 {
-		xsdType = xsdType[colonIndex+1:]
+	// Types defined by the schema set win over built-in names, except for
+	// references into the XML Schema namespace itself
+	qname := types.ParseQName(xsdType)
+	if qname.Space != types.XSDNamespace {
+		if goName, exists := p.lookupTypeName(qname); exists {
+			return goName
+		}
+	}
+
+	// Numbers without a fixed size may use arbitrary-precision types
+	if p.arbitraryPrecision {
+		if goType, exists := generator.BigNumberType(qname.Local); exists {
+			return goType
+		}
 	}
 
 	// Use default Go language mapper for type mappings
 	mapper := &generator.GoLanguageMapper{}
 	mappings := mapper.GetBuiltinTypeMappings()
 	for _, mapping := range mappings {
-		if mapping.XSDType == xsdType {
+		if mapping.XSDType == qname.Local {
 			return mapping.TargetType
 		}
 	}
 
 	// For custom types, convert to Go type name
-	return types.ToGoTypeName(xsdType)
+	return types.ToGoTypeName(qname.Local)
 }
 
 // generateConstantName generates a constant name for enums
@@ -908,26 +1216,55 @@ func (p *XSDParser) GetSchema() *types.XSDSchema {
 	return p.schema
 }
 
+// GetSchemaSet returns every schema document loaded for the root file
+func (p *XSDParser) GetSchemaSet() *SchemaSet {
+	return p.schemaSet
+}
+
+// GetElementTypes maps every global element of the schema set to the Go
+// type of its content
+func (p *XSDParser) GetElementTypes() map[types.QName]string {
+	elements := make(map[types.QName]string)
+	for _, namespace := range append([]string{p.targetNamespace}, p.importedNamespaces()...) {
+		schema := p.schemaForNamespace(namespace)
+		if schema == nil {
+			continue
+		}
+		for i := range schema.Elements {
+			found := &globalElement{element: &schema.Elements[i], namespace: namespace}
+			elements[types.QName{Space: namespace, Local: found.element.Name}] = p.globalElementType(found)
+		}
+	}
+	return elements
+}
+
+// sourceFile returns the document defining a global component, relative to
+// the root schema's directory. It is empty for single-document schemas.
+func (p *XSDParser) sourceFile(kind, name string) string {
+	if p.schemaSet == nil || len(p.schemaSet.Documents()) < 2 {
+		return ""
+	}
+	path := p.schemaSet.SourceFile(kind, p.currentNS, name)
+	if path == "" {
+		return ""
+	}
+	if rel, err := filepath.Rel(filepath.Dir(p.rootPath), path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
 // processGroupRef processes an XSD group reference
 func (p *XSDParser) processGroupRef(groupRef types.XSDGroupRef, goType *types.GoType, contextPath []string) error {
-	// Extract group name from reference (remove namespace prefix if present)
-	groupName := groupRef.Ref
-	if parts := strings.Split(groupName, ":"); len(parts) > 1 {
-		groupName = parts[1]
-	}
+	groupQName := types.ParseQName(groupRef.Ref)
+	groupName := groupQName.Local
 
 	if p.debugMode {
 		fmt.Printf("Processing group reference: %s in type %s\n", groupName, goType.Name)
 	}
 
 	// Find the group definition in the schema
-	var foundGroup *types.XSDGroup
-	for i := range p.schema.Groups {
-		if p.schema.Groups[i].Name == groupName {
-			foundGroup = &p.schema.Groups[i]
-			break
-		}
-	}
+	foundGroup, namespace := p.findGroup(groupQName)
 
 	if foundGroup == nil {
 		if p.debugMode {
@@ -936,6 +1273,23 @@ func (p *XSDParser) processGroupRef(groupRef types.XSDGroupRef, goType *types.Go
 		return nil // Skip if group not found
 	}
 
+	key := types.QName{Space: namespace, Local: foundGroup.Name}
+	if p.expandingGroups[key] {
+		return fmt.Errorf("circular reference to group %s", groupName)
+	}
+	p.expandingGroups[key] = true
+	defer delete(p.expandingGroups, key)
+
+	// Nested references resolve in the schema that declares the group
+	return p.inSchemaOf(namespace, func() error {
+		return p.processGroupContent(foundGroup, goType, contextPath)
+	})
+}
+
+// processGroupContent processes the choice, sequence or all of a group
+func (p *XSDParser) processGroupContent(foundGroup *types.XSDGroup, goType *types.GoType, contextPath []string) error {
+	groupName := foundGroup.Name
+
 	if p.debugMode {
 		fmt.Printf("Found group '%s', processing its contents\n", groupName)
 	}
@@ -970,3 +1324,33 @@ func (p *XSDParser) processGroupRef(groupRef types.XSDGroupRef, goType *types.Go
 
 	return nil
 }
+
+// findGroup looks up a named model group by qualified name and returns it
+// with its namespace. References without a namespace search the current
+// schema first.
+func (p *XSDParser) findGroup(qname types.QName) (*types.XSDGroup, string) {
+	namespaces := []string{qname.Space}
+	if qname.Space == "" {
+		namespaces = append([]string{p.currentNS, p.targetNamespace}, p.importedNamespaces()...)
+	}
+	for _, namespace := range namespaces {
+		schema := p.schemaForNamespace(namespace)
+		if schema == nil {
+			continue
+		}
+		for i := range schema.Groups {
+			if schema.Groups[i].Name == qname.Local {
+				return &schema.Groups[i], namespace
+			}
+		}
+	}
+	return nil, ""
+}
+
+// schemaForNamespace returns the merged schema for a target namespace
+func (p *XSDParser) schemaForNamespace(namespace string) *types.XSDSchema {
+	if namespace == p.targetNamespace {
+		return p.schema
+	}
+	return p.imports[namespace]
+}
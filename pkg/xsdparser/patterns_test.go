@@ -0,0 +1,81 @@
+package xsdparser_test
+
+import (
+	"strings"
+	"testing"
+)
+
+const patternsSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:t" targetNamespace="urn:t">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string"><xs:pattern value="[A-Z]{3}"/><xs:pattern value="[0-9]{3}"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="LetterCode">
+    <xs:restriction base="t:Code"><xs:pattern value="A.*"/><xs:pattern value="B.*"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="LetterAlias"><xs:restriction base="t:LetterCode"/></xs:simpleType>
+  <xs:simpleType name="Color">
+    <xs:restriction base="xs:string"><xs:enumeration value="red"/><xs:enumeration value="green"/><xs:enumeration value="blue"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Warm">
+    <xs:restriction base="t:Color"><xs:enumeration value="red"/><xs:enumeration value="orange"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="ShortColor">
+    <xs:restriction base="t:Color"><xs:maxLength value="4"/></xs:restriction>
+  </xs:simpleType>
+  <xs:element name="root">
+    <xs:complexType><xs:sequence>
+      <xs:element name="code" type="t:LetterAlias"/>
+      <xs:element name="warm" type="t:Warm"/>
+      <xs:element name="short" type="t:ShortColor"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+// TestPatternSteps checks that the patterns of one derivation step are
+// alternatives and the patterns of every step apply
+func TestPatternSteps(t *testing.T) {
+	tests := []validateCall{
+		{`Code("ABC")`, true},
+		{`Code("123")`, true},
+		{`Code("A1C")`, false},
+		{`LetterCode("ABC")`, true},
+		{`LetterCode("BCD")`, true},
+		{`LetterCode("CDE")`, false},
+		{`LetterCode("123")`, false},
+		{`LetterCode("Axyz")`, false},
+		{`LetterAlias("AXY")`, true},
+		{`LetterAlias("XYZ")`, false},
+		{`LetterAlias("123")`, false},
+	}
+	checkValidate(t, patternsSchema, tests)
+}
+
+// TestEnumerationSteps gives a derived type the enumeration values of its
+// own step that its base allows, or those of its base when it declares none
+func TestEnumerationSteps(t *testing.T) {
+	parser, _ := parseFiles(t, map[string]string{"main.xsd": patternsSchema})
+	values := make(map[string]string)
+	for _, goType := range parser.GetGoTypes() {
+		list := make([]string, 0, len(goType.Constants))
+		for _, constant := range goType.Constants {
+			list = append(list, constant.Name)
+		}
+		values[goType.Name] = strings.Join(list, " ")
+	}
+
+	tests := []struct {
+		typeName string
+		want     string
+	}{
+		{"Color", "ColorRed ColorGreen ColorBlue"},
+		{"Warm", "WarmRed"},
+		{"ShortColor", "ShortColorRed ShortColorGreen ShortColorBlue"},
+	}
+	for _, test := range tests {
+		if got := values[test.typeName]; got != test.want {
+			t.Errorf("%s: enumeration constants %q, want %q", test.typeName, got, test.want)
+		}
+	}
+}
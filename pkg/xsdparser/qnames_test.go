@@ -0,0 +1,83 @@
+package xsdparser_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/xsdparser"
+)
+
+const qnamesMainSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns="urn:a" xmlns:t="urn:a" xmlns:o="urn:b" targetNamespace="urn:a" elementFormDefault="qualified">
+  <xs:import namespace="urn:b" schemaLocation="b.xsd"/>
+  <xs:complexType name="Foo"><xs:sequence><xs:element name="a" type="xs:string"/></xs:sequence></xs:complexType>
+  <xs:element name="root">
+    <xs:complexType><xs:sequence>
+      <xs:element name="prefixed" type="t:Foo"/>
+      <xs:element name="unprefixed" type="Foo"/>
+      <xs:element name="other" type="o:Foo"/>
+      <xs:element name="redeclared" type="t:Foo" xmlns:t="urn:b"/>
+      <xs:element name="nested" xmlns:n="urn:b">
+        <xs:complexType><xs:sequence><xs:element name="inner" type="n:Foo"/></xs:sequence></xs:complexType>
+      </xs:element>
+      <xs:element name="reference" type="o:Ref"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+// qnamesImportedSchema uses the prefix t for its own namespace
+const qnamesImportedSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:b" targetNamespace="urn:b">
+  <xs:complexType name="Foo"><xs:sequence><xs:element name="b" type="xs:int"/></xs:sequence></xs:complexType>
+  <xs:complexType name="Ref"><xs:sequence><xs:element name="foo" type="t:Foo"/></xs:sequence></xs:complexType>
+</xs:schema>
+`
+
+// TestQNameResolution resolves type references against the prefixes in
+// scope where they appear, so same-named types of different namespaces
+// stay apart
+func TestQNameResolution(t *testing.T) {
+	parser, dir := parseFiles(t, map[string]string{"main.xsd": qnamesMainSchema, "b.xsd": qnamesImportedSchema})
+
+	fields := make(map[string]string)
+	namespaces := make(map[string]string)
+	for _, goType := range parser.GetGoTypes() {
+		namespaces[goType.Name] = goType.Namespace
+		for _, field := range goType.Fields {
+			fields[goType.Name+"."+field.Name] = field.Type
+		}
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"Root.Prefixed", "Foo"},
+		{"Root.Unprefixed", "Foo"},
+		{"Root.Other", "NFoo"},
+		{"Root.Redeclared", "NFoo"},
+		{"RootNested.Inner", "NFoo"},
+		{"Ref.Foo", "NFoo"},
+	}
+	for _, test := range tests {
+		if got := fields[test.field]; got != test.want {
+			t.Errorf("%s: type %q, want %q", test.field, got, test.want)
+		}
+	}
+	if namespaces["Foo"] != "urn:a" || namespaces["NFoo"] != "urn:b" {
+		t.Errorf("namespaces Foo %q, NFoo %q, want urn:a and urn:b", namespaces["Foo"], namespaces["NFoo"])
+	}
+
+	set := xsdparser.NewSchemaSet()
+	root, err := set.Load(filepath.Join(dir, "main.xsd"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	declared := map[string]string{"": "urn:a", "t": "urn:a", "o": "urn:b", "n": "urn:b"}
+	for prefix, want := range declared {
+		if got := root.Schema.Xmlns[prefix]; got != want {
+			t.Errorf("Xmlns[%q] = %q, want %q", prefix, got, want)
+		}
+	}
+}
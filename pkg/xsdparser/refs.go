@@ -0,0 +1,112 @@
+package xsdparser
+
+import (
+	"fmt"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// globalAttribute is a top-level attribute declaration with its namespace
+type globalAttribute struct {
+	attribute *types.XSDAttribute
+	namespace string
+}
+
+// findGlobalAttribute looks up a top-level attribute declaration by
+// qualified name. References without a namespace are matched by local name.
+// The attributes of the xml namespace are built in and need no declaration.
+func (p *XSDParser) findGlobalAttribute(qname types.QName) *globalAttribute {
+	namespaces := []string{qname.Space}
+	if qname.Space == "" {
+		namespaces = append([]string{p.currentNS, p.targetNamespace}, p.importedNamespaces()...)
+	}
+	for _, namespace := range namespaces {
+		schema := p.schemaForNamespace(namespace)
+		if schema == nil {
+			continue
+		}
+		for i := range schema.Attributes {
+			if schema.Attributes[i].Name == qname.Local {
+				return &globalAttribute{attribute: &schema.Attributes[i], namespace: namespace}
+			}
+		}
+	}
+	if qname.Space == types.XMLNamespace {
+		return &globalAttribute{attribute: &types.XSDAttribute{Name: qname.Local}, namespace: qname.Space}
+	}
+	return nil
+}
+
+// convertElementRef converts a reference to a global element. Name, type
+// and namespace come from the declaration, occurrence from the reference.
+func (p *XSDParser) convertElementRef(ref types.XSDElement) (*types.GoField, error) {
+	qname := types.ParseQName(ref.Ref)
+	found := p.findGlobalElement(qname)
+	if found == nil {
+		if p.strictMode {
+			return nil, fmt.Errorf("referenced element %s not found", qname.Local)
+		}
+		if p.debugMode {
+			fmt.Printf("Warning: referenced element '%s' not found, using string\n", qname.Local)
+		}
+		ref.Name = qname.Local
+		field := p.newElementField(ref, "string")
+		p.qualifyTag(field, qname.Space)
+		return field, nil
+	}
+
+	element := *found.element
+	element.MinOccurs, element.MaxOccurs = ref.MinOccurs, ref.MaxOccurs
+	if ref.Annotation != nil {
+		element.Annotation = ref.Annotation
+	}
+	field := p.newElementField(element, p.globalElementType(found))
+	p.qualifyTag(field, found.namespace)
+	return field, nil
+}
+
+// convertAttributeRef converts a reference to a global attribute. Name, type
+// and namespace come from the declaration; use, default and fixed may be
+// given by the reference.
+func (p *XSDParser) convertAttributeRef(ref types.XSDAttribute) (*types.GoField, error) {
+	qname := types.ParseQName(ref.Ref)
+	found := p.findGlobalAttribute(qname)
+	if found == nil {
+		if p.strictMode {
+			return nil, fmt.Errorf("referenced attribute %s not found", qname.Local)
+		}
+		if p.debugMode {
+			fmt.Printf("Warning: referenced attribute '%s' not found, using string\n", qname.Local)
+		}
+		found = &globalAttribute{attribute: &types.XSDAttribute{Name: qname.Local}, namespace: qname.Space}
+	}
+
+	attr := *found.attribute
+	attr.Use = ref.Use
+	if ref.Default != "" {
+		attr.Default = ref.Default
+	}
+	if ref.Fixed != "" {
+		attr.Fixed = ref.Fixed
+	}
+	if ref.Annotation != nil {
+		attr.Annotation = ref.Annotation
+	}
+	field, err := p.convertAttribute(attr, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Global attributes are always qualified
+	if found.namespace != "" {
+		field.XMLTag = found.namespace + " " + field.XMLTag
+	}
+	return field, nil
+}
+
+// qualifyTag adds the namespace of a referenced global element to the XML
+// tag of its field when it differs from the namespace being converted
+func (p *XSDParser) qualifyTag(field *types.GoField, namespace string) {
+	if namespace != "" && namespace != p.currentNS {
+		field.XMLTag = namespace + " " + field.XMLTag
+	}
+}
@@ -0,0 +1,114 @@
+package xsdparser_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/types"
+	"github.com/suifei/xsd2code/pkg/xsdparser"
+)
+
+const refsSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:a="urn:a" xmlns:c="urn:c" targetNamespace="urn:a" elementFormDefault="qualified">
+  <xs:include schemaLocation="common.xsd"/>
+  <xs:import namespace="urn:c" schemaLocation="c.xsd"/>
+  <xs:element name="order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element ref="a:line" maxOccurs="unbounded"/>
+        <xs:element ref="a:note" minOccurs="0"/>
+        <xs:element ref="c:stamp"/>
+      </xs:sequence>
+      <xs:attribute ref="c:origin" use="required"/>
+      <xs:attribute ref="a:channel" default="web"/>
+    </xs:complexType>
+  </xs:element>
+  <xs:attribute name="channel" type="xs:string"/>
+</xs:schema>
+`
+
+const refsCommonSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:a" elementFormDefault="qualified">
+  <xs:element name="line" type="xs:int"/>
+  <xs:element name="note" type="xs:string"/>
+</xs:schema>
+`
+
+const refsImportedSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:c">
+  <xs:element name="stamp" type="xs:dateTime"/>
+  <xs:attribute name="origin" type="xs:string"/>
+</xs:schema>
+`
+
+// TestGlobalReferences takes name, type and namespace of element and
+// attribute references from the global declarations, across included and
+// imported schemas, and occurrence and use from the reference
+func TestGlobalReferences(t *testing.T) {
+	parser, _ := parseFiles(t, map[string]string{"main.xsd": refsSchema, "common.xsd": refsCommonSchema, "c.xsd": refsImportedSchema})
+	var order *types.GoType
+	for _, goType := range parser.GetGoTypes() {
+		if goType.Name == "Order" {
+			order = &goType
+		}
+	}
+	if order == nil {
+		t.Fatal("type Order not generated")
+	}
+
+	tests := []struct {
+		name, typ, tag string
+		defaultValue   string
+	}{
+		{"Line", "[]int32", "line", ""},
+		{"Note", "*string", "note,omitempty", ""},
+		{"Stamp", "xsd.DateTime", "urn:c stamp", ""},
+		{"Origin", "string", "urn:c origin,attr", ""},
+		{"Channel", "*string", "urn:a channel,attr,omitempty", "web"},
+	}
+	fields := make(map[string]types.GoField)
+	for _, field := range order.Fields {
+		fields[field.Name] = field
+	}
+	for _, test := range tests {
+		field, exists := fields[test.name]
+		if !exists {
+			t.Errorf("field %s missing", test.name)
+			continue
+		}
+		if field.Type != test.typ || field.XMLTag != test.tag || field.DefaultValue != test.defaultValue {
+			t.Errorf("field %s: %s `%s` default %q, want %s `%s` default %q", test.name, field.Type, field.XMLTag, field.DefaultValue, test.typ, test.tag, test.defaultValue)
+		}
+	}
+}
+
+// TestMissingReferences reports references to undeclared elements and
+// attributes in strict mode
+func TestMissingReferences(t *testing.T) {
+	tests := []struct {
+		declaration string
+		err         string
+	}{
+		{`<xs:sequence><xs:element ref="a:missing"/></xs:sequence>`, "referenced element missing not found"},
+		{`<xs:attribute ref="a:missing"/>`, "referenced attribute missing not found"},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "main.xsd"), `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:a="urn:a" targetNamespace="urn:a">
+  <xs:complexType name="Order">`+test.declaration+`</xs:complexType>
+</xs:schema>
+`)
+		parser := xsdparser.NewUnifiedXSDParser(filepath.Join(dir, "main.xsd"), filepath.Join(dir, "out.go"), "gen")
+		parser.SetStrictMode(true)
+		if err := parser.Parse(); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error = %v, want %s", test.declaration, err, test.err)
+		}
+
+		parser = xsdparser.NewUnifiedXSDParser(filepath.Join(dir, "main.xsd"), filepath.Join(dir, "out.go"), "gen")
+		if err := parser.Parse(); err != nil {
+			t.Errorf("%s: error = %v outside strict mode", test.declaration, err)
+		}
+	}
+}
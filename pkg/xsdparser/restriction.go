@@ -0,0 +1,170 @@
+package xsdparser
+
+import (
+	"fmt"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// processRestriction processes a complexContent restriction. The fields of
+// the base type are copied, base elements the restriction no longer declares
+// are removed, redeclared particles and attributes take their restricted form
+// and prohibited attributes are dropped.
+func (p *XSDParser) processRestriction(restriction *types.XSDRestriction, goType *types.GoType) error {
+	if base := types.ParseQName(restriction.Base); restriction.Base != "" && base.Space != types.XSDNamespace {
+		baseComment := fmt.Sprintf("restricts %s", p.mapXSDTypeToGo(restriction.Base))
+		if goType.Comment != "" {
+			goType.Comment += "; " + baseComment
+		} else {
+			goType.Comment = baseComment
+		}
+	}
+
+	baseFields, err := p.baseTypeFields(restriction.Base)
+	if err != nil {
+		return err
+	}
+
+	// Convert the particles and attributes declared by the restriction itself
+	restricted := &types.GoType{Name: goType.Name, Fields: make([]types.GoField, 0)}
+	contextPath := p.typePath(goType.Name)
+	if restriction.Sequence != nil {
+		if err := p.processSequenceWithContext(restriction.Sequence, restricted, contextPath); err != nil {
+			return err
+		}
+	}
+	if restriction.Choice != nil {
+		if err := p.processChoiceWithContext(restriction.Choice, restricted, contextPath); err != nil {
+			return err
+		}
+	}
+	if restriction.All != nil {
+		if err := p.processAllWithContext(restriction.All, restricted, contextPath); err != nil {
+			return err
+		}
+	}
+	if restriction.Group != nil {
+		if err := p.processGroupRef(*restriction.Group, restricted, contextPath); err != nil {
+			return err
+		}
+	}
+
+	attributes, wildcard, err := p.expandAttributes(restriction.Attributes, restriction.AttributeGroups, restriction.AnyAttribute)
+	if err != nil {
+		return err
+	}
+	prohibited := make(map[string]bool)
+	for _, attr := range attributes {
+		if attr.Use == "prohibited" {
+			name := attr.Name
+			if name == "" {
+				name = types.LocalName(attr.Ref)
+			}
+			prohibited[types.ToGoFieldName(name)] = true
+			continue
+		}
+		field, err := p.convertAttribute(attr, contextPath)
+		if err != nil {
+			return err
+		}
+		restricted.Fields = append(restricted.Fields, *field)
+	}
+	p.processAnyAttribute(wildcard, restricted)
+
+	goType.Fields = append(goType.Fields, mergeRestrictedFields(baseFields, restricted.Fields, prohibited)...)
+	return nil
+}
+
+// baseTypeFields converts the complex base type of a derivation and returns
+// its fields. Built-in and simple bases contribute no fields.
+func (p *XSDParser) baseTypeFields(base string) ([]types.GoField, error) {
+	qname := types.ParseQName(base)
+	if base == "" || qname.Space == types.XSDNamespace {
+		return nil, nil
+	}
+	baseType, namespace := p.findComplexType(qname)
+	if baseType == nil {
+		if p.strictMode && p.findSimpleType(qname) == nil {
+			return nil, fmt.Errorf("base type %s not found", base)
+		}
+		return nil, nil
+	}
+
+	key := types.QName{Space: namespace, Local: baseType.Name}
+	if p.deriving[key] {
+		return nil, fmt.Errorf("circular derivation of type %s", baseType.Name)
+	}
+	p.deriving[key] = true
+	defer delete(p.deriving, key)
+
+	// Convert the base in the context of the schema that declares it
+	currentSchema, currentNS := p.currentSchema, p.currentNS
+	p.currentSchema, p.currentNS = p.schemaForNamespace(namespace), namespace
+	defer func() { p.currentSchema, p.currentNS = currentSchema, currentNS }()
+
+	converted, err := p.convertComplexType(*baseType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert base type %s: %v", baseType.Name, err)
+	}
+	return converted.Fields, nil
+}
+
+// findComplexType looks up a named complex type by qualified name and
+// returns it with its namespace. References without a namespace search the
+// current schema first.
+func (p *XSDParser) findComplexType(qname types.QName) (*types.XSDComplexType, string) {
+	namespaces := []string{qname.Space}
+	if qname.Space == "" {
+		namespaces = append([]string{p.currentNS, p.targetNamespace}, p.importedNamespaces()...)
+	}
+	for _, namespace := range namespaces {
+		schema := p.schemaForNamespace(namespace)
+		if schema == nil {
+			continue
+		}
+		for i := range schema.ComplexTypes {
+			if schema.ComplexTypes[i].Name == qname.Local {
+				return &schema.ComplexTypes[i], namespace
+			}
+		}
+	}
+	return nil, ""
+}
+
+// mergeRestrictedFields applies a restriction to the fields of its base.
+// Elements are kept in base order only when the restriction redeclares them,
+// while text content and attributes are inherited unless prohibited. An
+// attribute wildcard is kept only when the restriction declares one.
+func mergeRestrictedFields(base, restricted []types.GoField, prohibited map[string]bool) []types.GoField {
+	declared := make(map[string]int, len(restricted))
+	for i, field := range restricted {
+		declared[restrictionKey(field)] = i
+	}
+
+	used := make(map[int]bool, len(restricted))
+	merged := make([]types.GoField, 0, len(base)+len(restricted))
+	for _, field := range base {
+		if i, ok := declared[restrictionKey(field)]; ok {
+			merged = append(merged, restricted[i])
+			used[i] = true
+			continue
+		}
+		if field.IsText || (field.IsAttribute && !field.IsWildcard && !prohibited[field.Name]) {
+			merged = append(merged, field)
+		}
+	}
+	for i, field := range restricted {
+		if !used[i] {
+			merged = append(merged, field)
+		}
+	}
+	return merged
+}
+
+// restrictionKey identifies a field across a base type and its restriction
+func restrictionKey(field types.GoField) string {
+	if field.IsAttribute {
+		return "@" + field.Name
+	}
+	return field.Name
+}
@@ -0,0 +1,79 @@
+package xsdparser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+const restrictionSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:t" targetNamespace="urn:t" elementFormDefault="qualified">
+  <xs:complexType name="Base">
+    <xs:sequence>
+      <xs:element name="item" type="xs:string" maxOccurs="unbounded"/>
+      <xs:element name="note" type="xs:string" minOccurs="0"/>
+      <xs:element name="extra" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:string"/>
+    <xs:attribute name="lang" type="xs:language"/>
+    <xs:anyAttribute namespace="##other"/>
+  </xs:complexType>
+  <xs:complexType name="Single">
+    <xs:complexContent>
+      <xs:restriction base="t:Base">
+        <xs:sequence>
+          <xs:element name="item" type="xs:string"/>
+          <xs:element name="note" type="xs:string" minOccurs="0"/>
+        </xs:sequence>
+        <xs:attribute name="id" type="xs:string" use="required"/>
+        <xs:attribute name="lang" use="prohibited"/>
+      </xs:restriction>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="Bounded">
+    <xs:complexContent>
+      <xs:restriction base="t:Base">
+        <xs:sequence><xs:element name="item" type="xs:string" maxOccurs="3"/></xs:sequence>
+        <xs:anyAttribute namespace="##other"/>
+      </xs:restriction>
+    </xs:complexContent>
+  </xs:complexType>
+</xs:schema>
+`
+
+// TestComplexContentRestriction copies the content of the base type,
+// narrowed to the particles the restriction redeclares and without the
+// prohibited attributes
+func TestComplexContentRestriction(t *testing.T) {
+	parser, _ := parseFiles(t, map[string]string{"main.xsd": restrictionSchema})
+	goTypes := make(map[string]types.GoType)
+	for _, goType := range parser.GetGoTypes() {
+		goTypes[goType.Name] = goType
+	}
+
+	tests := []struct {
+		typeName string
+		fields   []string // name and Go type of each field in order
+	}{
+		{"Single", []string{"Item string", "Note *string", "Id string"}},
+		{"Bounded", []string{"Item []string", "Id *string", "Lang *string", "AnyAttrs AnyAttributes"}},
+	}
+	for _, test := range tests {
+		goType, exists := goTypes[test.typeName]
+		if !exists {
+			t.Errorf("type %s not generated", test.typeName)
+			continue
+		}
+		fields := make([]string, len(goType.Fields))
+		for i, field := range goType.Fields {
+			fields[i] = field.Name + " " + field.Type
+		}
+		if strings.Join(fields, ", ") != strings.Join(test.fields, ", ") {
+			t.Errorf("%s: fields %v, want %v", test.typeName, fields, test.fields)
+		}
+		if !strings.Contains(goType.Comment, "restricts Base") {
+			t.Errorf("%s: comment %q does not name the base type", test.typeName, goType.Comment)
+		}
+	}
+}
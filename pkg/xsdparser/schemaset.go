@@ -0,0 +1,278 @@
+package xsdparser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// Component kinds used to record where a schema component was defined
+const (
+	ComponentElement        = "element"
+	ComponentAttribute      = "attribute"
+	ComponentComplexType    = "complexType"
+	ComponentSimpleType     = "simpleType"
+	ComponentGroup          = "group"
+	ComponentAttributeGroup = "attributeGroup"
+)
+
+// SchemaDocument is a single schema file loaded into a SchemaSet
+type SchemaDocument struct {
+	Path            string
+	TargetNamespace string
+	Schema          *types.XSDSchema
+	Includes        []string // canonical paths of included documents
+	Imports         []string // canonical paths of imported documents
+}
+
+// SchemaSet holds the transitive closure of a root schema's includes and imports.
+// Documents are de-duplicated by canonical path and target namespace, so circular
+// includes are loaded once and a chameleon include once per including namespace.
+type SchemaSet struct {
+	documents  map[string]*SchemaDocument // keyed by documentKey
+	namespaces map[string]string          // declared target namespace by canonical path
+	order      []string
+	stack      []string
+	cycles     [][]string
+	sources    map[string]string
+	errors     []error
+	debugMode  bool
+}
+
+// NewSchemaSet creates an empty schema set
+func NewSchemaSet() *SchemaSet {
+	return &SchemaSet{
+		documents:  make(map[string]*SchemaDocument),
+		namespaces: make(map[string]string),
+		order:      make([]string, 0),
+		cycles:     make([][]string, 0),
+		sources:    make(map[string]string),
+		errors:     make([]error, 0),
+	}
+}
+
+// SetDebugMode enables or disables debug output while loading
+func (s *SchemaSet) SetDebugMode(debug bool) {
+	s.debugMode = debug
+}
+
+// Load loads a root schema file together with everything it includes or imports.
+// Only a failure to load the root document is returned; problems with referenced
+// documents are collected and available through Errors.
+func (s *SchemaSet) Load(path string) (*SchemaDocument, error) {
+	return s.load(path, "")
+}
+
+// load loads a document and its references. A non-empty chameleonNS is applied to
+// included documents that have no target namespace of their own.
+func (s *SchemaSet) load(path, chameleonNS string) (*SchemaDocument, error) {
+	canonical, err := canonicalPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// A document without a target namespace of its own takes the chameleon one
+	if declared, exists := s.namespaces[canonical]; exists {
+		namespace := declared
+		if namespace == "" {
+			namespace = chameleonNS
+		}
+		key := documentKey(canonical, namespace)
+
+		// A document that is still being loaded further up the stack closes a cycle
+		for i, loading := range s.stack {
+			if loading == key {
+				cycle := make([]string, 0, len(s.stack)-i+1)
+				for _, entry := range s.stack[i:] {
+					cycle = append(cycle, s.documents[entry].Path)
+				}
+				cycle = append(cycle, canonical)
+				s.cycles = append(s.cycles, cycle)
+				if s.debugMode {
+					fmt.Printf("Detected schema reference cycle: %s\n", strings.Join(cycle, " -> "))
+				}
+				return s.documents[key], nil
+			}
+		}
+
+		if doc, exists := s.documents[key]; exists {
+			return doc, nil
+		}
+	}
+
+	if s.debugMode {
+		fmt.Printf("Loading schema document: %s\n", canonical)
+	}
+
+	content, err := os.ReadFile(canonical)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", canonical, err)
+	}
+
+	schema, err := unmarshalSchema(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XML in %s: %v", canonical, err)
+	}
+	s.namespaces[canonical] = schema.TargetNamespace
+	if schema.TargetNamespace == "" && chameleonNS != "" {
+		schema.TargetNamespace = chameleonNS
+	}
+
+	doc := &SchemaDocument{
+		Path:            canonical,
+		TargetNamespace: schema.TargetNamespace,
+		Schema:          schema,
+		Includes:        make([]string, 0),
+		Imports:         make([]string, 0),
+	}
+	key := documentKey(canonical, doc.TargetNamespace)
+	s.documents[key] = doc
+	s.order = append(s.order, key)
+	s.recordSources(doc)
+
+	s.stack = append(s.stack, key)
+	defer func() { s.stack = s.stack[:len(s.stack)-1] }()
+
+	baseDir := filepath.Dir(canonical)
+	for _, inc := range schema.Includes {
+		if inc.SchemaLocation == "" {
+			continue
+		}
+		includePath := resolveLocation(baseDir, inc.SchemaLocation)
+		included, err := s.load(includePath, doc.TargetNamespace)
+		if err != nil {
+			s.errors = append(s.errors, fmt.Errorf("include %s in %s: %v", inc.SchemaLocation, canonical, err))
+			continue
+		}
+		if target, err := canonicalPath(includePath); err == nil {
+			doc.Includes = append(doc.Includes, target)
+		}
+		if included != nil && included.TargetNamespace != doc.TargetNamespace {
+			s.errors = append(s.errors, fmt.Errorf("include %s in %s: target namespace %q does not match %q",
+				inc.SchemaLocation, canonical, included.TargetNamespace, doc.TargetNamespace))
+		}
+	}
+
+	for _, imp := range schema.Imports {
+		if imp.SchemaLocation == "" {
+			continue // Skip imports without schema location
+		}
+		importPath := resolveLocation(baseDir, imp.SchemaLocation)
+		if _, err := s.load(importPath, ""); err != nil {
+			s.errors = append(s.errors, fmt.Errorf("import %s in %s: %v", imp.SchemaLocation, canonical, err))
+			continue
+		}
+		if target, err := canonicalPath(importPath); err == nil {
+			doc.Imports = append(doc.Imports, target)
+		}
+	}
+
+	return doc, nil
+}
+
+// recordSources remembers which document defines each global component
+func (s *SchemaSet) recordSources(doc *SchemaDocument) {
+	schema := doc.Schema
+	record := func(kind, name string) {
+		if name == "" {
+			return
+		}
+		key := componentKey(kind, doc.TargetNamespace, name)
+		if existing, exists := s.sources[key]; exists && existing != doc.Path {
+			s.errors = append(s.errors, fmt.Errorf("%s %s is defined in both %s and %s", kind, name, existing, doc.Path))
+			return
+		}
+		s.sources[key] = doc.Path
+	}
+
+	for _, element := range schema.Elements {
+		record(ComponentElement, element.Name)
+	}
+	for _, attribute := range schema.Attributes {
+		record(ComponentAttribute, attribute.Name)
+	}
+	for _, complexType := range schema.ComplexTypes {
+		record(ComponentComplexType, complexType.Name)
+	}
+	for _, simpleType := range schema.SimpleTypes {
+		record(ComponentSimpleType, simpleType.Name)
+	}
+	for _, group := range schema.Groups {
+		record(ComponentGroup, group.Name)
+	}
+	for _, attributeGroup := range schema.AttributeGroups {
+		record(ComponentAttributeGroup, attributeGroup.Name)
+	}
+}
+
+// Documents returns all loaded documents in load order, root first
+func (s *SchemaSet) Documents() []*SchemaDocument {
+	docs := make([]*SchemaDocument, 0, len(s.order))
+	for _, key := range s.order {
+		docs = append(docs, s.documents[key])
+	}
+	return docs
+}
+
+// Document returns the loaded document for a path, or nil. A chameleon
+// document loaded into several namespaces is returned as first loaded.
+func (s *SchemaSet) Document(path string) *SchemaDocument {
+	canonical, err := canonicalPath(path)
+	if err != nil {
+		return nil
+	}
+	for _, key := range s.order {
+		if doc := s.documents[key]; doc.Path == canonical {
+			return doc
+		}
+	}
+	return nil
+}
+
+// Cycles returns the include/import cycles found while loading
+func (s *SchemaSet) Cycles() [][]string {
+	return s.cycles
+}
+
+// Errors returns the problems found with referenced documents
+func (s *SchemaSet) Errors() []error {
+	return s.errors
+}
+
+// SourceFile returns the path of the document defining a global component
+func (s *SchemaSet) SourceFile(kind, namespace, name string) string {
+	return s.sources[componentKey(kind, namespace, name)]
+}
+
+// documentKey identifies a document loaded into a target namespace
+func documentKey(path, namespace string) string {
+	return path + "|" + namespace
+}
+
+// componentKey builds the lookup key for a global component
+func componentKey(kind, namespace, name string) string {
+	return kind + "|" + namespace + "|" + name
+}
+
+// resolveLocation resolves a schemaLocation relative to the referencing document
+func resolveLocation(baseDir, location string) string {
+	if filepath.IsAbs(location) {
+		return location
+	}
+	return filepath.Join(baseDir, filepath.FromSlash(location))
+}
+
+// canonicalPath returns an absolute, symlink-free path used to identify documents
+func canonicalPath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %v", path, err)
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		return resolved, nil
+	}
+	return absPath, nil
+}
@@ -0,0 +1,165 @@
+package xsdparser_test
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/xsdparser"
+)
+
+// schemaDocument returns a schema document with a target namespace, when
+// given, and the declarations
+func schemaDocument(namespace, declarations string) string {
+	targetNamespace := ""
+	if namespace != "" {
+		targetNamespace = ` targetNamespace="` + namespace + `"`
+	}
+	return `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"` + targetNamespace + `>
+` + declarations + `
+</xs:schema>
+`
+}
+
+const chameleonMoney = `<xs:complexType name="Money"><xs:attribute name="amount" type="xs:decimal"/></xs:complexType>`
+
+// TestSchemaSetLoad loads every document once per target namespace it ends
+// up in, so a chameleon include is loaded again for each including namespace
+func TestSchemaSetLoad(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		want   []string // loaded documents as file|namespace
+		cycles int
+	}{
+		{
+			name: "chameleon included into two namespaces",
+			files: map[string]string{
+				"main.xsd":  schemaDocument("urn:a", `<xs:include schemaLocation="money.xsd"/><xs:import namespace="urn:b" schemaLocation="b.xsd"/>`),
+				"b.xsd":     schemaDocument("urn:b", `<xs:include schemaLocation="money.xsd"/>`),
+				"money.xsd": schemaDocument("", chameleonMoney),
+			},
+			want: []string{"b.xsd|urn:b", "main.xsd|urn:a", "money.xsd|urn:a", "money.xsd|urn:b"},
+		},
+		{
+			name: "chameleon included twice into one namespace",
+			files: map[string]string{
+				"main.xsd":  schemaDocument("urn:a", `<xs:include schemaLocation="money.xsd"/><xs:include schemaLocation="part.xsd"/>`),
+				"part.xsd":  schemaDocument("urn:a", `<xs:include schemaLocation="money.xsd"/>`),
+				"money.xsd": schemaDocument("", chameleonMoney),
+			},
+			want: []string{"main.xsd|urn:a", "money.xsd|urn:a", "part.xsd|urn:a"},
+		},
+		{
+			name: "document with its own namespace imported twice",
+			files: map[string]string{
+				"main.xsd":  schemaDocument("urn:a", `<xs:import namespace="urn:m" schemaLocation="money.xsd"/><xs:import namespace="urn:b" schemaLocation="b.xsd"/>`),
+				"b.xsd":     schemaDocument("urn:b", `<xs:import namespace="urn:m" schemaLocation="money.xsd"/>`),
+				"money.xsd": schemaDocument("urn:m", chameleonMoney),
+			},
+			want: []string{"b.xsd|urn:b", "main.xsd|urn:a", "money.xsd|urn:m"},
+		},
+		{
+			name: "circular chameleon includes",
+			files: map[string]string{
+				"main.xsd":  schemaDocument("urn:a", `<xs:include schemaLocation="money.xsd"/>`),
+				"money.xsd": schemaDocument("", `<xs:include schemaLocation="main.xsd"/>`+chameleonMoney),
+			},
+			want:   []string{"main.xsd|urn:a", "money.xsd|urn:a"},
+			cycles: 1,
+		},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		for name, content := range test.files {
+			writeFile(t, filepath.Join(dir, name), content)
+		}
+		set := xsdparser.NewSchemaSet()
+		if _, err := set.Load(filepath.Join(dir, "main.xsd")); err != nil {
+			t.Fatalf("%s: load: %v", test.name, err)
+		}
+		if errs := set.Errors(); len(errs) > 0 {
+			t.Errorf("%s: errors %v", test.name, errs)
+		}
+		got := make([]string, 0)
+		for _, doc := range set.Documents() {
+			got = append(got, filepath.Base(doc.Path)+"|"+doc.TargetNamespace)
+		}
+		sort.Strings(got)
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%s: loaded %v, want %v", test.name, got, test.want)
+		}
+		if len(set.Cycles()) != test.cycles {
+			t.Errorf("%s: cycles %v, want %d", test.name, set.Cycles(), test.cycles)
+		}
+	}
+}
+
+// TestChameleonTypes generates a chameleon type in every namespace that
+// includes it
+func TestChameleonTypes(t *testing.T) {
+	parser, _ := parseFiles(t, map[string]string{
+		"main.xsd":  schemaDocument("urn:a", `<xs:include schemaLocation="money.xsd"/><xs:import namespace="urn:b" schemaLocation="b.xsd"/>`),
+		"b.xsd":     schemaDocument("urn:b", `<xs:include schemaLocation="money.xsd"/>`),
+		"money.xsd": schemaDocument("", chameleonMoney),
+	})
+	namespaces := make([]string, 0)
+	for _, goType := range parser.GetGoTypes() {
+		if strings.HasSuffix(goType.Name, "Money") {
+			namespaces = append(namespaces, goType.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	if strings.Join(namespaces, " ") != "urn:a urn:b" {
+		t.Errorf("Money generated in namespaces %v, want urn:a and urn:b", namespaces)
+	}
+}
+
+// TestSchemaSetSources records the document defining each global component
+// through chains of includes and reports components defined twice
+func TestSchemaSetSources(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.xsd":   schemaDocument("urn:a", `<xs:include schemaLocation="types.xsd"/><xs:element name="order" type="xs:string"/>`),
+		"types.xsd":  schemaDocument("urn:a", `<xs:include schemaLocation="common.xsd"/><xs:simpleType name="Code"><xs:restriction base="xs:string"/></xs:simpleType>`),
+		"common.xsd": schemaDocument("", `<xs:include schemaLocation="types.xsd"/><xs:attribute name="code" type="xs:string"/><xs:simpleType name="Code"><xs:restriction base="xs:token"/></xs:simpleType>`+chameleonMoney),
+	}
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+	set := xsdparser.NewSchemaSet()
+	if _, err := set.Load(filepath.Join(dir, "main.xsd")); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	tests := []struct {
+		kind, name string
+		want       string
+	}{
+		{xsdparser.ComponentElement, "order", "main.xsd"},
+		{xsdparser.ComponentSimpleType, "Code", "types.xsd"},
+		{xsdparser.ComponentComplexType, "Money", "common.xsd"},
+		{xsdparser.ComponentAttribute, "code", "common.xsd"},
+		{xsdparser.ComponentElement, "missing", ""},
+	}
+	for _, test := range tests {
+		got := set.SourceFile(test.kind, "urn:a", test.name)
+		if got != "" {
+			got = filepath.Base(got)
+		}
+		if got != test.want {
+			t.Errorf("SourceFile(%s %s) = %s, want %s", test.kind, test.name, got, test.want)
+		}
+	}
+
+	errs := set.Errors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "simpleType Code is defined in both") {
+		t.Errorf("errors %v, want the duplicate simpleType Code", errs)
+	}
+	if len(set.Cycles()) != 1 {
+		t.Errorf("cycles %v, want types.xsd -> common.xsd -> types.xsd", set.Cycles())
+	}
+}
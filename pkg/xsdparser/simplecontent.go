@@ -0,0 +1,129 @@
+package xsdparser
+
+import (
+	"fmt"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// textFieldName is the name of the field holding simpleContent character data
+const textFieldName = "Value"
+
+// processSimpleContent processes a simpleContent extension or restriction.
+// The text value becomes a chardata field next to the attribute fields.
+func (p *XSDParser) processSimpleContent(content *types.XSDSimpleContent, goType *types.GoType) error {
+	if extension := content.Extension; extension != nil {
+		baseFields, err := p.baseTypeFields(extension.Base)
+		if err != nil {
+			return err
+		}
+		if textField(baseFields) == nil {
+			baseFields = append([]types.GoField{p.newTextField(p.mapXSDTypeToGo(extension.Base))}, baseFields...)
+		}
+		goType.Fields = append(goType.Fields, baseFields...)
+		if err := p.processExtension(extension, goType); err != nil {
+			return err
+		}
+		renameTextField(goType)
+		return nil
+	}
+
+	restriction := content.Restriction
+	if restriction == nil {
+		return nil
+	}
+	start := len(goType.Fields)
+	if err := p.processRestriction(restriction, goType); err != nil {
+		return err
+	}
+	field := textField(goType.Fields[start:])
+	if field == nil {
+		goType.Fields = append(goType.Fields, types.GoField{})
+		copy(goType.Fields[start+1:], goType.Fields[start:])
+		goType.Fields[start] = p.newTextField(p.mapXSDTypeToGo(restriction.Base))
+		field = &goType.Fields[start]
+	}
+	if err := p.restrictTextField(restriction, field, goType.Name); err != nil {
+		return err
+	}
+	renameTextField(goType)
+	return nil
+}
+
+// renameTextField renames the chardata field when an attribute already
+// uses the Value name
+func renameTextField(goType *types.GoType) {
+	field := textField(goType.Fields)
+	if field == nil {
+		return
+	}
+	for _, other := range goType.Fields {
+		if !other.IsText && other.Name == field.Name {
+			field.Name = "Text" + textFieldName
+			return
+		}
+	}
+}
+
+// restrictTextField applies the facets of a simpleContent restriction to the
+// text field by generating a restricted <Type>Value type for it
+func (p *XSDParser) restrictTextField(restriction *types.XSDRestriction, field *types.GoField, owner string) error {
+	if !hasFacets(restriction) {
+		return nil
+	}
+	facets := *restriction
+	facets.Base = ""
+	facets.SimpleType = nil
+	valueType := types.XSDSimpleType{
+		Name:        p.claimName(types.NameType, "value "+owner, owner+"Value"),
+		Restriction: &facets,
+	}
+
+	if existing := p.findExistingType(valueType.Name); existing != nil {
+		field.Type = existing.Name
+		return nil
+	}
+	converted, err := p.convertSimpleType(valueType)
+	if err != nil {
+		return fmt.Errorf("failed to convert text content of %s: %v", owner, err)
+	}
+	converted.BaseType = field.Type
+	converted.Comment = fmt.Sprintf("represents the restricted text content of %s", owner)
+	p.goTypes = append(p.goTypes, *converted)
+	field.Type = converted.Name
+	return nil
+}
+
+// newTextField creates the chardata field of a simpleContent type
+func (p *XSDParser) newTextField(fieldType string) types.GoField {
+	field := types.GoField{
+		Name:   textFieldName,
+		Type:   fieldType,
+		XMLTag: ",chardata",
+		IsText: true,
+	}
+	if p.jsonCompatible {
+		field.JSONTag = "value"
+	}
+	return field
+}
+
+// textField returns the chardata field among fields, if any
+func textField(fields []types.GoField) *types.GoField {
+	for i := range fields {
+		if fields[i].IsText {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// hasFacets reports whether a restriction constrains the value space
+func hasFacets(restriction *types.XSDRestriction) bool {
+	return len(restriction.Enumerations) > 0 || len(restriction.Patterns) > 0 ||
+		restriction.Length != nil || restriction.MinLength != nil || restriction.MaxLength != nil ||
+		restriction.MinInclusive != nil || restriction.MaxInclusive != nil ||
+		restriction.MinExclusive != nil || restriction.MaxExclusive != nil ||
+		restriction.TotalDigits != nil || restriction.FractionDigits != nil ||
+		restriction.WhiteSpace != nil
+}
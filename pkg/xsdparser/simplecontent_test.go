@@ -0,0 +1,81 @@
+package xsdparser_test
+
+import (
+	"strings"
+	"testing"
+)
+
+const simpleContentSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:t" targetNamespace="urn:t" elementFormDefault="qualified">
+  <xs:complexType name="Price">
+    <xs:simpleContent>
+      <xs:extension base="xs:decimal"><xs:attribute name="currency" type="xs:string" use="required"/></xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:complexType name="SmallPrice">
+    <xs:simpleContent>
+      <xs:restriction base="t:Price"><xs:minExclusive value="0"/><xs:maxInclusive value="100"/></xs:restriction>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:simpleType name="Code"><xs:restriction base="xs:string"><xs:pattern value="[A-Z]{3}"/></xs:restriction></xs:simpleType>
+  <xs:complexType name="Coded">
+    <xs:simpleContent>
+      <xs:extension base="t:Code"><xs:attribute name="system" type="xs:string"/></xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+</xs:schema>
+`
+
+// TestSimpleContentValidate checks the facets of simpleContent restrictions
+// and of the simple types simpleContent extends
+func TestSimpleContentValidate(t *testing.T) {
+	tests := []validateCall{
+		{`SmallPriceValue(50)`, true},
+		{`SmallPriceValue(100)`, true},
+		{`SmallPriceValue(0)`, false},
+		{`SmallPriceValue(100.5)`, false},
+		{`Coded{Value: "EUR"}.Value`, true},
+		{`Coded{Value: "euro"}.Value`, false},
+	}
+	checkValidate(t, simpleContentSchema, tests)
+}
+
+// TestSimpleContentText reads and writes the text of simpleContent types
+// as character data next to their attributes, including the attributes a
+// restriction inherits
+func TestSimpleContentText(t *testing.T) {
+	parser, dir := parseFiles(t, map[string]string{"main.xsd": simpleContentSchema})
+	output := runGo(t, parser, dir, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	var price Price
+	if err := xml.Unmarshal([]byte("<Price xmlns=\"urn:t\" currency=\"EUR\">12.5</Price>"), &price); err != nil {
+		panic(err)
+	}
+	fmt.Println(price.Value, price.Currency)
+	var small SmallPrice
+	if err := xml.Unmarshal([]byte("<SmallPrice xmlns=\"urn:t\" currency=\"USD\">150</SmallPrice>"), &small); err != nil {
+		panic(err)
+	}
+	fmt.Println(small.Value, small.Currency, small.Value.Validate())
+	out, err := xml.Marshal(price)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+}
+`)
+	want := []string{
+		"12.5 EUR",
+		"150 USD false",
+		`<Price xmlns="urn:t" currency="EUR">12.5</Price>`,
+	}
+	if got := strings.Split(strings.TrimSpace(output), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output:\n%s\nwant:\n%s", output, strings.Join(want, "\n"))
+	}
+}
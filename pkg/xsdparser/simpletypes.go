@@ -0,0 +1,234 @@
+package xsdparser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// convertUnionType converts an XSD union to a string-backed Go type that
+// accepts a value of any of its member types. Anonymous member types are
+// generated as <Union>Member<N>.
+func (p *XSDParser) convertUnionType(xsdType types.XSDSimpleType) (*types.GoType, error) {
+	union := xsdType.Union
+	goType := &types.GoType{
+		Name:            p.typeName(xsdType.Name),
+		Package:         p.packageName,
+		Namespace:       p.currentNS,
+		BaseType:        "string",
+		Comment:         types.GetDocumentation(xsdType.Annotation),
+		IsUnion:         true,
+		UnionMembers:    make([]types.GoUnionMember, 0),
+		NeedsValidation: true,
+	}
+
+	for _, memberType := range strings.Fields(union.MemberTypes) {
+		goType.UnionMembers = append(goType.UnionMembers, p.unionMember(memberType))
+	}
+
+	for i, simpleType := range union.SimpleTypes {
+		simpleType.Name = p.claimName(types.NameType, fmt.Sprintf("member %s %d", goType.Name, i+1), fmt.Sprintf("%sMember%d", goType.Name, i+1))
+		memberType, err := p.convertSimpleType(simpleType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert member %d of union %s: %v", i+1, xsdType.Name, err)
+		}
+		if memberType == nil {
+			continue
+		}
+		p.goTypes = append(p.goTypes, *memberType)
+		goType.UnionMembers = append(goType.UnionMembers, types.GoUnionMember{TypeName: memberType.Name})
+	}
+
+	if len(goType.UnionMembers) == 0 {
+		return nil, fmt.Errorf("union %s has no member types", xsdType.Name)
+	}
+	return goType, nil
+}
+
+// unionMember resolves a member type reference of a union
+func (p *XSDParser) unionMember(memberType string) types.GoUnionMember {
+	qname := types.ParseQName(memberType)
+	if qname.Space != types.XSDNamespace {
+		if goName, exists := p.lookupTypeName(qname); exists {
+			return types.GoUnionMember{TypeName: goName}
+		}
+	}
+	return types.GoUnionMember{
+		TypeName: p.mapXSDTypeToGo(memberType),
+		XSDType:  qname.Local,
+	}
+}
+
+// convertListType converts an XSD list, or a restriction of one, to a Go
+// slice type. Anonymous item types are generated as <List>Item.
+func (p *XSDParser) convertListType(xsdType types.XSDSimpleType, list *types.XSDList, itemOwner string) (*types.GoType, error) {
+	goType := &types.GoType{
+		Name:            p.typeName(xsdType.Name),
+		Package:         p.packageName,
+		Namespace:       p.currentNS,
+		Comment:         types.GetDocumentation(xsdType.Annotation),
+		IsList:          true,
+		NeedsValidation: true,
+	}
+
+	itemType := p.mapXSDTypeToGo(list.ItemType)
+	if list.SimpleType != nil {
+		item := *list.SimpleType
+		owner := p.typeName(itemOwner)
+		item.Name = p.claimName(types.NameType, "item "+owner, owner+"Item")
+		itemType = "string"
+		if existing := p.findExistingType(item.Name); existing != nil {
+			itemType = existing.Name
+		} else {
+			converted, err := p.convertSimpleType(item)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert item type of list %s: %v", xsdType.Name, err)
+			}
+			if converted != nil {
+				p.goTypes = append(p.goTypes, *converted)
+				itemType = converted.Name
+			}
+		}
+	}
+	goType.ListItemType = itemType
+	goType.BaseType = "[]" + itemType
+
+	// Length facets of a restriction count items
+	if restriction := xsdType.Restriction; restriction != nil {
+		if restriction.Length != nil {
+			goType.HasLength = true
+			goType.Length = restriction.Length.Value
+		}
+		if restriction.MinLength != nil {
+			goType.HasMinLength = true
+			goType.MinLength = restriction.MinLength.Value
+		}
+		if restriction.MaxLength != nil {
+			goType.HasMaxLength = true
+			goType.MaxLength = restriction.MaxLength.Value
+		}
+	}
+	return goType, nil
+}
+
+// restrictedList returns the list a restriction derives from, either through
+// an anonymous base type or through a chain of named base types, along with
+// the name of the simple type that declares the list
+func (p *XSDParser) restrictedList(xsdType types.XSDSimpleType) (*types.XSDList, string) {
+	restriction := xsdType.Restriction
+	owner := xsdType.Name
+	visited := make(map[types.QName]bool)
+	for restriction != nil {
+		if restriction.SimpleType != nil {
+			if restriction.SimpleType.List != nil {
+				return restriction.SimpleType.List, owner
+			}
+			restriction = restriction.SimpleType.Restriction
+			continue
+		}
+		base := types.ParseQName(restriction.Base)
+		if base.Space == types.XSDNamespace || visited[base] {
+			return nil, ""
+		}
+		visited[base] = true
+		baseType := p.findSimpleType(base)
+		if baseType == nil {
+			return nil, ""
+		}
+		if baseType.List != nil {
+			return baseType.List, baseType.Name
+		}
+		restriction, owner = baseType.Restriction, baseType.Name
+	}
+	return nil, ""
+}
+
+// restrictionChain returns the restriction of a simple type followed by
+// those of its anonymous and named base types, most derived first
+func (p *XSDParser) restrictionChain(xsdType types.XSDSimpleType) []*types.XSDRestriction {
+	chain := make([]*types.XSDRestriction, 0)
+	restriction := xsdType.Restriction
+	visited := make(map[types.QName]bool)
+	for restriction != nil {
+		chain = append(chain, restriction)
+		if restriction.SimpleType != nil {
+			restriction = restriction.SimpleType.Restriction
+			continue
+		}
+		base := types.ParseQName(restriction.Base)
+		if base.Space == types.XSDNamespace || visited[base] {
+			break
+		}
+		visited[base] = true
+		baseType := p.findSimpleType(base)
+		if baseType == nil {
+			break
+		}
+		restriction = baseType.Restriction
+	}
+	return chain
+}
+
+// effectivePatterns returns one pattern per derivation step that has
+// patterns, base type first. Patterns of the same step are alternatives,
+// while a value must match the patterns of every step.
+func effectivePatterns(chain []*types.XSDRestriction) []string {
+	patterns := make([]string, 0)
+	for i := len(chain) - 1; i >= 0; i-- {
+		if len(chain[i].Patterns) > 0 {
+			patterns = append(patterns, types.JoinPatterns(chain[i].Patterns))
+		}
+	}
+	return patterns
+}
+
+// effectiveEnumerations returns the enumerations of the most derived step
+// that has any, keeping only the values every other such step allows too
+func effectiveEnumerations(chain []*types.XSDRestriction) []types.XSDEnumeration {
+	var result []types.XSDEnumeration
+	for _, restriction := range chain {
+		if len(restriction.Enumerations) == 0 {
+			continue
+		}
+		if result == nil {
+			result = restriction.Enumerations
+			continue
+		}
+		allowed := make(map[string]bool, len(restriction.Enumerations))
+		for _, enum := range restriction.Enumerations {
+			allowed[enum.Value] = true
+		}
+		kept := make([]types.XSDEnumeration, 0, len(result))
+		for _, enum := range result {
+			if allowed[enum.Value] {
+				kept = append(kept, enum)
+			}
+		}
+		result = kept
+	}
+	return result
+}
+
+// findSimpleType looks up a named simple type by qualified name.
+// References without a namespace search the current schema first.
+func (p *XSDParser) findSimpleType(qname types.QName) *types.XSDSimpleType {
+	schemas := []*types.XSDSchema{p.schemaForNamespace(qname.Space)}
+	if qname.Space == "" {
+		schemas = append(schemas, p.currentSchema, p.schema)
+		for _, namespace := range p.importedNamespaces() {
+			schemas = append(schemas, p.imports[namespace])
+		}
+	}
+	for _, schema := range schemas {
+		if schema == nil {
+			continue
+		}
+		for i := range schema.SimpleTypes {
+			if schema.SimpleTypes[i].Name == qname.Local {
+				return &schema.SimpleTypes[i]
+			}
+		}
+	}
+	return nil
+}
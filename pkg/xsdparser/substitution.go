@@ -0,0 +1,229 @@
+package xsdparser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// globalElement is a top-level element declaration with its namespace
+type globalElement struct {
+	element   *types.XSDElement
+	namespace string
+}
+
+// findGlobalElement looks up a top-level element declaration by qualified name.
+// References without a namespace are matched by local name.
+func (p *XSDParser) findGlobalElement(qname types.QName) *globalElement {
+	namespaces := []string{qname.Space}
+	if qname.Space == "" {
+		namespaces = append([]string{p.currentNS, p.targetNamespace}, p.importedNamespaces()...)
+	}
+	for _, namespace := range namespaces {
+		schema := p.schemaForNamespace(namespace)
+		if schema == nil {
+			continue
+		}
+		for i := range schema.Elements {
+			if schema.Elements[i].Name == qname.Local {
+				return &globalElement{element: &schema.Elements[i], namespace: namespace}
+			}
+		}
+	}
+	return nil
+}
+
+// computeSubstitutionGroups records the direct members of every substitution
+// group head and assigns Go names to the holder type and interface of each group
+func (p *XSDParser) computeSubstitutionGroups() {
+	p.substitutionMembers = make(map[types.QName][]types.QName)
+	p.substitutionGroups = make(map[types.QName]string)
+	p.substitutionInterfaces = make(map[types.QName]string)
+
+	namespaces := append([]string{p.targetNamespace}, p.importedNamespaces()...)
+	for _, namespace := range namespaces {
+		schema := p.schemaForNamespace(namespace)
+		if schema == nil {
+			continue
+		}
+		for _, element := range schema.Elements {
+			if element.SubstitutionGroup == "" {
+				continue
+			}
+			head := types.ParseQName(element.SubstitutionGroup)
+			if found := p.findGlobalElement(head); found != nil {
+				head = types.QName{Space: found.namespace, Local: found.element.Name}
+			}
+			member := types.QName{Space: namespace, Local: element.Name}
+			p.substitutionMembers[head] = append(p.substitutionMembers[head], member)
+		}
+	}
+
+	heads := make([]types.QName, 0, len(p.substitutionMembers))
+	for head := range p.substitutionMembers {
+		heads = append(heads, head)
+	}
+	sort.Slice(heads, func(i, j int) bool {
+		return heads[i].String() < heads[j].String()
+	})
+	for _, head := range heads {
+		p.substitutionGroups[head] = p.names.Claim(types.NameType, "group "+head.String(), types.ToGoTypeName(head.Local)+"Group")
+		p.substitutionInterfaces[head] = p.names.Claim(types.NameType, "interface "+head.String(), types.ToGoTypeName(head.Local)+"Member")
+	}
+}
+
+// substitutionGroupName returns the holder type name for a head element
+func (p *XSDParser) substitutionGroupName(head types.QName) (types.QName, string, bool) {
+	if found := p.findGlobalElement(head); found != nil {
+		head = types.QName{Space: found.namespace, Local: found.element.Name}
+	}
+	name, exists := p.substitutionGroups[head]
+	return head, name, exists
+}
+
+// substitutionGroupField converts a reference to a substitution group head
+// into a catch-all field that can hold any member of the group
+func (p *XSDParser) substitutionGroupField(element types.XSDElement) (*types.GoField, bool) {
+	head, groupName, exists := p.substitutionGroupName(types.ParseQName(element.Ref))
+	if !exists {
+		return nil, false
+	}
+
+	min, max := types.ParseOccurs(element.MinOccurs, element.MaxOccurs)
+	isOptional := min == 0
+	isArray := max > 1 || max == -1
+
+	fieldType := groupName
+	if isArray {
+		fieldType = "[]" + fieldType
+	} else if isOptional {
+		fieldType = "*" + fieldType
+	}
+
+	jsonTag := ""
+	if p.jsonCompatible {
+		jsonTag = types.ToSnakeCase(head.Local)
+		if isOptional {
+			jsonTag += ",omitempty"
+		}
+	}
+
+	return &types.GoField{
+		Name:       types.ToGoFieldName(head.Local),
+		Type:       fieldType,
+		XMLTag:     ",any",
+		JSONTag:    jsonTag,
+		Comment:    fmt.Sprintf("any element of the %s substitution group", head.Local),
+		IsElement:  true,
+		IsOptional: isOptional,
+		IsArray:    isArray,
+		MinOccurs:  min,
+		MaxOccurs:  max,
+	}, true
+}
+
+// convertSubstitutionGroups adds a holder type for every substitution group
+// and marks the member types as implementing the group's interface
+func (p *XSDParser) convertSubstitutionGroups() {
+	heads := make([]types.QName, 0, len(p.substitutionGroups))
+	for head := range p.substitutionGroups {
+		heads = append(heads, head)
+	}
+	sort.Slice(heads, func(i, j int) bool {
+		return heads[i].String() < heads[j].String()
+	})
+
+	for _, head := range heads {
+		groupName := p.substitutionGroups[head]
+		interfaceName := p.substitutionInterfaces[head]
+		groupType := types.GoType{
+			Name:                groupName,
+			Package:             p.packageName,
+			XMLName:             head.Local,
+			Namespace:           head.Space,
+			Comment:             fmt.Sprintf("holds any element of the %s substitution group", head.Local),
+			IsSubstitutionGroup: true,
+			MemberInterface:     interfaceName,
+			Members:             make([]types.GoSubstitutionMember, 0),
+		}
+
+		for _, member := range p.collectSubstitutionMembers(head) {
+			found := p.findGlobalElement(member)
+			if found == nil || found.element.Abstract == "true" {
+				continue
+			}
+			typeName := p.globalElementType(found)
+			memberType := p.findExistingType(typeName)
+			if memberType == nil || memberType.IsSubstitutionGroup {
+				// Built-in types cannot carry the interface method
+				if p.debugMode {
+					fmt.Printf("Warning: substitution group member %s has no generated type, skipping\n", member.Local)
+				}
+				continue
+			}
+			if !containsString(memberType.Implements, interfaceName) {
+				memberType.Implements = append(memberType.Implements, interfaceName)
+			}
+			groupType.Members = append(groupType.Members, types.GoSubstitutionMember{
+				ElementName: member.Local,
+				Namespace:   member.Space,
+				TypeName:    typeName,
+			})
+		}
+
+		p.goTypes = append(p.goTypes, groupType)
+	}
+}
+
+// collectSubstitutionMembers returns the head and all direct and indirect
+// members of its substitution group
+func (p *XSDParser) collectSubstitutionMembers(head types.QName) []types.QName {
+	visited := map[types.QName]bool{head: true}
+	members := []types.QName{head}
+	for i := 0; i < len(members); i++ {
+		for _, member := range p.substitutionMembers[members[i]] {
+			if !visited[member] {
+				visited[member] = true
+				members = append(members, member)
+			}
+		}
+	}
+	return members
+}
+
+// globalElementType returns the Go type of a global element; elements
+// without a type of their own take the type of their substitution group head
+func (p *XSDParser) globalElementType(found *globalElement) string {
+	savedNS := p.currentNS
+	defer func() { p.currentNS = savedNS }()
+
+	visited := make(map[*types.XSDElement]bool)
+	for found != nil && !visited[found.element] {
+		visited[found.element] = true
+		p.currentNS = found.namespace
+
+		element := found.element
+		switch {
+		case element.Type != "":
+			return p.mapXSDTypeToGo(element.Type)
+		case element.ComplexType != nil:
+			return p.elementTypeName(element.Name)
+		case element.SubstitutionGroup != "":
+			found = p.findGlobalElement(types.ParseQName(element.SubstitutionGroup))
+		default:
+			found = nil
+		}
+	}
+	return "string"
+}
+
+// containsString reports whether a slice contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package xsdparser_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/generator"
+	"github.com/suifei/xsd2code/pkg/xsdparser"
+)
+
+const drawingSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="shape" type="ShapeType" abstract="true"/>
+  <xs:complexType name="ShapeType"/>
+  <xs:element name="circle" substitutionGroup="shape">
+    <xs:complexType><xs:attribute name="r" type="xs:int"/></xs:complexType>
+  </xs:element>
+  <xs:element name="square" substitutionGroup="shape">
+    <xs:complexType><xs:attribute name="side" type="xs:int"/></xs:complexType>
+  </xs:element>
+  <xs:complexType name="ColorType"><xs:simpleContent><xs:extension base="xs:string"/></xs:simpleContent></xs:complexType>
+  <xs:element name="paint" type="ColorType"/>
+  <xs:element name="red" type="ColorType" substitutionGroup="paint"/>
+  <xs:element name="blue" type="ColorType" substitutionGroup="paint"/>
+  <xs:element name="drawing">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="title" type="xs:string"/>
+        <xs:element ref="shape" maxOccurs="unbounded"/>
+        <xs:element ref="paint" minOccurs="0"/>
+        <xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="unbounded"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+const drawingProgram = `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	doc := ` + "`" + `<drawing xmlns:o="urn:other"><title>T</title><circle r="2"/><square side="3"/><blue>b</blue><o:note>hi</o:note></drawing>` + "`" + `
+	var d Drawing
+	if err := xml.Unmarshal([]byte(doc), &d); err != nil {
+		panic(err)
+	}
+	for _, shape := range d.Shape {
+		switch value := shape.Value.(type) {
+		case *Circle:
+			fmt.Println("circle", *value.R)
+		case *Square:
+			fmt.Println("square", *value.Side)
+		}
+	}
+	if d.Paint != nil {
+		fmt.Println(d.Paint.XMLName.Local, d.Paint.Value.(*ColorType).Value)
+	}
+	for _, element := range d.Any {
+		fmt.Println("any", element.XMLName.Space, element.XMLName.Local)
+	}
+}
+`
+
+// TestSubstitutionGroupsWithWildcard decodes a struct with two substitution
+// group references and a wildcard, which all need a ",any" field
+func TestSubstitutionGroupsWithWildcard(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "drawing.xsd")
+	outputPath := filepath.Join(dir, "drawing.go")
+	writeFile(t, schemaPath, drawingSchema)
+	writeFile(t, filepath.Join(dir, "go.mod"), "module drawing\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(dir, "main.go"), drawingProgram)
+
+	parser := xsdparser.NewUnifiedXSDParser(schemaPath, outputPath, "main")
+	if err := parser.Parse(); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	config := generator.NewGeneratorConfig().SetPackage("main").SetOutput(outputPath)
+	if err := generator.NewCodeGeneratorFactory(config).GenerateCode(parser.GetGoTypes()); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run: %v\n%s", err, output)
+	}
+	want := "circle 2\nsquare 3\nblue b\nany urn:other note\n"
+	if got := string(output); got != want {
+		t.Errorf("decoded content:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.TrimLeft(content, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSubstitutionGroupNamespaces hands an element to a substitution group
+// only when its namespace matches a member's, not just its local name
+func TestSubstitutionGroupNamespaces(t *testing.T) {
+	parser, dir := parseFiles(t, map[string]string{"main.xsd": `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:d="urn:d" targetNamespace="urn:d" elementFormDefault="qualified">
+  <xs:element name="shape" type="d:ShapeType" abstract="true"/>
+  <xs:complexType name="ShapeType"><xs:attribute name="r" type="xs:int"/></xs:complexType>
+  <xs:element name="circle" type="d:ShapeType" substitutionGroup="d:shape"/>
+  <xs:element name="drawing">
+    <xs:complexType><xs:sequence>
+      <xs:element ref="d:shape" maxOccurs="unbounded"/>
+      <xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+</xs:schema>
+`})
+	output := runGo(t, parser, dir, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	doc := `+"`"+`<drawing xmlns="urn:d" xmlns:o="urn:other"><circle r="1"/><o:circle r="9"/></drawing>`+"`"+`
+	var d Drawing
+	if err := xml.Unmarshal([]byte(doc), &d); err != nil {
+		panic(err)
+	}
+	for _, shape := range d.Shape {
+		fmt.Println(shape.XMLName.Space, shape.XMLName.Local, *shape.Value.(*ShapeType).R)
+	}
+	for _, element := range d.Any {
+		fmt.Println("any", element.XMLName.Space, element.XMLName.Local)
+	}
+}
+`)
+	want := "urn:d circle 1\nany urn:other circle\n"
+	if output != want {
+		t.Errorf("decoded content:\n%s\nwant:\n%s", output, want)
+	}
+}
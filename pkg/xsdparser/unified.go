@@ -45,6 +45,21 @@ func (u *UnifiedXSDParser) SetIncludeComments(comments bool) {
 	u.generator.SetIncludeComments(comments)
 }
 
+// SetAnonymousNaming sets how anonymous inline types are named
+func (u *UnifiedXSDParser) SetAnonymousNaming(strategy AnonymousNaming, names map[string]string) {
+	u.parser.SetAnonymousNaming(strategy, names)
+}
+
+// SetNameSuffixes sets the suffixes used to resolve name collisions
+func (u *UnifiedXSDParser) SetNameSuffixes(suffixes types.NameSuffixes) {
+	u.parser.SetNameSuffixes(suffixes)
+}
+
+// SetArbitraryPrecision maps xs:decimal and xs:integer to arbitrary-precision types
+func (u *UnifiedXSDParser) SetArbitraryPrecision(enable bool) {
+	u.parser.SetArbitraryPrecision(enable)
+}
+
 // Parse parses the XSD file
 func (u *UnifiedXSDParser) Parse() error {
 	return u.parser.Parse()
@@ -62,7 +77,27 @@ func (u *UnifiedXSDParser) GetGoTypes() []types.GoType {
 	return u.parser.GetGoTypes()
 }
 
+// GetNameChanges returns the names changed to resolve collisions
+func (u *UnifiedXSDParser) GetNameChanges() []types.NameChange {
+	return u.parser.GetNameChanges()
+}
+
 // GetSchema returns the parsed schema
 func (u *UnifiedXSDParser) GetSchema() *types.XSDSchema {
 	return u.parser.GetSchema()
 }
+
+// GetSchemaSet returns every schema document loaded for the root file
+func (u *UnifiedXSDParser) GetSchemaSet() *SchemaSet {
+	return u.parser.GetSchemaSet()
+}
+
+// GetElementTypes maps the global elements to the Go types of their content
+func (u *UnifiedXSDParser) GetElementTypes() map[types.QName]string {
+	return u.parser.GetElementTypes()
+}
+
+// NamespacePackages maps the namespaces of the converted types to Go packages
+func (u *UnifiedXSDParser) NamespacePackages(defaultPackage string, custom map[string]string) map[string]string {
+	return u.parser.NamespacePackages(defaultPackage, custom)
+}
@@ -0,0 +1,238 @@
+package xsdparser_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suifei/xsd2code/pkg/generator"
+	"github.com/suifei/xsd2code/pkg/xsdparser"
+)
+
+// generateSource generates code for the parsed types in a language and
+// returns it
+func generateSource(t *testing.T, parser *xsdparser.UnifiedXSDParser, dir string, language generator.TargetLanguage) string {
+	t.Helper()
+	outputPath := filepath.Join(dir, "out."+string(language))
+	config := generator.NewGeneratorConfig().SetLanguage(language).SetPackage("main").SetOutput(outputPath)
+	if err := generator.NewCodeGeneratorFactory(config).GenerateCode(parser.GetGoTypes()); err != nil {
+		t.Fatalf("generate %s: %v", language, err)
+	}
+	source, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(source)
+}
+
+// runGo generates Go code for the parsed types into package main next to
+// program and returns the output of running them
+func runGo(t *testing.T, parser *xsdparser.UnifiedXSDParser, dir, program string) string {
+	t.Helper()
+	writeGoProgram(t, dir, program)
+	generateSource(t, parser, dir, generator.LanguageGo)
+	return goRun(t, dir)
+}
+
+// writeGoProgram writes a module with program as its main.go. Generated
+// code may import packages of this module.
+func writeGoProgram(t *testing.T, dir, program string) {
+	t.Helper()
+	module, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "go.mod"), "module gen\n\ngo 1.22\n\nrequire github.com/suifei/xsd2code v0.0.0\n\nreplace github.com/suifei/xsd2code => "+module+"\n")
+	writeFile(t, filepath.Join(dir, "main.go"), program)
+}
+
+// goRun runs the main package in dir and returns its output
+func goRun(t *testing.T, dir string) string {
+	t.Helper()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run: %v\n%s", err, output)
+	}
+	return string(output)
+}
+
+// validateCall is a call of a Validate method in generated Go code
+type validateCall struct {
+	call string // conversion of a value to a generated type
+	want bool
+}
+
+// checkValidate generates Go code for a schema and checks the results of
+// calling Validate on values converted to the generated types
+func checkValidate(t *testing.T, schema string, tests []validateCall) {
+	t.Helper()
+	calls := make([]string, len(tests))
+	for i, test := range tests {
+		calls[i] = "\tfmt.Println(" + test.call + ".Validate())\n"
+	}
+	parser, dir := parseFiles(t, map[string]string{"main.xsd": schema})
+	output := runGo(t, parser, dir, "package main\n\nimport \"fmt\"\n\nfunc main() {\n"+strings.Join(calls, "")+"}\n")
+
+	results := strings.Fields(output)
+	if len(results) != len(tests) {
+		t.Fatalf("output:\n%s", output)
+	}
+	for i, test := range tests {
+		if got := results[i] == "true"; got != test.want {
+			t.Errorf("%s.Validate() = %v, want %v", test.call, got, test.want)
+		}
+	}
+}
+
+const unionSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:t" targetNamespace="urn:t">
+  <xs:simpleType name="IntList"><xs:list itemType="xs:int"/></xs:simpleType>
+  <xs:simpleType name="Color">
+    <xs:restriction base="xs:string"><xs:enumeration value="red"/><xs:enumeration value="blue"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="ColorList">
+    <xs:restriction>
+      <xs:simpleType><xs:list itemType="t:Color"/></xs:simpleType>
+      <xs:minLength value="1"/><xs:maxLength value="3"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Numbers"><xs:union memberTypes="t:IntList t:Color"/></xs:simpleType>
+  <xs:simpleType name="Colors"><xs:union memberTypes="t:ColorList xs:boolean"/></xs:simpleType>
+  <xs:element name="root">
+    <xs:complexType><xs:sequence>
+      <xs:element name="numbers" type="t:Numbers"/>
+      <xs:element name="colors" type="t:Colors"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+// TestUnionValidate checks values against unions in generated Go code
+func TestUnionValidate(t *testing.T) {
+	tests := []validateCall{
+		{`Numbers("1 2 3")`, true},
+		{`Numbers(" 7 ")`, true},
+		{`Numbers("red")`, true},
+		{`Numbers("1 x")`, false},
+		{`Numbers("green")`, false},
+		{`Colors("red blue")`, true},
+		{`Colors("red blue red red")`, false},
+		{`Colors("red green")`, false},
+		{`Colors("true")`, true},
+	}
+	checkValidate(t, unionSchema, tests)
+}
+
+// TestUnionListMembers checks that Java and C# unions test list members
+// item by item instead of accepting every value
+func TestUnionListMembers(t *testing.T) {
+	tests := []struct {
+		language generator.TargetLanguage
+		want     []string
+	}{
+		{generator.LanguageJava, []string{
+			"if (IntList.isValidText(value)) {",
+			"if (ColorList.isValidText(value)) {",
+			"if (!(items.length >= 1 && items.length <= 3)) {",
+			"if (!(items.size() >= 1 && items.size() <= 3)) {",
+			"Integer.parseInt(value);",
+			`if (Arrays.asList("red", "blue").contains(value)) {`,
+		}},
+		{generator.LanguageCSharp, []string{
+			"if (IntList.IsValidText(Value))",
+			"if (ColorList.IsValidText(Value))",
+			"if (!(items.Length >= 1 && items.Length <= 3))",
+			"if (!(Items.Count >= 1 && Items.Count <= 3))",
+			"int.TryParse(value, ",
+			`if (Array.IndexOf(new[] { "red", "blue" }, value) >= 0)`,
+		}},
+	}
+	for _, test := range tests {
+		parser, dir := parseFiles(t, map[string]string{"main.xsd": unionSchema})
+		source := generateSource(t, parser, dir, test.language)
+		for _, want := range test.want {
+			if !strings.Contains(source, want) {
+				t.Errorf("%s output does not contain %q", test.language, want)
+			}
+		}
+	}
+}
+
+const signSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:t="urn:t" targetNamespace="urn:t">
+  <xs:simpleType name="Color">
+    <xs:restriction base="xs:string"><xs:enumeration value="red"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Positive"><xs:union memberTypes="xs:positiveInteger t:Color"/></xs:simpleType>
+  <xs:simpleType name="NonNegative"><xs:union memberTypes="xs:nonNegativeInteger t:Color"/></xs:simpleType>
+  <xs:simpleType name="Negative"><xs:union memberTypes="xs:negativeInteger t:Color"/></xs:simpleType>
+  <xs:simpleType name="NonPositive"><xs:union memberTypes="xs:nonPositiveInteger t:Color"/></xs:simpleType>
+  <xs:element name="root">
+    <xs:complexType><xs:sequence>
+      <xs:element name="a" type="t:Positive"/>
+      <xs:element name="b" type="t:NonNegative"/>
+      <xs:element name="c" type="t:Negative"/>
+      <xs:element name="d" type="t:NonPositive"/>
+    </xs:sequence></xs:complexType>
+  </xs:element>
+</xs:schema>
+`
+
+// TestUnionIntegerSigns checks the zero bound of the built-in integer types
+// bounded by it, in generated Go code and in the other languages' source
+func TestUnionIntegerSigns(t *testing.T) {
+	tests := []validateCall{
+		{`Positive("5")`, true},
+		{`Positive("0")`, false},
+		{`Positive("-3")`, false},
+		{`Positive("red")`, true},
+		{`NonNegative("0")`, true},
+		{`NonNegative("-1")`, false},
+		{`Negative("-1")`, true},
+		{`Negative("0")`, false},
+		{`Negative("1")`, false},
+		{`NonPositive("0")`, true},
+		{`NonPositive("-7")`, true},
+		{`NonPositive("2")`, false},
+	}
+	checkValidate(t, signSchema, tests)
+	parser, dir := parseFiles(t, map[string]string{"main.xsd": signSchema})
+
+	sources := []struct {
+		language generator.TargetLanguage
+		want     []string
+	}{
+		{generator.LanguageJava, []string{
+			"if (Long.parseUnsignedLong(value) != 0) {",
+			"if (Long.parseLong(value) < 0) {",
+			"if (Long.parseLong(value) <= 0) {",
+		}},
+		{generator.LanguageCSharp, []string{
+			"ulong.Parse(Value, System.Globalization.NumberStyles.Integer, System.Globalization.CultureInfo.InvariantCulture) > 0",
+			"long.Parse(Value, System.Globalization.NumberStyles.Integer, System.Globalization.CultureInfo.InvariantCulture) < 0",
+			"long.Parse(Value, System.Globalization.NumberStyles.Integer, System.Globalization.CultureInfo.InvariantCulture) <= 0",
+		}},
+		{generator.LanguagePython, []string{
+			"if int(value) > 0:",
+			"if int(value) >= 0:",
+			"if int(value) < 0:",
+			"if int(value) <= 0:",
+		}},
+	}
+	for _, source := range sources {
+		generated := generateSource(t, parser, dir, source.language)
+		for _, want := range source.want {
+			if !strings.Contains(generated, want) {
+				t.Errorf("%s output does not contain %q", source.language, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package xsdparser
+
+import (
+	"fmt"
+
+	"github.com/suifei/xsd2code/pkg/types"
+)
+
+// Names of the catch-all fields generated for wildcards
+const (
+	anyElementFieldName   = "Any"
+	anyAttributeFieldName = "AnyAttrs"
+)
+
+// processAnyElements adds a catch-all field for the xs:any wildcards of a
+// sequence or choice. Every wildcard of a type shares the same field, whose
+// occurrence range grows with each wildcard merged into it. Substitution
+// group fields are catch-alls too; the generated Go decoder hands them their
+// members and the wildcard field the remaining elements.
+func (p *XSDParser) processAnyElements(wildcards []types.XSDAny, goType *types.GoType) {
+	for _, wildcard := range wildcards {
+		min, max := types.ParseOccurs(wildcard.MinOccurs, wildcard.MaxOccurs)
+		if existing := wildcardField(goType); existing != nil {
+			existing.MinOccurs += min
+			if existing.MaxOccurs == -1 || max == -1 {
+				existing.MaxOccurs = -1
+			} else {
+				existing.MaxOccurs += max
+			}
+			existing.IsOptional = existing.MinOccurs == 0
+			continue
+		}
+		comment := types.GetDocumentation(wildcard.Annotation)
+		if comment == "" {
+			comment = fmt.Sprintf("holds elements matched by the schema wildcard (%s)",
+				wildcardDescription(wildcard.Namespace, wildcard.ProcessContents))
+		}
+
+		field := types.GoField{
+			Name:       anyElementFieldName,
+			Type:       "[]AnyElement",
+			XMLTag:     ",any",
+			Comment:    comment,
+			IsElement:  true,
+			IsOptional: min == 0,
+			IsArray:    true,
+			MinOccurs:  min,
+			MaxOccurs:  max,
+			IsWildcard: true,
+		}
+		if p.jsonCompatible {
+			field.JSONTag = "any,omitempty"
+		}
+		goType.Fields = append(goType.Fields, field)
+	}
+}
+
+// processAnyAttribute adds a catch-all field for an xs:anyAttribute wildcard
+func (p *XSDParser) processAnyAttribute(wildcard *types.XSDAnyAttribute, goType *types.GoType) {
+	if wildcard == nil || catchAllField(goType, true) != nil {
+		return
+	}
+	comment := types.GetDocumentation(wildcard.Annotation)
+	if comment == "" {
+		comment = fmt.Sprintf("holds attributes matched by the schema wildcard (%s)",
+			wildcardDescription(wildcard.Namespace, wildcard.ProcessContents))
+	}
+
+	field := types.GoField{
+		Name:        anyAttributeFieldName,
+		Type:        "AnyAttributes",
+		XMLTag:      ",any,attr",
+		Comment:     comment,
+		IsAttribute: true,
+		IsOptional:  true,
+		IsWildcard:  true,
+	}
+	if p.jsonCompatible {
+		field.JSONTag = "any_attrs,omitempty"
+	}
+	goType.Fields = append(goType.Fields, field)
+}
+
+// catchAllField returns the field of a type that takes unmatched elements
+// or attributes: any field tagged ",any" or ",any,attr", which includes the
+// fields of substitution group references
+func catchAllField(goType *types.GoType, attribute bool) *types.GoField {
+	tag := ",any"
+	if attribute {
+		tag = ",any,attr"
+	}
+	for i := range goType.Fields {
+		if goType.Fields[i].XMLTag == tag {
+			return &goType.Fields[i]
+		}
+	}
+	return nil
+}
+
+// wildcardField returns the element wildcard field of a type, if any
+func wildcardField(goType *types.GoType) *types.GoField {
+	for i := range goType.Fields {
+		if goType.Fields[i].IsWildcard && !goType.Fields[i].IsAttribute {
+			return &goType.Fields[i]
+		}
+	}
+	return nil
+}
+
+// wildcardDescription summarizes the namespace constraint and processing mode
+// of a wildcard, applying the schema defaults for missing values
+func wildcardDescription(namespace, processContents string) string {
+	if namespace == "" {
+		namespace = "##any"
+	}
+	if processContents == "" {
+		processContents = "strict"
+	}
+	return fmt.Sprintf("namespace %s, processContents %s", namespace, processContents)
+}
@@ -0,0 +1,321 @@
+package xsdregex
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// branches is an alternation of sequences
+type branches [][]piece
+
+// piece is an atom with its quantifier
+type piece struct {
+	literal    rune
+	class      *class
+	group      branches
+	quantifier string // as written, e.g. "*" or "{2,4}"
+}
+
+// parser reads an XSD regular expression
+type parser struct {
+	pattern string
+	pos     int
+}
+
+// parse parses a whole pattern
+func parse(pattern string) (branches, error) {
+	p := &parser{pattern: pattern}
+	result, err := p.parseBranches()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.pattern) {
+		return nil, p.errorf("unmatched )")
+	}
+	return result, nil
+}
+
+// errorf reports an error at the current position
+func (p *parser) errorf(message string) *Error {
+	return &Error{Pattern: p.pattern, Offset: p.pos, Message: message}
+}
+
+// peek returns the next rune without consuming it, or -1 at the end
+func (p *parser) peek() rune {
+	if p.pos >= len(p.pattern) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(p.pattern[p.pos:])
+	return r
+}
+
+// next consumes and returns the next rune
+func (p *parser) next() rune {
+	r, size := utf8.DecodeRuneInString(p.pattern[p.pos:])
+	p.pos += size
+	return r
+}
+
+// lookingAt reports whether the rest of the pattern starts with prefix
+func (p *parser) lookingAt(prefix string) bool {
+	return strings.HasPrefix(p.pattern[p.pos:], prefix)
+}
+
+// parseBranches parses regExp ::= branch ('|' branch)*
+func (p *parser) parseBranches() (branches, error) {
+	result := branches{}
+	for {
+		branch, err := p.parseBranch()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, branch)
+		if p.peek() != '|' {
+			return result, nil
+		}
+		p.next()
+	}
+}
+
+// parseBranch parses branch ::= piece*
+func (p *parser) parseBranch() ([]piece, error) {
+	branch := make([]piece, 0)
+	for {
+		switch p.peek() {
+		case -1, '|', ')':
+			return branch, nil
+		}
+		atom, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		if atom.quantifier, err = p.parseQuantifier(); err != nil {
+			return nil, err
+		}
+		branch = append(branch, atom)
+	}
+}
+
+// parseAtom parses atom ::= Char | charClass | '(' regExp ')'
+func (p *parser) parseAtom() (piece, error) {
+	start := p.pos
+	switch r := p.next(); r {
+	case '(':
+		if p.peek() == '?' {
+			return piece{}, p.errorf("(? groups are not part of XSD patterns")
+		}
+		group, err := p.parseBranches()
+		if err != nil {
+			return piece{}, err
+		}
+		if p.peek() != ')' {
+			return piece{}, p.errorf("missing )")
+		}
+		p.next()
+		return piece{group: group}, nil
+	case '[':
+		c, err := p.parseClassExpr()
+		return piece{class: c}, err
+	case '.':
+		return piece{class: newClass(true, classItem{lo: '\n', hi: '\n'}, classItem{lo: '\r', hi: '\r'})}, nil
+	case '\\':
+		literal, c, err := p.parseEscape()
+		return piece{literal: literal, class: c}, err
+	case '?', '*', '+':
+		p.pos = start
+		return piece{}, p.errorf("quantifier " + string(r) + " without an atom")
+	case ']':
+		p.pos = start
+		return piece{}, p.errorf("unmatched ]")
+	default:
+		return piece{literal: r}, nil
+	}
+}
+
+// parseQuantifier parses an optional quantifier
+func (p *parser) parseQuantifier() (string, error) {
+	switch p.peek() {
+	case '?', '*', '+':
+		return string(p.next()), nil
+	case '{':
+	default:
+		return "", nil
+	}
+
+	start := p.pos
+	end := strings.IndexByte(p.pattern[start:], '}')
+	if end < 0 {
+		return "", p.errorf("missing }")
+	}
+	quantity := p.pattern[start+1 : start+end]
+	minimum, maximum, hasComma := strings.Cut(quantity, ",")
+	lo, err := strconv.Atoi(minimum)
+	if err != nil || lo < 0 || strings.HasPrefix(minimum, "+") {
+		return "", p.errorf("invalid quantifier {" + quantity + "}")
+	}
+	if hasComma && maximum != "" {
+		hi, err := strconv.Atoi(maximum)
+		if err != nil || hi < lo || strings.HasPrefix(maximum, "+") {
+			return "", p.errorf("invalid quantifier {" + quantity + "}")
+		}
+	}
+	p.pos = start + end + 1
+	return "{" + quantity + "}", nil
+}
+
+// parseClassExpr parses the rest of charClassExpr ::= '[' charGroup ']',
+// where charGroup ::= '^'? (charRange | charClassEsc)+ ('-' charClassExpr)?
+func (p *parser) parseClassExpr() (*class, error) {
+	negated := false
+	if p.peek() == '^' {
+		p.next()
+		negated = true
+	}
+
+	group := newClass(false)
+	empty := true
+	for {
+		switch {
+		case p.peek() == -1:
+			return nil, p.errorf("missing ]")
+		case p.peek() == ']' && !empty:
+			p.next()
+			return p.finishGroup(group, negated, nil), nil
+		case p.lookingAt("-[") && !empty:
+			p.pos += 2
+			subtracted, err := p.parseClassExpr()
+			if err != nil {
+				return nil, err
+			}
+			if p.peek() != ']' {
+				return nil, p.errorf("subtraction must end the character class")
+			}
+			p.next()
+			return p.finishGroup(group, negated, subtracted), nil
+		case p.peek() == '[':
+			return nil, p.errorf("[ must be escaped inside a character class")
+		}
+
+		lo, escaped, err := p.parseClassChar()
+		if err != nil {
+			return nil, err
+		}
+		empty = false
+		if escaped != nil {
+			group.add(escaped)
+			continue
+		}
+		// A - before ] or -[ is a literal
+		if p.peek() != '-' || p.lookingAt("-]") || p.lookingAt("-[") {
+			group.add(newClass(false, classItem{lo: lo, hi: lo}))
+			continue
+		}
+		p.next()
+		hi, escaped, err := p.parseClassChar()
+		if err != nil {
+			return nil, err
+		}
+		if escaped != nil {
+			return nil, p.errorf("character class escape used as a range end")
+		}
+		if hi < lo {
+			return nil, p.errorf("invalid character range " + string(lo) + "-" + string(hi))
+		}
+		group.add(newClass(false, classItem{lo: lo, hi: hi}))
+	}
+}
+
+// finishGroup applies negation and subtraction to a character group
+func (p *parser) finishGroup(group *class, negated bool, subtracted *class) *class {
+	if negated {
+		group.negated = true
+		group.set = group.set.complement()
+	}
+	if subtracted != nil {
+		group.set = group.set.subtract(subtracted.set)
+		group.compact = false
+	}
+	return group
+}
+
+// parseClassChar parses a character or an escape inside a character class
+func (p *parser) parseClassChar() (rune, *class, error) {
+	r := p.next()
+	if r != '\\' {
+		return r, nil, nil
+	}
+	return p.parseEscape()
+}
+
+// parseEscape parses the rest of an escape, which is either a single
+// character or a class
+func (p *parser) parseEscape() (rune, *class, error) {
+	start := p.pos - 1
+	if p.peek() == -1 {
+		return 0, nil, p.errorf("trailing \\")
+	}
+	switch r := p.next(); r {
+	case 'n':
+		return '\n', nil, nil
+	case 'r':
+		return '\r', nil, nil
+	case 't':
+		return '\t', nil, nil
+	case 's', 'S':
+		return 0, rangesClass(runeSet{{'\t', '\n'}, {'\r', '\r'}, {' ', ' '}}, r == 'S'), nil
+	case 'i', 'I':
+		return 0, rangesClass(nameStartChars, r == 'I'), nil
+	case 'c', 'C':
+		return 0, rangesClass(nameChars, r == 'C'), nil
+	case 'd':
+		return 0, newClass(false, classItem{category: "Nd"}), nil
+	case 'D':
+		return 0, newClass(false, classItem{category: "Nd", negated: true}), nil
+	case 'w', 'W':
+		// \w is every character except punctuation, separators and others
+		return 0, newClass(r == 'w', classItem{category: "P"}, classItem{category: "Z"}, classItem{category: "C"}), nil
+	case 'p', 'P':
+		c, err := p.parseProperty(start, r == 'P')
+		return 0, c, err
+	default:
+		if r < utf8.RuneSelf && !isASCIIAlphanumeric(r) {
+			return r, nil, nil
+		}
+		p.pos = start
+		return 0, nil, p.errorf("unsupported escape \\" + string(r))
+	}
+}
+
+// parseProperty parses the rest of \p{...} or \P{...}
+func (p *parser) parseProperty(start int, negated bool) (*class, error) {
+	end := strings.IndexByte(p.pattern[p.pos:], '}')
+	if p.peek() != '{' || end < 0 {
+		p.pos = start
+		return nil, p.errorf("malformed character property")
+	}
+	name := p.pattern[p.pos+1 : p.pos+end]
+	p.pos += end + 1
+
+	if block, found := strings.CutPrefix(name, "Is"); found {
+		canonical, exists := blockNames[normalizeBlockName(block)]
+		if !exists {
+			p.pos = start
+			return nil, p.errorf("unknown Unicode block " + name)
+		}
+		return rangesClass(blocks[canonical], negated), nil
+	}
+	if !categories[name] {
+		p.pos = start
+		return nil, p.errorf("unknown character property " + name)
+	}
+	c := newClass(false, classItem{category: name, negated: negated})
+	// Go and Python know no Cn, so it is always written out
+	c.compact = name != "Cn"
+	return c, nil
+}
+
+// isASCIIAlphanumeric reports whether r is an ASCII letter or digit
+func isASCIIAlphanumeric(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z'
+}
@@ -0,0 +1,296 @@
+// Package xsdregex translates XML Schema regular expressions, as used by the
+// pattern facet, into the regular expression syntax of Go and of the other
+// languages code is generated for.
+//
+// XSD patterns are implicitly anchored, know no ^ and $ anchors, and add the
+// \i, \c and \p{IsBlock} escapes and character class subtraction. The
+// translation parses the pattern and writes an anchored equivalent, or
+// reports the constructs it cannot translate.
+package xsdregex
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Dialect is a regular expression syntax patterns are translated to
+type Dialect int
+
+const (
+	RE2    Dialect = iota // Go regexp
+	Java                  // java.util.regex
+	DotNet                // System.Text.RegularExpressions
+	Python                // re
+)
+
+// Error reports a pattern that cannot be translated
+type Error struct {
+	Pattern string
+	Offset  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid XSD pattern %q at offset %d: %s", e.Pattern, e.Offset, e.Message)
+}
+
+// Translate converts an XSD pattern into an anchored Go regular expression
+func Translate(pattern string) (string, error) {
+	return TranslateTo(pattern, RE2)
+}
+
+// TranslateTo converts an XSD pattern into an anchored regular expression
+// of the given dialect. The result contains no backquotes, single quotes or
+// double quotes, so it can be embedded in raw and verbatim string literals.
+func TranslateTo(pattern string, dialect Dialect) (string, error) {
+	parsed, err := parse(pattern)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	builder.WriteString("^(?:")
+	dialect.writeBranches(&builder, parsed)
+	builder.WriteString(")")
+	switch dialect {
+	case RE2:
+		builder.WriteString("$")
+	case Python:
+		builder.WriteString(`\Z`)
+	default:
+		builder.WriteString(`\z`)
+	}
+
+	result := builder.String()
+	if dialect == RE2 {
+		// Go rejects what the XSD grammar allows, like counts above 1000
+		if _, err := regexp.Compile(result); err != nil {
+			return "", &Error{Pattern: pattern, Message: err.Error()}
+		}
+	}
+	return result, nil
+}
+
+// compiled caches Compile results by pattern
+var compiled sync.Map
+
+// Compile translates and compiles an XSD pattern
+func Compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := compiled.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	translated, err := Translate(pattern)
+	if err != nil {
+		return nil, err
+	}
+	re := regexp.MustCompile(translated)
+	compiled.Store(pattern, re)
+	return re, nil
+}
+
+// MatchString reports whether the whole value matches an XSD pattern
+func MatchString(pattern, value string) (bool, error) {
+	re, err := Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(value), nil
+}
+
+// writeBranches writes an alternation
+func (d Dialect) writeBranches(builder *strings.Builder, parsed branches) {
+	for i, branch := range parsed {
+		if i > 0 {
+			builder.WriteString("|")
+		}
+		for _, atom := range branch {
+			switch {
+			case atom.group != nil:
+				builder.WriteString("(?:")
+				d.writeBranches(builder, atom.group)
+				builder.WriteString(")")
+			case atom.class != nil:
+				d.writeClass(builder, atom.class)
+			default:
+				d.writeLiteral(builder, atom.literal)
+			}
+			builder.WriteString(atom.quantifier)
+		}
+	}
+}
+
+// writeLiteral writes a single character outside a class
+func (d Dialect) writeLiteral(builder *strings.Builder, r rune) {
+	if d == DotNet && r > 0xFFFF {
+		// .NET matches UTF-16 code units
+		high, low := utf16.EncodeRune(r)
+		builder.WriteString(d.codePoint(high) + d.codePoint(low))
+		return
+	}
+	switch {
+	case strings.ContainsRune(`\.+*?()|[]{}^$`, r):
+		builder.WriteString(`\` + string(r))
+	case d.needsCodePoint(r):
+		builder.WriteString(d.codePoint(r))
+	default:
+		builder.WriteRune(r)
+	}
+}
+
+// writeClass writes a character class, preferring the form it was written
+// in and otherwise its set or the complement of its set, whichever is shorter
+func (d Dialect) writeClass(builder *strings.Builder, c *class) {
+	if c.compact && d.canWriteItems(c.items) {
+		if len(c.items) == 1 && c.items[0].category != "" && !c.negated {
+			builder.WriteString(d.category(c.items[0]))
+			return
+		}
+		var items strings.Builder
+		for _, item := range c.items {
+			if item.category != "" {
+				items.WriteString(d.category(item))
+			} else {
+				d.writeRange(&items, item.lo, item.hi)
+			}
+		}
+		switch {
+		case items.Len() > 0 && c.negated:
+			builder.WriteString("[^" + items.String() + "]")
+		case items.Len() > 0:
+			builder.WriteString("[" + items.String() + "]")
+		case c.negated:
+			// Only .NET drops items, namely supplementary characters
+			builder.WriteString(d.anyClass())
+		default:
+			builder.WriteString(d.emptyClass())
+		}
+		return
+	}
+
+	set := d.clip(c.set)
+	complement := d.clip(c.set.complement())
+	negated := len(complement) < len(set)
+	if negated {
+		set = complement
+	}
+	var ranges strings.Builder
+	for _, r := range set {
+		d.writeRange(&ranges, r.lo, r.hi)
+	}
+	switch {
+	case len(set) > 0 && negated:
+		builder.WriteString("[^" + ranges.String() + "]")
+	case len(set) > 0:
+		builder.WriteString("[" + ranges.String() + "]")
+	case negated:
+		builder.WriteString(d.anyClass())
+	default:
+		builder.WriteString(d.emptyClass())
+	}
+}
+
+// canWriteItems reports whether the dialect supports the items of a class
+func (d Dialect) canWriteItems(items []classItem) bool {
+	if d != Python {
+		return true
+	}
+	for _, item := range items {
+		if item.category != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// category writes a general category escape
+func (d Dialect) category(item classItem) string {
+	if item.negated {
+		return `\P{` + item.category + `}`
+	}
+	return `\p{` + item.category + `}`
+}
+
+// writeRange writes a range inside a class, leaving out the part the
+// dialect cannot match
+func (d Dialect) writeRange(builder *strings.Builder, lo, hi rune) {
+	if lo > d.maxRune() {
+		return
+	}
+	if hi > d.maxRune() {
+		hi = d.maxRune()
+	}
+	builder.WriteString(d.classRune(lo))
+	if hi > lo {
+		if hi > lo+1 {
+			builder.WriteString("-")
+		}
+		builder.WriteString(d.classRune(hi))
+	}
+}
+
+// clip drops the code points the dialect cannot match in a class
+func (d Dialect) clip(set runeSet) runeSet {
+	result := make(runeSet, 0, len(set))
+	for _, r := range set {
+		if r.lo > d.maxRune() {
+			break
+		}
+		if r.hi > d.maxRune() {
+			r.hi = d.maxRune()
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// classRune writes a single character inside a class
+func (d Dialect) classRune(r rune) string {
+	switch {
+	case strings.ContainsRune(`\[]^-`, r), d == Java && r == '&':
+		return `\` + string(r)
+	case d.needsCodePoint(r):
+		return d.codePoint(r)
+	default:
+		return string(r)
+	}
+}
+
+// needsCodePoint reports whether a character is written as a code point
+// escape, which is the case for invisible characters and quotes
+func (d Dialect) needsCodePoint(r rune) bool {
+	return !unicode.IsPrint(r) || r == '`' || r == '\'' || r == '"'
+}
+
+// codePoint writes a code point escape
+func (d Dialect) codePoint(r rune) string {
+	switch {
+	case d == DotNet || d == Python && r <= 0xFFFF:
+		return fmt.Sprintf(`\u%04X`, r)
+	case d == Python:
+		return fmt.Sprintf(`\U%08X`, r)
+	default:
+		return fmt.Sprintf(`\x{%X}`, r)
+	}
+}
+
+// maxRune returns the largest code point a class can contain; .NET classes
+// only hold UTF-16 code units
+func (d Dialect) maxRune() rune {
+	if d == DotNet {
+		return 0xFFFF
+	}
+	return unicode.MaxRune
+}
+
+// anyClass returns a class that matches every character
+func (d Dialect) anyClass() string {
+	return "[" + d.classRune(0) + "-" + d.classRune(d.maxRune()) + "]"
+}
+
+// emptyClass returns a class that matches nothing
+func (d Dialect) emptyClass() string {
+	return "[^" + d.classRune(0) + "-" + d.classRune(d.maxRune()) + "]"
+}
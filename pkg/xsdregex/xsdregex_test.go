@@ -0,0 +1,132 @@
+package xsdregex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		pattern, want string
+	}{
+		{`abc`, `^(?:abc)$`},
+		{`a|b`, `^(?:a|b)$`},
+		{`[0-9]{3}-[0-9]{4}`, `^(?:[0-9]{3}-[0-9]{4})$`},
+		{`a{2,}`, `^(?:a{2,})$`},
+		{`(ab)*`, `^(?:(?:ab)*)$`},
+		{`\d+(\.\d{1,2})?`, `^(?:\p{Nd}+(?:\.\p{Nd}{1,2})?)$`},
+		{`\p{Lu}`, `^(?:\p{Lu})$`},
+		{`[^abc]`, `^(?:[^abc])$`},
+		{`[\-a]`, `^(?:[\-a])$`},
+
+		// Class subtraction and blocks become plain ranges
+		{`[a-z-[aeiou]]+`, `^(?:[b-df-hj-np-tv-z]+)$`},
+		{`\P{IsBasicLatin}`, `^(?:[^\x{0}-\x{7F}])$`},
+		{`\p{IsGreek}`, `^(?:[Ͱ-Ͽ])$`},
+
+		// XSD has no anchors, and . matches anything but line ends
+		{`^$`, `^(?:\^\$)$`},
+		{`\d$`, `^(?:\p{Nd}\$)$`},
+		{`.`, `^(?:[^\x{A}\x{D}])$`},
+		{`\s\S`, `^(?:[\x{9}\x{A}\x{D} ][^\x{9}\x{A}\x{D} ])$`},
+		{`{`, `^(?:\{)$`},
+	}
+	for _, test := range tests {
+		got, err := Translate(test.pattern)
+		if err != nil {
+			t.Errorf("Translate(%q) error = %v", test.pattern, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Translate(%q) = %q, want %q", test.pattern, got, test.want)
+		}
+	}
+}
+
+func TestTranslateTo(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		pattern string
+		want    string
+	}{
+		{RE2, `a.`, `^(?:a[^\x{A}\x{D}])$`},
+		{Java, `a.`, `^(?:a[^\x{A}\x{D}])\z`},
+		{DotNet, `a.`, `^(?:a[^\u000A\u000D])\z`},
+		{Python, `a.`, `^(?:a[^\u000A\u000D])\Z`},
+		{Java, `\p{Lu}`, `^(?:\p{Lu})\z`},
+		{DotNet, `\P{IsBasicLatin}`, `^(?:[^\u0000-\u007F])\z`},
+	}
+	for _, test := range tests {
+		got, err := TranslateTo(test.pattern, test.dialect)
+		if err != nil {
+			t.Errorf("TranslateTo(%q, %d) error = %v", test.pattern, test.dialect, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("TranslateTo(%q, %d) = %q, want %q", test.pattern, test.dialect, got, test.want)
+		}
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		offset  int
+		message string
+	}{
+		{`(?:a)`, 1, "(? groups are not part of XSD patterns"},
+		{`*a`, 0, "quantifier * without an atom"},
+		{`a**`, 2, "quantifier * without an atom"},
+		{`[a`, 2, "missing ]"},
+		{`(a`, 2, "missing )"},
+		{`a)`, 1, "unmatched )"},
+		{`]`, 0, "unmatched ]"},
+		{`a{1`, 1, "missing }"},
+		{`a{2,1}`, 1, "invalid quantifier {2,1}"},
+		{`[z-a]`, 4, "invalid character range z-a"},
+		{`\q`, 0, "unsupported escape \\q"},
+		{`\p{Foo}`, 0, "unknown character property Foo"},
+
+		// Valid XSD that Go cannot compile
+		{`x{1001}`, 0, "error parsing regexp: invalid repeat count: `{1001}`"},
+	}
+	for _, test := range tests {
+		_, err := Translate(test.pattern)
+		var patternErr *Error
+		if !errors.As(err, &patternErr) {
+			t.Errorf("Translate(%q) error = %v, want an *Error", test.pattern, err)
+			continue
+		}
+		if patternErr.Pattern != test.pattern || patternErr.Offset != test.offset || patternErr.Message != test.message {
+			t.Errorf("Translate(%q) error = %+v, want offset %d and message %q", test.pattern, *patternErr, test.offset, test.message)
+		}
+	}
+}
+
+func TestMatchString(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{`[0-9]{3}`, "123", true},
+		{`[0-9]{3}`, "1234", false},
+		{`[0-9]{3}`, "x123", false},
+		{`a|b`, "ab", false},
+		{`[a-z-[aeiou]]+`, "xyz", true},
+		{`[a-z-[aeiou]]+`, "xaz", false},
+		{`\i\c*`, "_name-1", true},
+		{`\i\c*`, "1name", false},
+		{`.`, "\n", false},
+		{`\d`, "٣", true},
+	}
+	for _, test := range tests {
+		got, err := MatchString(test.pattern, test.value)
+		if err != nil {
+			t.Errorf("MatchString(%q, %q) error = %v", test.pattern, test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("MatchString(%q, %q) = %v, want %v", test.pattern, test.value, got, test.want)
+		}
+	}
+}